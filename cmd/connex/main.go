@@ -0,0 +1,77 @@
+// Command connex is an operational CLI for one-shot tasks that don't belong
+// in the long-running server binary (cmd/server), e.g. triggering an LDAP
+// directory sync by hand or from a cron job.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"connex/internal/api/user"
+	"connex/internal/config"
+	"connex/internal/db"
+	"connex/pkg/logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "ldap":
+		runLDAP(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: connex ldap sync")
+}
+
+func runLDAP(args []string) {
+	if len(args) != 1 || args[0] != "sync" {
+		usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if !cfg.LDAP.Enabled {
+		fmt.Fprintln(os.Stderr, "LDAP is not enabled (LDAP_ENABLED=true to enable)")
+		os.Exit(1)
+	}
+
+	if err := logger.InitGlobal(cfg.Log.Level, cfg.Log.Env); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	log := logger.GetGlobal()
+	defer log.Sync()
+
+	dbInstance, err := db.Init(cfg.Database)
+	if err != nil {
+		log.Error("failed to connect to database: " + err.Error())
+		os.Exit(1)
+	}
+	defer dbInstance.Close()
+
+	userService := user.NewService(db.DefaultManager())
+	provider := user.NewLDAPProvider(cfg.LDAP)
+
+	result, err := user.SyncExternal(context.Background(), userService, provider)
+	if err != nil {
+		log.Error("ldap sync failed: " + err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("ldap sync complete: created=%d updated=%d removed=%d\n",
+		result.Created, result.Updated, result.Removed)
+}
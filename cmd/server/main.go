@@ -1,21 +1,38 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"connex/internal/api/admin"
 	"connex/internal/api/auth"
+	"connex/internal/api/auth/backend"
 	"connex/internal/api/health"
+	"connex/internal/api/uploads"
 	"connex/internal/api/user"
 	"connex/internal/cache"
-	"connex/internal/config"
 	"connex/internal/db"
 	"connex/internal/job"
 	custommiddleware "connex/internal/middleware"
+	"connex/internal/serverconfig"
+	"connex/internal/storage"
+	"connex/pkg/jwt"
 	"connex/pkg/logger"
+	"connex/pkg/password"
+	"connex/pkg/security/authn"
+	"connex/pkg/security/breach"
+	"connex/pkg/security/crowdsec"
+	"connex/pkg/security/detector"
 	"connex/pkg/telemetry"
+	pkgtls "connex/pkg/tls"
 
 	"encoding/base64"
 
@@ -24,11 +41,15 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/hibiken/asynq"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/oauth2"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
+	// Load configuration. CONFIG_SOURCES (e.g. "k8s,awsssm") layers
+	// cloud/platform-specific env vars in before config.Load reads them;
+	// unset, this is exactly config.Load().
+	cfg, err := serverconfig.Load(context.Background(), serverconfig.SourcesFromEnv())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 		os.Exit(1)
@@ -42,7 +63,12 @@ func main() {
 	log := logger.GetGlobal()
 	defer log.Sync()
 
-	// Initialize DB
+	// Must run before any password.Hash/Verify call (Register/Login below).
+	password.SetPepper(cfg.Password.Pepper)
+
+	// Initialize DB. db.Init registers the default-tenant pool on the
+	// package's default Manager; dbManager is that same Manager, used below
+	// for the tenant-aware services instead of the deprecated db.Get().
 	dbInstance, err := db.Init(cfg.Database)
 	if err != nil {
 		log.Error("Failed to connect to database")
@@ -50,6 +76,7 @@ func main() {
 		os.Exit(1)
 	}
 	defer dbInstance.Close()
+	dbManager := db.DefaultManager()
 
 	// Initialize OpenTelemetry
 	if err := telemetry.Init(cfg.OTel, log.Logger); err != nil {
@@ -79,16 +106,299 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Periodic scheduler for cfg.Jobs.Schedules, additionally synced below
+	// against the Postgres-backed ScheduleStore admin.Handler serves CRUD
+	// for at /admin/jobs/schedules.
+	scheduler, err := job.InitScheduler(cfg.Jobs, redisOpt, log.Logger)
+	if err != nil {
+		log.Error("Failed to initialize job scheduler")
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+	defer scheduler.Shutdown()
+	scheduleStore := job.NewPostgresScheduleStore(dbManager)
+	if defs, err := scheduleStore.List(context.Background()); err != nil {
+		log.Error("Failed to load persisted job schedules")
+		log.Error(err.Error())
+	} else if err := scheduler.Sync(defs); err != nil {
+		log.Error("Failed to sync job schedules")
+		log.Error(err.Error())
+	}
+
+	// Object storage client backing presigned uploads (internal/api/uploads)
+	// and the admin dashboard's bucket/upload metrics.
+	storageClient, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Error("Failed to connect to object storage")
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+	uploadStore := storage.NewPostgresUploadStore(dbManager)
+
 	// User service and handler
-	userService := user.NewService()
+	userService := user.NewService(dbManager)
 	userHandler := user.NewHandler(userService)
 
 	// Auth handler
 	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
+	authHandler.WithExpirations(time.Duration(cfg.JWT.Expiration), time.Duration(cfg.JWT.RefreshExpiration))
+	if cfg.JWT.SecretRef != nil {
+		authHandler.WithJWTSecret(cfg.JWT.SecretRef.Load)
+	}
+
+	// OAuth2 authorization server, signing its tokens with an Ed25519 key
+	// derived from JWT_SIGNING_KEY_SEED rather than the legacy handler's
+	// HS256 JWT_SECRET, so both flows can coexist during rollout.
+	signingKeySeed, err := base64.StdEncoding.DecodeString(cfg.JWT.SigningKeySeed)
+	if err != nil || len(signingKeySeed) != ed25519.SeedSize {
+		log.Error("JWT_SIGNING_KEY_SEED must be a base64-encoded 32-byte Ed25519 seed")
+		os.Exit(1)
+	}
+	signingKey, err := jwt.KeyPairFromSeed(cfg.JWT.SigningKeyID, signingKeySeed)
+	if err != nil {
+		log.Error("Failed to derive OAuth2 signing key")
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+	oauthKeyring := jwt.NewKeyring()
+	oauthKeyring.AddKey(signingKey)
+	oauthHandler := auth.NewOAuthHandler(userService, auth.NewClientStore(dbManager), oauthKeyring, cfg.JWT.Issuer, cfg.JWT.Audience)
 
 	// Health handler
 	healthHandler := health.NewHandler()
 
+	// Admin dashboard, backed by the same Postgres pools as everything
+	// else; WithSchedules and WithStorage below let it additionally serve
+	// /admin/jobs/schedules and bucket/upload metrics.
+	adminHandler := admin.NewHandler(log.Logger, admin.NewPostgresProvider(dbManager), admin.NewZapTapLogSource(logger.GetRingBuffer()))
+	adminHandler.WithSchedules(scheduleStore, scheduler)
+	adminHandler.WithStorage(storageClient, uploadStore)
+
+	// Presigned-upload endpoints, sharing the same storage client and
+	// upload records the admin dashboard reads from.
+	uploadsHandler := uploads.NewHandler(log.Logger, storageClient, uploadStore)
+
+	// CrowdSec LAPI bouncer. Constructed unconditionally so
+	// SecurityMonitoringMiddleware always has somewhere to report events;
+	// it only starts polling/pushing (and only blocks requests) when enabled.
+	crowdsecBouncer := crowdsec.NewBouncer(cfg.CrowdSec)
+	if cfg.CrowdSec.Enabled {
+		bouncerCtx, cancelBouncer := context.WithCancel(context.Background())
+		defer cancelBouncer()
+		crowdsecBouncer.Start(bouncerCtx)
+		defer crowdsecBouncer.Stop()
+	}
+
+	// Now that the bouncer exists, feed it login lockouts as signals.
+	authHandler.WithLogins(auth.NewRedisLoginLimiter(redisClient, crowdsecBouncer))
+
+	// Session tracking shared across replicas, so a logout or idle timeout
+	// on one instance is honored by all of them.
+	authHandler.WithSessions(auth.NewRedisSessionStore(
+		redisClient, time.Duration(cfg.Session.TokenIdleTimeout), time.Duration(cfg.Session.AbsoluteTokenLifetime), cfg.Session.EnableMultiLogin,
+	))
+
+	// HIBP breach check on registration, opt-in via BREACH_CHECK_ENABLED.
+	if cfg.Breach.Enabled {
+		authHandler.WithBreach(breach.NewHIBPChecker(
+			redisClient, cfg.Breach.Threshold, time.Duration(cfg.Breach.CacheTTL), time.Duration(cfg.Breach.Timeout),
+		))
+	}
+
+	// Federated login providers, alongside local username/password. Each is
+	// opt-in via its own OIDC_*_ENABLED var; RegisterSocialRoutes below wires
+	// /api/auth/{provider}/login and /callback for whatever ends up
+	// registered here.
+	if cfg.OIDC.Keycloak.Enabled {
+		keycloakBackend, err := backend.NewKeycloakBackend(context.Background(), cfg.OIDC.Keycloak.IssuerURL, &oauth2.Config{
+			ClientID:     cfg.OIDC.Keycloak.ClientID,
+			ClientSecret: cfg.OIDC.Keycloak.ClientSecret,
+			RedirectURL:  cfg.OIDC.Keycloak.RedirectURL,
+			Scopes:       cfg.OIDC.Keycloak.Scopes,
+		})
+		if err != nil {
+			log.Error("Failed to initialize Keycloak OIDC backend")
+			log.Error(err.Error())
+			os.Exit(1)
+		}
+		authHandler.Backends.Register(keycloakBackend)
+	}
+	if cfg.OIDC.Google.Enabled {
+		googleBackend, err := backend.NewGoogleBackend(context.Background(), &oauth2.Config{
+			ClientID:     cfg.OIDC.Google.ClientID,
+			ClientSecret: cfg.OIDC.Google.ClientSecret,
+			RedirectURL:  cfg.OIDC.Google.RedirectURL,
+			Scopes:       cfg.OIDC.Google.Scopes,
+		})
+		if err != nil {
+			log.Error("Failed to initialize Google OIDC backend")
+			log.Error(err.Error())
+			os.Exit(1)
+		}
+		authHandler.Backends.Register(googleBackend)
+	}
+	if cfg.OIDC.GitHub.Enabled {
+		authHandler.Backends.Register(backend.NewGitHubBackend(&oauth2.Config{
+			ClientID:     cfg.OIDC.GitHub.ClientID,
+			ClientSecret: cfg.OIDC.GitHub.ClientSecret,
+			RedirectURL:  cfg.OIDC.GitHub.RedirectURL,
+			Scopes:       cfg.OIDC.GitHub.Scopes,
+		}))
+	}
+
+	// LDAP-backed user provisioning and directory sync, opt-in via
+	// LDAP_ENABLED. Authenticate/Sync are only used here for the periodic
+	// reconciliation loop; interactive LDAP login continues to go through
+	// backend.LDAPBackend, a separate, simpler concern.
+	if cfg.LDAP.Enabled {
+		ldapProvider := user.NewLDAPProvider(cfg.LDAP)
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.LDAP.SyncInterval))
+			defer ticker.Stop()
+			for range ticker.C {
+				result, err := user.SyncExternal(context.Background(), userService, ldapProvider)
+				if err != nil {
+					log.Error("LDAP directory sync failed")
+					log.Error(err.Error())
+					continue
+				}
+				log.Info(fmt.Sprintf(
+					"LDAP directory sync complete: created=%d updated=%d removed=%d",
+					result.Created, result.Updated, result.Removed,
+				))
+			}
+		}()
+	}
+
+	// Authn chain protecting /metrics in production (see
+	// custommiddleware.SecureMetricsMiddleware): local Basic Auth always,
+	// plus the Keycloak issuer when federated login is enabled, so an
+	// operator can authenticate with whichever credential they already have.
+	// keycloakAPIAuth additionally lets the same issuer's tokens authenticate
+	// ordinary API requests via auth.AuthMiddleware, when
+	// OIDC_KEYCLOAK_API_BEARER_ENABLED opts into it.
+	metricsAuthenticators := []authn.Authenticator{authn.NewBasicAuthenticator(userService)}
+	var keycloakAPIAuth *authn.OIDCAuthenticator
+	if cfg.OIDC.Keycloak.Enabled {
+		keycloakAuthn, err := authn.NewOIDCAuthenticator(
+			context.Background(), cfg.OIDC.Keycloak.IssuerURL, cfg.OIDC.Keycloak.ClientID, userService,
+			authn.WithUsernameClaim(cfg.OIDC.Keycloak.UsernameClaim),
+			authn.WithGroupsClaim(cfg.OIDC.Keycloak.GroupsClaim),
+			authn.WithAllowedAudiences(cfg.OIDC.Keycloak.AllowedAudiences...),
+		)
+		if err != nil {
+			log.Error("Failed to initialize Keycloak authenticator for /metrics")
+			log.Error(err.Error())
+			os.Exit(1)
+		}
+		metricsAuthenticators = append(metricsAuthenticators, keycloakAuthn)
+		if cfg.OIDC.Keycloak.APIBearerEnabled {
+			keycloakAPIAuth = keycloakAuthn
+		}
+	}
+	metricsAuthChain := authn.NewChain(metricsAuthenticators...)
+
+	// mTLS client-certificate authentication, as an alternative (or
+	// supplement, via auth.ModeEither) to Bearer JWTs on selected routes.
+	var certAuth func(http.Handler) http.Handler
+	var mtlsClientCAs *x509.CertPool
+	if cfg.MTLS.Enabled {
+		caPEM, err := os.ReadFile(cfg.MTLS.CAFile)
+		if err != nil {
+			log.Error("Failed to read MTLS_CA_FILE")
+			log.Error(err.Error())
+			os.Exit(1)
+		}
+		mtlsClientCAs = x509.NewCertPool()
+		if !mtlsClientCAs.AppendCertsFromPEM(caPEM) {
+			log.Error("MTLS_CA_FILE contains no usable certificates")
+			os.Exit(1)
+		}
+
+		var revocation auth.CompositeRevocationChecker
+		if cfg.MTLS.CRLURL != "" {
+			crlChecker := auth.NewCRLChecker(cfg.MTLS.CRLURL)
+			revocation = append(revocation, crlChecker)
+			go func() {
+				ticker := time.NewTicker(time.Duration(cfg.MTLS.CRLRefresh))
+				defer ticker.Stop()
+				for range ticker.C {
+					if err := crlChecker.Refresh(); err != nil {
+						log.Error("Failed to refresh mTLS CRL")
+						log.Error(err.Error())
+					}
+				}
+			}()
+		}
+		if cfg.MTLS.OCSPEnabled {
+			revocation = append(revocation, auth.NewOCSPChecker())
+		}
+
+		resolver := auth.CertIdentityResolverFunc(func(ctx context.Context, identity auth.CertIdentity) (int64, error) {
+			u, err := userService.GetByEmail(ctx, identity.CommonName)
+			if err != nil {
+				return 0, fmt.Errorf("resolve cert identity: %w", err)
+			}
+			return u.ID, nil
+		})
+		certAuth = auth.CertAuthMiddleware(mtlsClientCAs, cfg.MTLS.OUAllowlist, resolver, revocation)
+	}
+
+	// TLS config applied regardless of mode "manual" or "auto" (mTLS client
+	// verification layers on top of whichever certificate source is used).
+	var tlsConfig *tls.Config
+	if cfg.MTLS.Enabled {
+		// VerifyClientCertIfGiven rather than RequireAndVerifyClientCert:
+		// most routes still accept a plain Bearer JWT over TLS with no
+		// client cert at all, and auth.CertAuthMiddleware/EitherAuthMiddleware
+		// enforce a certificate where one is actually required.
+		tlsConfig = &tls.Config{
+			ClientCAs:  mtlsClientCAs,
+			ClientAuth: tls.VerifyClientCertIfGiven,
+		}
+	}
+
+	// Built up front (rather than inside the listen switch below) so its
+	// Ready method is available to gate the HSTS header in the middleware
+	// stack registered on the router just below.
+	var autoTLSServer *pkgtls.Server
+	if cfg.Server.TLS.Mode == "auto" {
+		// SQLCache lets multiple replicas share ACME-issued certificates
+		// instead of each requesting (and rate-limiting itself against) its
+		// own; the filesystem cache is used only when no default database
+		// pool resolves.
+		var autocertCache autocert.Cache = autocert.DirCache(cfg.Server.TLS.AutoTLS.CacheDir)
+		if pool, poolErr := dbManager.Resolve(context.Background()); poolErr == nil {
+			autocertCache = pkgtls.NewSQLCache(pool.DB)
+		}
+		autoTLSServer = pkgtls.NewAutoTLSServer(nil, fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port), cfg.Server.TLS.AutoTLS, autocertCache)
+		autoTLSServer.ReadTimeout = time.Duration(cfg.Server.ReadTimeout)
+		autoTLSServer.WriteTimeout = time.Duration(cfg.Server.WriteTimeout)
+		autoTLSServer.IdleTimeout = time.Duration(cfg.Server.IdleTimeout)
+		autoTLSServer.BaseTLSConfig = tlsConfig
+	}
+
+	// Suspicious-request detector. Its ruleset can be hot-reloaded by
+	// sending the process SIGHUP, without restarting the server.
+	requestDetector, err := detector.NewDetector(cfg.Security.RulesPath, crowdsecBouncer)
+	if err != nil {
+		log.Error("Failed to load suspicious request ruleset")
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+	go func() {
+		for range reloadCh {
+			if err := requestDetector.Reload(); err != nil {
+				log.Error("Failed to reload suspicious request ruleset")
+				log.Error(err.Error())
+				continue
+			}
+			log.Info("Reloaded suspicious request ruleset")
+		}
+	}()
+
 	// Set up router
 	r := chi.NewRouter()
 
@@ -97,6 +407,7 @@ func main() {
 	r.Use(chimiddleware.RealIP)
 	r.Use(chimiddleware.Logger)
 	r.Use(chimiddleware.Recoverer)
+	r.Use(custommiddleware.RequestLogger())
 	r.Use(chimiddleware.Timeout(60 * time.Second))
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://your-frontend-domain.com", "http://localhost:3000"},
@@ -109,8 +420,18 @@ func main() {
 
 	// Add monitoring middleware
 	r.Use(custommiddleware.MetricsMiddleware())
-	r.Use(custommiddleware.SecurityHeadersMiddleware())
-	r.Use(custommiddleware.SecurityMonitoringMiddleware())
+	if autoTLSServer != nil {
+		// HSTS preload is only promised once a certificate has actually
+		// been issued; see autoTLSServer.Ready and middleware.WithHSTSGate.
+		r.Use(custommiddleware.SecurityHeadersMiddleware(custommiddleware.WithHSTSGate(autoTLSServer.Ready)))
+	} else {
+		r.Use(custommiddleware.SecurityHeadersMiddleware())
+	}
+	r.Use(custommiddleware.CompressionMiddleware(cfg.Compression))
+	if cfg.CrowdSec.Enabled {
+		r.Use(crowdsecBouncer.Middleware())
+	}
+	r.Use(custommiddleware.SecurityMonitoringMiddleware(crowdsecBouncer, requestDetector))
 	if cfg.OTel.Enabled {
 		r.Use(custommiddleware.TracingMiddleware())
 	}
@@ -122,7 +443,7 @@ func main() {
 
 	// Monitoring endpoints (protected in production)
 	r.Route("/metrics", func(r chi.Router) {
-		r.Use(custommiddleware.SecureMetricsMiddleware())
+		r.Use(custommiddleware.SecureMetricsMiddleware(metricsAuthChain))
 		r.Handle("/", promhttp.Handler())
 	})
 	r.Get("/health", healthHandler.SimpleHealthCheck)
@@ -147,20 +468,38 @@ func main() {
 		r.Use(custommiddleware.CSRFMiddleware(csrfKey))
 		r.Post("/register", authHandler.Register)
 		r.Post("/login", authHandler.Login)
+		r.Post("/logout", authHandler.Logout)
+		r.Post("/logout-all", authHandler.LogoutAll)
+		r.Post("/refresh", authHandler.Refresh)
+		authHandler.RegisterSocialRoutes(r)
 	})
 
 	// User CRUD endpoints (protected, with rate limiting, caching, and CSRF)
 	r.Route("/api/users", func(r chi.Router) {
-		r.Use(auth.AuthMiddleware(cfg.JWT.Secret))
+		r.Use(auth.AuthMiddleware(authHandler.JWTSecret, authHandler.Tokens, authHandler.Sessions, keycloakAPIAuth))
 		r.Use(custommiddleware.IPRateLimit(100, time.Minute))
 		r.Use(custommiddleware.URLPathCache(5 * time.Minute))
 		r.Use(custommiddleware.CSRFMiddleware(csrfKey))
 		userHandler.RegisterRoutes(r)
+		r.Delete("/{id}/sessions", authHandler.RevokeUserSessions)
 	})
 
-	// Job management endpoints (admin only)
+	// jwtAuth/protectedAuth protect every route below that requires an
+	// authenticated caller but no finer-grained authorization (this
+	// codebase has no role-based authorization middleware - "admin only"
+	// means "any authenticated user" same as /api/jobs always has). When
+	// mTLS is enabled, a client certificate can be used in place of a
+	// Bearer JWT (auth.ModeEither), which is how our own job-scheduling
+	// services authenticate.
+	jwtAuth := auth.AuthMiddleware(authHandler.JWTSecret, authHandler.Tokens, authHandler.Sessions, keycloakAPIAuth)
+	protectedAuth := jwtAuth
+	if certAuth != nil {
+		protectedAuth = auth.EitherAuthMiddleware(jwtAuth, certAuth)
+	}
+
+	// Job management endpoints (admin only).
 	r.Route("/api/jobs", func(r chi.Router) {
-		r.Use(auth.AuthMiddleware(cfg.JWT.Secret))
+		r.Use(protectedAuth)
 		r.Post("/email", func(w http.ResponseWriter, r *http.Request) {
 			// Example: enqueue email job
 			payload := job.EmailPayload{
@@ -176,6 +515,25 @@ func main() {
 		})
 	})
 
+	// Admin dashboard (admin only).
+	r.Group(func(r chi.Router) {
+		r.Use(protectedAuth)
+		adminHandler.RegisterRoutes(r)
+	})
+
+	// Presigned-upload endpoints (any authenticated user).
+	r.Route("/api/uploads", func(r chi.Router) {
+		r.Use(protectedAuth)
+		uploadsHandler.RegisterRoutes(r)
+	})
+
+	// OAuth2 authorization server. /oauth/token, /revoke, /introspect, and
+	// /logout authenticate the calling client themselves, so they're left
+	// off protectedAuth here; only /oauth/authorize (the resource owner's
+	// approval step) and /oauth/clients (plain CRUD with no auth of its
+	// own) need it - see RegisterRoutes.
+	oauthHandler.RegisterRoutes(r, protectedAuth)
+
 	// Basic health check endpoint
 	r.Get("/api/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -192,12 +550,25 @@ func main() {
 	srv := &http.Server{
 		Addr:         addr,
 		Handler:      r,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout),
+	}
+	switch cfg.Server.TLS.Mode {
+	case "manual":
+		srv.TLSConfig = tlsConfig
+		log.Info("Serving HTTPS with a manual certificate")
+		err = srv.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+	case "auto":
+		autoTLSServer.Handler = r
+		log.Info("Serving HTTPS with an AutoTLS (ACME) certificate")
+		err = autoTLSServer.ListenAndServeAutoTLS(context.Background(), log.Logger)
+	default:
+		srv.TLSConfig = tlsConfig
+		err = srv.ListenAndServe()
 	}
 
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err != nil && err != http.ErrServerClosed {
 		log.Error("Server error")
 		log.Error(err.Error())
 		os.Exit(1)
@@ -3,24 +3,57 @@ package admin
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
+	"connex/internal/job"
+	"connex/internal/storage"
+
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
 // Handler handles admin API requests
 type Handler struct {
-	logger *zap.Logger
+	logger    *zap.Logger
+	provider  DashboardProvider
+	logs      LogSource
+	schedules job.ScheduleStore
+	scheduler *job.Scheduler
+	storage   *storage.Client
+	uploads   storage.UploadStore
 }
 
-// NewHandler creates a new admin handler
-func NewHandler(logger *zap.Logger) *Handler {
+// NewHandler creates a new admin handler, serving dashboard data from
+// provider and recent log entries from logs instead of hard-coded mock
+// data.
+func NewHandler(logger *zap.Logger, provider DashboardProvider, logs LogSource) *Handler {
 	return &Handler{
-		logger: logger,
+		logger:   logger,
+		provider: provider,
+		logs:     logs,
 	}
 }
 
+// WithSchedules attaches a job.ScheduleStore and the job.Scheduler it backs,
+// enabling /admin/jobs/schedules. scheduler may be nil, in which case
+// mutations are persisted but not immediately synced to a running
+// scheduler - the next replica restart or periodic Sync picks them up.
+func (h *Handler) WithSchedules(store job.ScheduleStore, scheduler *job.Scheduler) *Handler {
+	h.schedules = store
+	h.scheduler = scheduler
+	return h
+}
+
+// WithStorage attaches a storage.Client and its UploadStore, enabling
+// bucket-size and per-user upload counters on /admin/system and
+// /admin/metrics.
+func (h *Handler) WithStorage(client *storage.Client, uploads storage.UploadStore) *Handler {
+	h.storage = client
+	h.uploads = uploads
+	return h
+}
+
 // RegisterRoutes registers admin routes
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/admin", func(r chi.Router) {
@@ -30,6 +63,14 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Get("/system", h.getSystemStatus)
 		r.Get("/logs", h.getLogs)
 		r.Get("/metrics", h.getMetrics)
+		r.Get("/stream", h.streamDashboard)
+		r.Route("/jobs/schedules", func(r chi.Router) {
+			r.Get("/", h.listSchedules)
+			r.Post("/", h.createSchedule)
+			r.Post("/{id}/pause", h.pauseSchedule)
+			r.Post("/{id}/resume", h.resumeSchedule)
+			r.Delete("/{id}", h.deleteSchedule)
+		})
 	})
 }
 
@@ -82,7 +123,7 @@ type DashboardRecent struct {
 
 // UserSummary represents a user summary
 type UserSummary struct {
-	ID        int       `json:"id"`
+	ID        int64     `json:"id"`
 	Name      string    `json:"name"`
 	Email     string    `json:"email"`
 	Status    string    `json:"status"`
@@ -109,107 +150,71 @@ type ActivityItem struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// dashboardActivityWindow bounds how far back getDashboardData's activity
+// chart looks.
+const dashboardActivityWindow = 7 * 24 * time.Hour
+
+// topUploadersLimit bounds how many rows getMetrics' per-user upload
+// counters return.
+const topUploadersLimit = 10
+
 // getDashboardData returns comprehensive dashboard data
 func (h *Handler) getDashboardData(w http.ResponseWriter, r *http.Request) {
-	// In a real application, this would fetch data from the database
-	// For now, we'll return mock data
+	ctx := r.Context()
+
+	stats, err := h.provider.Stats(ctx)
+	if err != nil {
+		h.logger.Error("failed to load dashboard stats", zap.Error(err))
+		http.Error(w, "failed to load dashboard data", http.StatusInternalServerError)
+		return
+	}
+
+	userActivity, err := h.provider.UserActivity(ctx, dashboardActivityWindow)
+	if err != nil {
+		h.logger.Error("failed to load user activity", zap.Error(err))
+		http.Error(w, "failed to load dashboard data", http.StatusInternalServerError)
+		return
+	}
+
+	health, err := h.provider.SystemHealth(ctx)
+	if err != nil {
+		h.logger.Error("failed to load system health", zap.Error(err))
+		http.Error(w, "failed to load dashboard data", http.StatusInternalServerError)
+		return
+	}
+
+	recentUsers, err := h.provider.RecentUsers(ctx, ListParams{Limit: 5})
+	if err != nil {
+		h.logger.Error("failed to load recent users", zap.Error(err))
+		http.Error(w, "failed to load dashboard data", http.StatusInternalServerError)
+		return
+	}
+
+	recentOrders, err := h.provider.RecentOrders(ctx, ListParams{Limit: 5})
+	if err != nil {
+		h.logger.Error("failed to load recent orders", zap.Error(err))
+		http.Error(w, "failed to load dashboard data", http.StatusInternalServerError)
+		return
+	}
+
+	activity, err := h.provider.Activity(ctx, ListParams{Limit: 5})
+	if err != nil {
+		h.logger.Error("failed to load activity log", zap.Error(err))
+		http.Error(w, "failed to load dashboard data", http.StatusInternalServerError)
+		return
+	}
+
 	data := DashboardData{
-		Stats: DashboardStats{
-			TotalUsers:    1247,
-			ActiveUsers:   892,
-			TotalRevenue:  45231.50,
-			TotalOrders:   3456,
-			UserGrowth:    12.5,
-			RevenueGrowth: 15.2,
-			OrderGrowth:   -2.1,
-		},
+		Stats: stats,
 		Charts: DashboardCharts{
-			UserActivity: []ChartPoint{
-				{Label: "Mon", Value: 65},
-				{Label: "Tue", Value: 59},
-				{Label: "Wed", Value: 80},
-				{Label: "Thu", Value: 81},
-				{Label: "Fri", Value: 56},
-				{Label: "Sat", Value: 55},
-				{Label: "Sun", Value: 40},
-			},
-			SystemHealth: SystemHealth{
-				CPU:     65.0,
-				Memory:  45.0,
-				Disk:    30.0,
-				Network: 80.0,
-			},
+			UserActivity: userActivity,
+			SystemHealth: health,
 		},
 		Recent: DashboardRecent{
-			Users: []UserSummary{
-				{
-					ID:        1,
-					Name:      "John Doe",
-					Email:     "john@example.com",
-					Status:    "active",
-					LastLogin: time.Now().Add(-2 * time.Hour),
-					CreatedAt: time.Now().AddDate(0, -1, 0),
-				},
-				{
-					ID:        2,
-					Name:      "Jane Smith",
-					Email:     "jane@example.com",
-					Status:    "active",
-					LastLogin: time.Now().Add(-1 * time.Hour),
-					CreatedAt: time.Now().AddDate(0, -2, 0),
-				},
-				{
-					ID:        3,
-					Name:      "Bob Johnson",
-					Email:     "bob@example.com",
-					Status:    "inactive",
-					LastLogin: time.Now().AddDate(0, 0, -3),
-					CreatedAt: time.Now().AddDate(0, -3, 0),
-				},
-			},
-			Orders: []OrderSummary{
-				{
-					ID:        1,
-					UserID:    1,
-					Amount:    299.99,
-					Status:    "completed",
-					CreatedAt: time.Now().Add(-1 * time.Hour),
-				},
-				{
-					ID:        2,
-					UserID:    2,
-					Amount:    149.50,
-					Status:    "pending",
-					CreatedAt: time.Now().Add(-2 * time.Hour),
-				},
-			},
-		},
-		Activity: []ActivityItem{
-			{
-				ID:        1,
-				Type:      "login",
-				User:      "John Doe",
-				Action:    "logged in",
-				Details:   "User logged in from 192.168.1.100",
-				Timestamp: time.Now().Add(-2 * time.Minute),
-			},
-			{
-				ID:        2,
-				Type:      "create",
-				User:      "Jane Smith",
-				Action:    "created a new account",
-				Details:   "New user registration",
-				Timestamp: time.Now().Add(-5 * time.Minute),
-			},
-			{
-				ID:        3,
-				Type:      "update",
-				User:      "Bob Johnson",
-				Action:    "updated their profile",
-				Details:   "Profile information updated",
-				Timestamp: time.Now().Add(-10 * time.Minute),
-			},
+			Users:  recentUsers,
+			Orders: recentOrders,
 		},
+		Activity:  activity,
 		Timestamp: time.Now(),
 	}
 
@@ -218,48 +223,12 @@ func (h *Handler) getDashboardData(w http.ResponseWriter, r *http.Request) {
 
 // getUsers returns user management data
 func (h *Handler) getUsers(w http.ResponseWriter, r *http.Request) {
-	// Mock user data
-	users := []UserSummary{
-		{
-			ID:        1,
-			Name:      "John Doe",
-			Email:     "john@example.com",
-			Status:    "active",
-			LastLogin: time.Now().Add(-2 * time.Hour),
-			CreatedAt: time.Now().AddDate(0, -1, 0),
-		},
-		{
-			ID:        2,
-			Name:      "Jane Smith",
-			Email:     "jane@example.com",
-			Status:    "active",
-			LastLogin: time.Now().Add(-1 * time.Hour),
-			CreatedAt: time.Now().AddDate(0, -2, 0),
-		},
-		{
-			ID:        3,
-			Name:      "Bob Johnson",
-			Email:     "bob@example.com",
-			Status:    "inactive",
-			LastLogin: time.Now().AddDate(0, 0, -3),
-			CreatedAt: time.Now().AddDate(0, -3, 0),
-		},
-		{
-			ID:        4,
-			Name:      "Alice Brown",
-			Email:     "alice@example.com",
-			Status:    "pending",
-			LastLogin: time.Time{},
-			CreatedAt: time.Now().AddDate(0, 0, -1),
-		},
-		{
-			ID:        5,
-			Name:      "Charlie Wilson",
-			Email:     "charlie@example.com",
-			Status:    "active",
-			LastLogin: time.Now().Add(-30 * time.Minute),
-			CreatedAt: time.Now().AddDate(0, -1, -15),
-		},
+	params := parseListParams(r)
+	users, err := h.provider.RecentUsers(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to load users", zap.Error(err))
+		http.Error(w, "failed to load users", http.StatusInternalServerError)
+		return
 	}
 
 	h.respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -270,43 +239,35 @@ func (h *Handler) getUsers(w http.ResponseWriter, r *http.Request) {
 
 // getAnalytics returns analytics data
 func (h *Handler) getAnalytics(w http.ResponseWriter, r *http.Request) {
-	// Mock analytics data
+	ctx := r.Context()
+
+	userGrowth, err := h.provider.UserActivity(ctx, 180*24*time.Hour)
+	if err != nil {
+		h.logger.Error("failed to load analytics", zap.Error(err))
+		http.Error(w, "failed to load analytics", http.StatusInternalServerError)
+		return
+	}
+
+	orders, err := h.provider.RecentOrders(ctx, ListParams{Limit: 100})
+	if err != nil {
+		h.logger.Error("failed to load analytics", zap.Error(err))
+		http.Error(w, "failed to load analytics", http.StatusInternalServerError)
+		return
+	}
+
+	revenueByDay := map[string]float64{}
+	for _, o := range orders {
+		label := o.CreatedAt.Format("Mon")
+		revenueByDay[label] += o.Amount
+	}
+	revenueTrend := make([]ChartPoint, 0, len(revenueByDay))
+	for label, total := range revenueByDay {
+		revenueTrend = append(revenueTrend, ChartPoint{Label: label, Value: total})
+	}
+
 	analytics := map[string]interface{}{
-		"user_growth": []ChartPoint{
-			{Label: "Jan", Value: 100},
-			{Label: "Feb", Value: 150},
-			{Label: "Mar", Value: 200},
-			{Label: "Apr", Value: 250},
-			{Label: "May", Value: 300},
-			{Label: "Jun", Value: 350},
-		},
-		"revenue_trend": []ChartPoint{
-			{Label: "Jan", Value: 5000},
-			{Label: "Feb", Value: 7500},
-			{Label: "Mar", Value: 10000},
-			{Label: "Apr", Value: 12500},
-			{Label: "May", Value: 15000},
-			{Label: "Jun", Value: 17500},
-		},
-		"top_products": []map[string]interface{}{
-			{"name": "Product A", "sales": 150, "revenue": 7500},
-			{"name": "Product B", "sales": 120, "revenue": 6000},
-			{"name": "Product C", "sales": 100, "revenue": 5000},
-		},
-		"user_demographics": map[string]interface{}{
-			"age_groups": map[string]int{
-				"18-25": 30,
-				"26-35": 45,
-				"36-45": 15,
-				"46+":   10,
-			},
-			"locations": map[string]int{
-				"US":    40,
-				"EU":    30,
-				"Asia":  20,
-				"Other": 10,
-			},
-		},
+		"user_growth":   userGrowth,
+		"revenue_trend": revenueTrend,
 	}
 
 	h.respondJSON(w, http.StatusOK, analytics)
@@ -314,107 +275,124 @@ func (h *Handler) getAnalytics(w http.ResponseWriter, r *http.Request) {
 
 // getSystemStatus returns system status information
 func (h *Handler) getSystemStatus(w http.ResponseWriter, r *http.Request) {
-	// Mock system status data
+	health, err := h.provider.SystemHealth(r.Context())
+	if err != nil {
+		h.logger.Error("failed to load system health", zap.Error(err))
+		http.Error(w, "failed to load system status", http.StatusInternalServerError)
+		return
+	}
+
+	queues, err := job.Queues()
+	if err != nil {
+		h.logger.Warn("failed to load queue stats", zap.Error(err))
+		queues = nil
+	}
+
 	status := map[string]interface{}{
 		"system": map[string]interface{}{
-			"cpu_usage":     65.0,
-			"memory_usage":  45.0,
-			"disk_usage":    30.0,
-			"network_usage": 80.0,
-			"uptime":        "15 days, 3 hours, 27 minutes",
-			"load_average":  []float64{1.2, 1.1, 0.9},
-		},
-		"services": []map[string]interface{}{
-			{"name": "Web Server", "status": "healthy", "uptime": "99.9%"},
-			{"name": "Database", "status": "healthy", "uptime": "99.8%"},
-			{"name": "Redis Cache", "status": "healthy", "uptime": "99.9%"},
-			{"name": "Job Queue", "status": "warning", "uptime": "98.5%"},
-		},
-		"security": map[string]interface{}{
-			"last_scan":       time.Now().Add(-6 * time.Hour),
-			"vulnerabilities": 0,
-			"failed_logins":   12,
-			"blocked_ips":     3,
+			"cpu_usage":     health.CPU,
+			"memory_usage":  health.Memory,
+			"disk_usage":    health.Disk,
+			"network_usage": health.Network,
 		},
+		"queues": queues,
+	}
+
+	if h.storage != nil {
+		if stats, err := h.storage.BucketStats(r.Context()); err != nil {
+			h.logger.Warn("failed to load bucket stats", zap.Error(err))
+		} else {
+			status["storage"] = stats
+		}
 	}
 
 	h.respondJSON(w, http.StatusOK, status)
 }
 
-// getLogs returns system logs
+// getLogs returns recent application log entries, tailed from the
+// logger.RingBuffer behind h.logs.
 func (h *Handler) getLogs(w http.ResponseWriter, r *http.Request) {
-	// Mock log data
-	logs := []map[string]interface{}{
-		{
-			"timestamp": time.Now().Add(-1 * time.Minute),
-			"level":     "INFO",
-			"message":   "User login successful",
-			"user_id":   1,
-			"ip":        "192.168.1.100",
-		},
-		{
-			"timestamp": time.Now().Add(-2 * time.Minute),
-			"level":     "WARN",
-			"message":   "High memory usage detected",
-			"details":   "Memory usage: 85%",
-		},
-		{
-			"timestamp": time.Now().Add(-3 * time.Minute),
-			"level":     "ERROR",
-			"message":   "Database connection failed",
-			"details":   "Connection timeout after 30 seconds",
-		},
-		{
-			"timestamp": time.Now().Add(-5 * time.Minute),
-			"level":     "INFO",
-			"message":   "New user registration",
-			"user_id":   5,
-			"email":     "charlie@example.com",
-		},
-		{
-			"timestamp": time.Now().Add(-10 * time.Minute),
-			"level":     "INFO",
-			"message":   "Backup completed successfully",
-			"details":   "Database backup: 2.3GB",
-		},
+	params := parseListParams(r)
+	n := params.Limit
+	if n <= 0 {
+		n = defaultListLimit
+	}
+
+	entries := h.logs.Entries(n)
+	if params.Level != "" {
+		filtered := entries[:0]
+		for _, e := range entries {
+			if e.Level == params.Level {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
 	}
 
 	h.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"logs":  logs,
-		"total": len(logs),
+		"logs":  entries,
+		"total": len(entries),
 	})
 }
 
-// getMetrics returns system metrics
+// getMetrics returns job queue depth metrics. HTTP/cache/websocket metrics
+// aren't collected anywhere in this codebase yet, so they're omitted rather
+// than fabricated.
 func (h *Handler) getMetrics(w http.ResponseWriter, r *http.Request) {
-	// Mock metrics data
+	queues, err := job.Queues()
+	if err != nil {
+		h.logger.Error("failed to load queue metrics", zap.Error(err))
+		http.Error(w, "failed to load metrics", http.StatusInternalServerError)
+		return
+	}
+
 	metrics := map[string]interface{}{
-		"http_requests": map[string]interface{}{
-			"total":             15420,
-			"success":           15200,
-			"errors":            220,
-			"avg_response_time": 245,
-		},
-		"database": map[string]interface{}{
-			"connections":     25,
-			"queries_per_sec": 150,
-			"slow_queries":    3,
-		},
-		"cache": map[string]interface{}{
-			"hit_rate":     85.5,
-			"miss_rate":    14.5,
-			"memory_usage": "512MB",
-		},
-		"websocket": map[string]interface{}{
-			"active_connections": 45,
-			"messages_per_min":   120,
-			"rooms":              8,
-		},
+		"queues": queues,
+	}
+
+	if h.storage != nil {
+		if stats, err := h.storage.BucketStats(r.Context()); err != nil {
+			h.logger.Warn("failed to load bucket stats", zap.Error(err))
+		} else {
+			metrics["storage"] = stats
+		}
+	}
+
+	if h.uploads != nil {
+		if top, err := h.uploads.TopUploaders(r.Context(), topUploadersLimit); err != nil {
+			h.logger.Warn("failed to load top uploaders", zap.Error(err))
+		} else {
+			metrics["top_uploaders"] = top
+		}
 	}
 
 	h.respondJSON(w, http.StatusOK, metrics)
 }
 
+// parseListParams reads ?limit=&cursor=&status=&level=&from=&to= from r,
+// ignoring unparseable values rather than rejecting the request.
+func parseListParams(r *http.Request) ListParams {
+	q := r.URL.Query()
+	var params ListParams
+
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil {
+		params.Limit = v
+	}
+	if v, err := strconv.ParseInt(q.Get("cursor"), 10, 64); err == nil {
+		params.Cursor = v
+	}
+	params.Status = q.Get("status")
+	params.Level = q.Get("level")
+	if v, err := time.Parse(time.RFC3339, q.Get("from")); err == nil {
+		params.From = v
+	}
+	if v, err := time.Parse(time.RFC3339, q.Get("to")); err == nil {
+		params.To = v
+	}
+
+	return params
+}
+
 // respondJSON sends a JSON response
 func (h *Handler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,294 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"connex/internal/db"
+)
+
+// defaultListLimit caps RecentUsers/RecentOrders/Activity when the caller's
+// ListParams.Limit is unset.
+const defaultListLimit = 20
+
+// PostgresProvider is a DashboardProvider backed by the users/orders/
+// activity_log tables and process/host resource stats.
+//
+// The users table has no last-login or session tracking (see
+// internal/api/user.User), so ActiveUsers/UserSummary.Status approximate
+// "active" as "updated in the last 30 days" rather than a true last-seen
+// timestamp; wiring in auth.RedisSessionStore for a precise signal is future
+// work, not something this provider fabricates.
+type PostgresProvider struct {
+	db *db.Manager
+}
+
+// NewPostgresProvider builds a PostgresProvider resolving pools from
+// manager.
+func NewPostgresProvider(manager *db.Manager) *PostgresProvider {
+	return &PostgresProvider{db: manager}
+}
+
+func (p *PostgresProvider) Stats(ctx context.Context) (DashboardStats, error) {
+	pool, err := p.db.Resolve(ctx)
+	if err != nil {
+		return DashboardStats{}, err
+	}
+
+	const q = `SELECT
+		(SELECT count(*) FROM users WHERE deleted_at IS NULL) AS total_users,
+		(SELECT count(*) FROM users WHERE deleted_at IS NULL AND updated_at > NOW() - INTERVAL '30 days') AS active_users,
+		(SELECT count(*) FROM users WHERE deleted_at IS NULL AND created_at > NOW() - INTERVAL '30 days') AS new_users_current,
+		(SELECT count(*) FROM users WHERE deleted_at IS NULL AND created_at > NOW() - INTERVAL '60 days' AND created_at <= NOW() - INTERVAL '30 days') AS new_users_previous,
+		(SELECT coalesce(sum(amount), 0) FROM orders WHERE created_at > NOW() - INTERVAL '30 days') AS revenue_current,
+		(SELECT coalesce(sum(amount), 0) FROM orders WHERE created_at > NOW() - INTERVAL '60 days' AND created_at <= NOW() - INTERVAL '30 days') AS revenue_previous,
+		(SELECT count(*) FROM orders WHERE created_at > NOW() - INTERVAL '30 days') AS orders_current,
+		(SELECT count(*) FROM orders WHERE created_at > NOW() - INTERVAL '60 days' AND created_at <= NOW() - INTERVAL '30 days') AS orders_previous`
+
+	var totalUsers, activeUsers, newUsersCur, newUsersPrev, ordersCur, ordersPrev int
+	var revenueCur, revenuePrev float64
+	row := pool.QueryRowxContext(ctx, q)
+	if err := row.Scan(
+		&totalUsers, &activeUsers, &newUsersCur, &newUsersPrev,
+		&revenueCur, &revenuePrev, &ordersCur, &ordersPrev,
+	); err != nil {
+		return DashboardStats{}, fmt.Errorf("query dashboard stats: %w", err)
+	}
+
+	return DashboardStats{
+		TotalUsers:    totalUsers,
+		ActiveUsers:   activeUsers,
+		TotalRevenue:  revenueCur,
+		TotalOrders:   ordersCur,
+		UserGrowth:    growthPercent(float64(newUsersCur), float64(newUsersPrev)),
+		RevenueGrowth: growthPercent(revenueCur, revenuePrev),
+		OrderGrowth:   growthPercent(float64(ordersCur), float64(ordersPrev)),
+	}, nil
+}
+
+// growthPercent computes the percentage change from previous to current,
+// treating a zero previous value as 100% growth if current is positive and
+// 0% otherwise (an empty baseline can't have a meaningful ratio).
+func growthPercent(current, previous float64) float64 {
+	if previous == 0 {
+		if current > 0 {
+			return 100
+		}
+		return 0
+	}
+	return (current - previous) / previous * 100
+}
+
+func (p *PostgresProvider) UserActivity(ctx context.Context, window time.Duration) ([]ChartPoint, error) {
+	pool, err := p.db.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `SELECT date_trunc('day', created_at) AS day, count(*)
+	      FROM users
+	      WHERE deleted_at IS NULL AND created_at > NOW() - $1::interval
+	      GROUP BY day ORDER BY day`
+	rows, err := pool.QueryxContext(ctx, q, window.String())
+	if err != nil {
+		return nil, fmt.Errorf("query user activity: %w", err)
+	}
+	defer rows.Close()
+
+	var points []ChartPoint
+	for rows.Next() {
+		var day time.Time
+		var count float64
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("scan user activity: %w", err)
+		}
+		points = append(points, ChartPoint{Label: day.Format("Mon"), Value: count})
+	}
+	return points, nil
+}
+
+func (p *PostgresProvider) RecentUsers(ctx context.Context, params ListParams) ([]UserSummary, error) {
+	pool, err := p.db.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT id, name, email, created_at, updated_at FROM users WHERE deleted_at IS NULL`)
+	args := []interface{}{}
+	appendListFilters(&b, &args, params, "id", "created_at")
+	b.WriteString(` ORDER BY id DESC LIMIT `)
+	fmt.Fprintf(&b, "%d", listLimit(params))
+
+	rows, err := pool.QueryxContext(ctx, b.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query recent users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []UserSummary
+	for rows.Next() {
+		var id int64
+		var name, email string
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&id, &name, &email, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan recent user: %w", err)
+		}
+		status := "inactive"
+		if time.Since(updatedAt) < 30*24*time.Hour {
+			status = "active"
+		}
+		users = append(users, UserSummary{
+			ID: id, Name: name, Email: email, Status: status, CreatedAt: createdAt,
+		})
+	}
+	return users, nil
+}
+
+func (p *PostgresProvider) RecentOrders(ctx context.Context, params ListParams) ([]OrderSummary, error) {
+	pool, err := p.db.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT id, user_id, amount, status, created_at FROM orders WHERE 1=1`)
+	args := []interface{}{}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		fmt.Fprintf(&b, " AND status = $%d", len(args))
+	}
+	appendListFilters(&b, &args, params, "id", "created_at")
+	b.WriteString(` ORDER BY id DESC LIMIT `)
+	fmt.Fprintf(&b, "%d", listLimit(params))
+
+	rows, err := pool.QueryxContext(ctx, b.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query recent orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []OrderSummary
+	for rows.Next() {
+		var o OrderSummary
+		var id, userID int64
+		if err := rows.Scan(&id, &userID, &o.Amount, &o.Status, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan recent order: %w", err)
+		}
+		o.ID = int(id)
+		o.UserID = int(userID)
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+func (p *PostgresProvider) Activity(ctx context.Context, params ListParams) ([]ActivityItem, error) {
+	pool, err := p.db.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT id, type, user_name, action, details, created_at FROM activity_log WHERE 1=1`)
+	args := []interface{}{}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		fmt.Fprintf(&b, " AND type = $%d", len(args))
+	}
+	appendListFilters(&b, &args, params, "id", "created_at")
+	b.WriteString(` ORDER BY id DESC LIMIT `)
+	fmt.Fprintf(&b, "%d", listLimit(params))
+
+	rows, err := pool.QueryxContext(ctx, b.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query activity log: %w", err)
+	}
+	defer rows.Close()
+
+	var items []ActivityItem
+	for rows.Next() {
+		var item ActivityItem
+		var id int64
+		if err := rows.Scan(&id, &item.Type, &item.User, &item.Action, &item.Details, &item.Timestamp); err != nil {
+			return nil, fmt.Errorf("scan activity item: %w", err)
+		}
+		item.ID = int(id)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// appendListFilters appends the Cursor/From/To clauses ListParams describes
+// to b, parameterizing them onto args in order. idColumn/timeColumn name the
+// columns they apply to, so callers sharing this helper can point it at
+// whichever table they're querying.
+func appendListFilters(b *strings.Builder, args *[]interface{}, params ListParams, idColumn, timeColumn string) {
+	if params.Cursor > 0 {
+		*args = append(*args, params.Cursor)
+		fmt.Fprintf(b, " AND %s < $%d", idColumn, len(*args))
+	}
+	if !params.From.IsZero() {
+		*args = append(*args, params.From)
+		fmt.Fprintf(b, " AND %s >= $%d", timeColumn, len(*args))
+	}
+	if !params.To.IsZero() {
+		*args = append(*args, params.To)
+		fmt.Fprintf(b, " AND %s <= $%d", timeColumn, len(*args))
+	}
+}
+
+func listLimit(params ListParams) int {
+	if params.Limit > 0 {
+		return params.Limit
+	}
+	return defaultListLimit
+}
+
+// SystemHealth reports Memory from runtime.MemStats and Disk from a statfs
+// of "/", both real process/host measurements. CPU approximates load as
+// goroutines-per-CPU (there's no portable stdlib source for host CPU%
+// without adding a metrics dependency like gopsutil); Network is left at 0
+// pending a real byte-rate source, rather than fabricating a number.
+func (p *PostgresProvider) SystemHealth(ctx context.Context) (SystemHealth, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	memPercent := 0.0
+	if mem.Sys > 0 {
+		memPercent = float64(mem.Alloc) / float64(mem.Sys) * 100
+	}
+
+	cpuPercent := float64(runtime.NumGoroutine()) / float64(runtime.NumCPU()*50) * 100
+	if cpuPercent > 100 {
+		cpuPercent = 100
+	}
+
+	diskPercent, err := diskUsagePercent("/")
+	if err != nil {
+		diskPercent = 0
+	}
+
+	return SystemHealth{
+		CPU:     cpuPercent,
+		Memory:  memPercent,
+		Disk:    diskPercent,
+		Network: 0,
+	}, nil
+}
+
+func diskUsagePercent(path string) (float64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	total := stat.Blocks * uint64(stat.Bsize)
+	if total == 0 {
+		return 0, nil
+	}
+	free := stat.Bfree * uint64(stat.Bsize)
+	used := total - free
+	return float64(used) / float64(total) * 100, nil
+}
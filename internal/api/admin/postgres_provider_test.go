@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"connex/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProvider wraps a sqlmock-backed *sql.DB in a *db.Manager registered
+// under db.DefaultTenant, so PostgresProvider.db.Resolve(ctx) finds it
+// exactly as it would a real pool.
+func newTestProvider(mockDB *sql.DB) *PostgresProvider {
+	manager := db.NewManager()
+	manager.SetPool(db.DefaultTenant, sqlx.NewDb(mockDB, "sqlmock"))
+	return NewPostgresProvider(manager)
+}
+
+func TestPostgresProvider_Stats(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	provider := newTestProvider(mockDB)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_users", "active_users", "new_users_current", "new_users_previous",
+			"revenue_current", "revenue_previous", "orders_current", "orders_previous",
+		}).AddRow(100, 40, 20, 10, 500.0, 250.0, 5, 0))
+
+	stats, err := provider.Stats(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, stats.TotalUsers)
+	assert.Equal(t, 40, stats.ActiveUsers)
+	assert.Equal(t, 500.0, stats.TotalRevenue)
+	assert.Equal(t, 5, stats.TotalOrders)
+	assert.InDelta(t, 100.0, stats.UserGrowth, 0.001)
+	assert.InDelta(t, 100.0, stats.RevenueGrowth, 0.001)
+	assert.InDelta(t, 100.0, stats.OrderGrowth, 0.001)
+}
+
+func TestPostgresProvider_RecentUsers(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	provider := newTestProvider(mockDB)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, name, email, created_at, updated_at FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "created_at", "updated_at"}).
+			AddRow(1, "John Doe", "john@example.com", now, now).
+			AddRow(2, "Jane Smith", "jane@example.com", now, now.AddDate(0, -1, 0)))
+
+	users, err := provider.RecentUsers(context.Background(), ListParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	assert.Equal(t, int64(1), users[0].ID)
+	assert.Equal(t, "active", users[0].Status)
+	assert.Equal(t, "inactive", users[1].Status)
+}
+
+func TestGrowthPercent(t *testing.T) {
+	assert.Equal(t, 100.0, growthPercent(5, 0))
+	assert.Equal(t, 0.0, growthPercent(0, 0))
+	assert.InDelta(t, 50.0, growthPercent(15, 10), 0.001)
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"connex/pkg/logger"
+)
+
+// ListParams captures the pagination, filtering, and time-range query
+// parameters /admin's listing endpoints accept (?limit=&cursor=&status=&
+// level=&from=&to=).
+type ListParams struct {
+	// Limit bounds how many rows a call returns; callers should apply a
+	// sane default when it's <= 0.
+	Limit int
+
+	// Cursor is an exclusive keyset-pagination bound: "rows with ID less
+	// than Cursor", so repeated calls page backwards through history
+	// without the row-count drift an OFFSET would have under concurrent
+	// writes.
+	Cursor int64
+
+	// Status filters RecentOrders by their status column and Activity by its
+	// type column (RecentUsers ignores it); Level filters LogSource entries
+	// by log level. Empty means unfiltered.
+	Status string
+	Level  string
+
+	// From/To bound rows by their timestamp column. Zero means unbounded on
+	// that side.
+	From time.Time
+	To   time.Time
+}
+
+// DashboardProvider supplies the live data behind /admin/*, backed by the
+// database and job queue instead of hard-coded values.
+type DashboardProvider interface {
+	// Stats returns the top-line counters/growth percentages for
+	// /admin/dashboard.
+	Stats(ctx context.Context) (DashboardStats, error)
+
+	// UserActivity buckets user signups over window (e.g. 7*24h),
+	// one ChartPoint per day, for the dashboard's activity chart.
+	UserActivity(ctx context.Context, window time.Duration) ([]ChartPoint, error)
+
+	// RecentUsers returns the most recently created users matching params.
+	RecentUsers(ctx context.Context, params ListParams) ([]UserSummary, error)
+
+	// RecentOrders returns the most recent orders matching params.
+	RecentOrders(ctx context.Context, params ListParams) ([]OrderSummary, error)
+
+	// Activity returns the most recent audit/activity log entries matching
+	// params.
+	Activity(ctx context.Context, params ListParams) ([]ActivityItem, error)
+
+	// SystemHealth reports current process/host resource usage.
+	SystemHealth(ctx context.Context) (SystemHealth, error)
+}
+
+// LogSource reports recent application log entries for /admin/logs.
+type LogSource interface {
+	Entries(n int) []logger.Entry
+}
+
+// ZapTapLogSource is a LogSource backed by a logger.RingBuffer tapping the
+// application's zap logger, so /admin/logs streams real log output instead
+// of needing to scrape stdout or a file.
+type ZapTapLogSource struct {
+	ring *logger.RingBuffer
+}
+
+// NewZapTapLogSource builds a ZapTapLogSource reading from ring (see
+// logger.GetRingBuffer).
+func NewZapTapLogSource(ring *logger.RingBuffer) *ZapTapLogSource {
+	return &ZapTapLogSource{ring: ring}
+}
+
+func (s *ZapTapLogSource) Entries(n int) []logger.Entry {
+	if s.ring == nil {
+		return nil
+	}
+	return s.ring.Entries(n)
+}
@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connex/internal/job"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// createScheduleRequest is the /admin/jobs/schedules POST body.
+type createScheduleRequest struct {
+	Spec      string `json:"spec"`
+	TaskType  string `json:"task_type"`
+	Payload   string `json:"payload"`
+	Queue     string `json:"queue"`
+	Retention int64  `json:"retention_seconds"`
+}
+
+// listSchedules returns every persisted schedule definition, enabled or
+// paused.
+func (h *Handler) listSchedules(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		http.Error(w, "schedule store not configured", http.StatusNotImplemented)
+		return
+	}
+
+	defs, err := h.schedules.List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list job schedules", zap.Error(err))
+		http.Error(w, "failed to list job schedules", http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"schedules": defs,
+		"total":     len(defs),
+	})
+}
+
+// createSchedule persists a new schedule definition and, if a Scheduler is
+// attached, syncs it in immediately rather than waiting for the next
+// periodic Sync.
+func (h *Handler) createSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		http.Error(w, "schedule store not configured", http.StatusNotImplemented)
+		return
+	}
+
+	var req createScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Spec == "" || req.TaskType == "" {
+		http.Error(w, "spec and task_type are required", http.StatusBadRequest)
+		return
+	}
+
+	def, err := h.schedules.Create(r.Context(), job.ScheduleDefinition{
+		Spec:      req.Spec,
+		TaskType:  req.TaskType,
+		Payload:   req.Payload,
+		Queue:     req.Queue,
+		Retention: time.Duration(req.Retention) * time.Second,
+	})
+	if err != nil {
+		h.logger.Error("failed to create job schedule", zap.Error(err))
+		http.Error(w, "failed to create job schedule", http.StatusInternalServerError)
+		return
+	}
+
+	h.syncScheduler(r)
+	h.respondJSON(w, http.StatusCreated, def)
+}
+
+// pauseSchedule disables a schedule without deleting its definition.
+func (h *Handler) pauseSchedule(w http.ResponseWriter, r *http.Request) {
+	h.setScheduleEnabled(w, r, false)
+}
+
+// resumeSchedule re-enables a previously paused schedule.
+func (h *Handler) resumeSchedule(w http.ResponseWriter, r *http.Request) {
+	h.setScheduleEnabled(w, r, true)
+}
+
+func (h *Handler) setScheduleEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	if h.schedules == nil {
+		http.Error(w, "schedule store not configured", http.StatusNotImplemented)
+		return
+	}
+
+	id, err := scheduleIDParam(r)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.schedules.SetEnabled(r.Context(), id, enabled); err != nil {
+		h.logger.Error("failed to update job schedule", zap.Int64("id", id), zap.Error(err))
+		http.Error(w, "failed to update job schedule", http.StatusInternalServerError)
+		return
+	}
+
+	h.syncScheduler(r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteSchedule removes a schedule definition entirely.
+func (h *Handler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		http.Error(w, "schedule store not configured", http.StatusNotImplemented)
+		return
+	}
+
+	id, err := scheduleIDParam(r)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.schedules.Delete(r.Context(), id); err != nil {
+		h.logger.Error("failed to delete job schedule", zap.Int64("id", id), zap.Error(err))
+		http.Error(w, "failed to delete job schedule", http.StatusInternalServerError)
+		return
+	}
+
+	h.syncScheduler(r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// syncScheduler re-applies the store's current definitions to h.scheduler,
+// if one is attached, so this replica's asynq registrations reflect the
+// mutation that was just made without waiting for the next periodic Sync.
+// Sync failures are logged, not surfaced to the caller: the write already
+// succeeded, and other replicas (or the next periodic Sync) will converge.
+func (h *Handler) syncScheduler(r *http.Request) {
+	if h.scheduler == nil {
+		return
+	}
+	defs, err := h.schedules.List(r.Context())
+	if err != nil {
+		h.logger.Warn("failed to reload job schedules for sync", zap.Error(err))
+		return
+	}
+	if err := h.scheduler.Sync(defs); err != nil {
+		h.logger.Warn("failed to sync job scheduler", zap.Error(err))
+	}
+}
+
+func scheduleIDParam(r *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+}
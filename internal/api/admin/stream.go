@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"connex/internal/events"
+	"connex/internal/job"
+
+	"go.uber.org/zap"
+)
+
+// streamHeartbeatInterval is how often streamDashboard writes an SSE
+// comment line to keep idle connections (and intermediate proxies) alive.
+const streamHeartbeatInterval = 15 * time.Second
+
+// streamStatsInterval is how often streamDashboard pushes a fresh snapshot
+// of DashboardStats/SystemHealth/queue depths, independent of whatever
+// request/job events happen to fire on the events.Bus in between.
+const streamStatsInterval = 5 * time.Second
+
+// streamEvent is one Server-Sent Event frame, matching the "id:"/"event:"/
+// "data:" fields the SSE spec defines; id doubles as the Last-Event-ID a
+// reconnecting client echoes back to resume.
+type streamEvent struct {
+	id   string
+	name string
+	data interface{}
+}
+
+// streamDashboard upgrades to text/event-stream and pushes live dashboard
+// data to connected admins: bus events (job enqueue/success/failure,
+// request completions) are forwarded as they're published, and a
+// stats/health/queues snapshot is pushed every streamStatsInterval. Clients
+// that reconnect with a Last-Event-ID header resume from the events.Bus's
+// buffered history instead of missing whatever was published while they
+// were disconnected.
+func (h *Handler) streamDashboard(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sub := events.Subscribe(r.Header.Get("Last-Event-ID"))
+	defer events.Unsubscribe(sub)
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	stats := time.NewTicker(streamStatsInterval)
+	defer stats.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case ev := <-sub.Events():
+			h.writeStreamEvent(w, streamEvent{id: ev.ID, name: ev.Type, data: ev.Data})
+			flusher.Flush()
+
+		case <-stats.C:
+			h.writeStatsSnapshot(w, r)
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeStatsSnapshot writes the dashboard's live stats/health/queues as
+// three SSE events, logging (rather than failing the whole stream) on a
+// provider error so one bad fetch doesn't drop the connection.
+func (h *Handler) writeStatsSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if stats, err := h.provider.Stats(ctx); err != nil {
+		h.logger.Warn("stream: failed to load stats", zap.Error(err))
+	} else {
+		h.writeStreamEvent(w, streamEvent{name: "stats", data: stats})
+	}
+
+	if health, err := h.provider.SystemHealth(ctx); err != nil {
+		h.logger.Warn("stream: failed to load system health", zap.Error(err))
+	} else {
+		h.writeStreamEvent(w, streamEvent{name: "health", data: health})
+	}
+
+	if queues, err := job.Queues(); err != nil {
+		h.logger.Warn("stream: failed to load queue stats", zap.Error(err))
+	} else {
+		h.writeStreamEvent(w, streamEvent{name: "queues", data: queues})
+	}
+}
+
+// writeStreamEvent serializes ev.data as JSON and writes it in SSE wire
+// format. A JSON encoding failure is logged and the event is dropped rather
+// than writing malformed SSE data.
+func (h *Handler) writeStreamEvent(w http.ResponseWriter, ev streamEvent) {
+	data, err := json.Marshal(ev.data)
+	if err != nil {
+		h.logger.Error("stream: failed to encode event", zap.String("event", ev.name), zap.Error(err))
+		return
+	}
+
+	if ev.id != "" {
+		fmt.Fprintf(w, "id: %s\n", ev.id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, data)
+}
@@ -0,0 +1,101 @@
+// Package backend provides a pluggable authentication backend registry so
+// auth.Handler.Login can authenticate against the local database, an
+// htpasswd file, LDAP, or an OIDC/OAuth2 social provider without changing
+// handler code.
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrInvalidCredentials is returned by a Backend when the supplied
+// credentials do not authenticate, so Registry.Authenticate can move on to
+// the next backend in order.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Credentials carries whatever a backend needs to authenticate a caller.
+// Username/Password cover the local, htpasswd, and LDAP backends; Extra
+// carries backend-specific values such as an OIDC authorization code.
+type Credentials struct {
+	Username string
+	Password string
+	Extra    map[string]string
+}
+
+// Claims is the identity a Backend resolves a successful authentication to.
+type Claims struct {
+	Subject string
+	Email   string
+	Name    string
+}
+
+// Backend authenticates credentials against one identity source.
+type Backend interface {
+	Name() string
+	Authenticate(ctx context.Context, creds Credentials) (Claims, error)
+}
+
+// Registry holds the set of enabled backends and the order Login tries them in.
+type Registry struct {
+	mu       sync.RWMutex
+	backends map[string]Backend
+	order    []string
+}
+
+// NewRegistry creates an empty backend registry.
+func NewRegistry() *Registry {
+	return &Registry{backends: make(map[string]Backend)}
+}
+
+// Register adds b to the registry and appends it to the try-order unless
+// already present.
+func (r *Registry) Register(b Backend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := b.Name()
+	if _, exists := r.backends[name]; !exists {
+		r.order = append(r.order, name)
+	}
+	r.backends[name] = b
+}
+
+// SetOrder overrides the order backends are tried in. Names not yet
+// registered are ignored until Register is called for them.
+func (r *Registry) SetOrder(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append([]string(nil), names...)
+}
+
+// Get returns the named backend, if registered.
+func (r *Registry) Get(name string) (Backend, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.backends[name]
+	return b, ok
+}
+
+// Authenticate tries each registered backend in order and returns the claims
+// and backend name from the first one that succeeds.
+func (r *Registry) Authenticate(ctx context.Context, creds Credentials) (Claims, string, error) {
+	r.mu.RLock()
+	order := append([]string(nil), r.order...)
+	backends := r.backends
+	r.mu.RUnlock()
+
+	var lastErr error = ErrInvalidCredentials
+	for _, name := range order {
+		b, ok := backends[name]
+		if !ok {
+			continue
+		}
+		claims, err := b.Authenticate(ctx, creds)
+		if err == nil {
+			return claims, name, nil
+		}
+		lastErr = err
+	}
+	return Claims{}, "", lastErr
+}
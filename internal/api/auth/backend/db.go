@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+
+	"connex/internal/api/user"
+	"connex/pkg/logger"
+	"connex/pkg/password"
+
+	"go.uber.org/zap"
+)
+
+// DBBackend authenticates against the existing users table via
+// user.Service, verifying the stored password hash with pkg/password
+// (Argon2id, with a compatibility path for legacy bcrypt hashes). This is
+// the module's original (and still default) authentication path.
+type DBBackend struct {
+	UserService user.Service
+}
+
+// NewDBBackend wraps an existing user.Service as a Backend.
+func NewDBBackend(userService user.Service) *DBBackend {
+	return &DBBackend{UserService: userService}
+}
+
+func (b *DBBackend) Name() string { return "db" }
+
+func (b *DBBackend) Authenticate(ctx context.Context, creds Credentials) (Claims, error) {
+	found, err := b.UserService.GetByEmail(ctx, creds.Username)
+	if err != nil {
+		return Claims{}, ErrInvalidCredentials
+	}
+	ok, needsRehash := password.Verify(creds.Password, found.PasswordHash)
+	if !ok {
+		return Claims{}, ErrInvalidCredentials
+	}
+	if needsRehash {
+		b.rehash(ctx, found, creds.Password)
+	}
+	return Claims{
+		Subject: strconv.FormatInt(found.ID, 10),
+		Email:   found.Email,
+		Name:    found.Name,
+	}, nil
+}
+
+// rehash replaces found's stored hash with a fresh Argon2id one at current
+// parameters, transparently migrating legacy bcrypt or outdated-parameter
+// hashes forward on a successful login. A failure here doesn't fail the
+// login itself; the next successful login just retries the migration.
+func (b *DBBackend) rehash(ctx context.Context, found *user.User, plainPassword string) {
+	newHash, err := password.Hash(plainPassword)
+	if err != nil {
+		logger.GetGlobal().Warn("failed to rehash password", zap.Int64("user_id", found.ID), zap.Error(err))
+		return
+	}
+	found.PasswordHash = newHash
+	if _, err := b.UserService.Update(ctx, found); err != nil {
+		logger.GetGlobal().Warn("failed to persist rehashed password", zap.Int64("user_id", found.ID), zap.Error(err))
+	}
+}
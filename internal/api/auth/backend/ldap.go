@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPBackend authenticates with a simple bind against an LDAP/AD server:
+// it attempts to bind as the user directly using a configurable bind DN
+// template, which succeeds only if the supplied password is correct.
+type LDAPBackend struct {
+	Addr           string
+	BaseDN         string
+	BindDNTemplate string // e.g. "uid=%s,ou=people,dc=example,dc=com"
+	GroupFilter    string
+}
+
+// NewLDAPBackend configures an LDAP simple-bind backend.
+func NewLDAPBackend(addr, baseDN, bindDNTemplate, groupFilter string) *LDAPBackend {
+	return &LDAPBackend{
+		Addr:           addr,
+		BaseDN:         baseDN,
+		BindDNTemplate: bindDNTemplate,
+		GroupFilter:    groupFilter,
+	}
+}
+
+func (b *LDAPBackend) Name() string { return "ldap" }
+
+func (b *LDAPBackend) Authenticate(ctx context.Context, creds Credentials) (Claims, error) {
+	conn, err := ldap.DialURL(b.Addr)
+	if err != nil {
+		return Claims{}, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	defer conn.Close()
+
+	bindDN := fmt.Sprintf(b.BindDNTemplate, ldap.EscapeFilter(creds.Username))
+	if err := conn.Bind(bindDN, creds.Password); err != nil {
+		return Claims{}, ErrInvalidCredentials
+	}
+
+	return Claims{Subject: creds.Username, Email: creds.Username}, nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+
+	"connex/internal/api/middleware"
+	"connex/pkg/logger"
+	"connex/pkg/telemetry"
+
+	"go.uber.org/zap"
+)
+
+// AuthMode selects which credential(s) a route accepts.
+type AuthMode string
+
+const (
+	// ModeJWT accepts only the legacy Bearer JWT (see AuthMiddleware).
+	ModeJWT AuthMode = "jwt"
+	// ModeCert accepts only an mTLS client certificate (see CertAuthMiddleware).
+	ModeCert AuthMode = "cert"
+	// ModeEither accepts a client certificate if one was presented during
+	// the TLS handshake, falling back to a Bearer JWT otherwise.
+	ModeEither AuthMode = "either"
+)
+
+// CertIdentity is the identity CertAuthMiddleware extracts from a validated
+// client certificate.
+type CertIdentity struct {
+	CommonName string
+	SANs       []string
+	OU         []string
+}
+
+// CertIdentityResolver maps a validated certificate identity to a local
+// user ID, the same way auth.Handler.resolveLocalUser maps backend.Claims
+// for the JWT flows.
+type CertIdentityResolver interface {
+	ResolveCertIdentity(ctx context.Context, identity CertIdentity) (int64, error)
+}
+
+// CertIdentityResolverFunc adapts a plain function to a CertIdentityResolver.
+type CertIdentityResolverFunc func(ctx context.Context, identity CertIdentity) (int64, error)
+
+func (f CertIdentityResolverFunc) ResolveCertIdentity(ctx context.Context, identity CertIdentity) (int64, error) {
+	return f(ctx, identity)
+}
+
+// CertAuthMiddleware authenticates machine-to-machine callers by their TLS
+// client certificate instead of a Bearer JWT. The server's tls.Config must
+// request a client certificate (e.g. tls.VerifyClientCertIfGiven) so that
+// r.TLS.PeerCertificates is populated; this middleware then verifies the
+// leaf against caPool for client-auth usage itself, and adds the
+// application-level checks a bare TLS handshake can't: an OU allowlist,
+// live revocation (when revocation is non-nil), and resolving the identity
+// to a local user ID via resolver.
+func CertAuthMiddleware(caPool *x509.CertPool, ouAllowlist []string, resolver CertIdentityResolver, revocation RevocationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := logger.FromContext(r.Context())
+
+			if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+				http.Error(w, "client certificate required", http.StatusUnauthorized)
+				return
+			}
+			cert := r.TLS.PeerCertificates[0]
+
+			if _, err := cert.Verify(x509.VerifyOptions{
+				Roots:     caPool,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			}); err != nil {
+				log.Warn("client certificate failed verification", zap.Error(err))
+				http.Error(w, "invalid client certificate", http.StatusUnauthorized)
+				return
+			}
+
+			identity := certIdentity(cert)
+			if !ouAllowed(identity.OU, ouAllowlist) {
+				log.Warn("client certificate OU not in allowlist", zap.Strings("ou", identity.OU))
+				http.Error(w, "client certificate not authorized", http.StatusForbidden)
+				return
+			}
+
+			if revocation != nil {
+				var issuer *x509.Certificate
+				if len(r.TLS.PeerCertificates) > 1 {
+					issuer = r.TLS.PeerCertificates[1]
+				}
+				revoked, err := revocation.IsRevoked(cert, issuer)
+				if err != nil {
+					log.Error("revocation check failed", zap.Error(err))
+					http.Error(w, "could not verify certificate status", http.StatusServiceUnavailable)
+					return
+				}
+				if revoked {
+					log.Warn("client certificate revoked", zap.String("cn", identity.CommonName))
+					http.Error(w, "client certificate has been revoked", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			userID, err := resolver.ResolveCertIdentity(r.Context(), identity)
+			if err != nil {
+				log.Warn("client certificate identity not recognized", zap.String("cn", identity.CommonName), zap.Error(err))
+				http.Error(w, "client certificate not recognized", http.StatusUnauthorized)
+				return
+			}
+
+			telemetry.RecordSecurityEvent("cert_auth", identity.CommonName)
+
+			ctx := context.WithValue(r.Context(), middleware.UserIDKey, userID)
+			ctx = logger.NewContext(ctx, log.WithField("user_id", userID).WithField("auth_mode", string(ModeCert)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// EitherAuthMiddleware implements AuthMode = "either": a request that
+// presented a TLS client certificate is authenticated via certMiddleware,
+// and everything else falls back to jwtMiddleware.
+func EitherAuthMiddleware(jwtMiddleware, certMiddleware func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrappedJWT := jwtMiddleware(next)
+		wrappedCert := certMiddleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+				wrappedCert.ServeHTTP(w, r)
+				return
+			}
+			wrappedJWT.ServeHTTP(w, r)
+		})
+	}
+}
+
+// certIdentity extracts the identity fields CertAuthMiddleware cares about
+// from a validated client certificate.
+func certIdentity(cert *x509.Certificate) CertIdentity {
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return CertIdentity{
+		CommonName: cert.Subject.CommonName,
+		SANs:       sans,
+		OU:         cert.Subject.OrganizationalUnit,
+	}
+}
+
+// ouAllowed reports whether identityOU intersects allowlist; an empty
+// allowlist permits every OU.
+func ouAllowed(identityOU, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	for _, allowed := range allowlist {
+		for _, ou := range identityOU {
+			if ou == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
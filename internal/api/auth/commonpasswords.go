@@ -0,0 +1,30 @@
+package auth
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed commonpasswords.txt
+var commonPasswordsRaw string
+
+// commonPasswords is the lowercase set built from commonpasswords.txt
+// (one entry per line), checked by isCommonPassword.
+var commonPasswords = buildCommonPasswordSet(commonPasswordsRaw)
+
+func buildCommonPasswordSet(raw string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(raw, "\n") {
+		if line = strings.ToLower(strings.TrimSpace(line)); line != "" {
+			set[line] = struct{}{}
+		}
+	}
+	return set
+}
+
+// isCommonPassword reports whether password (case-insensitively) appears in
+// the embedded wordlist of breached/common passwords.
+func isCommonPassword(password string) bool {
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
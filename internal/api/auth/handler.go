@@ -1,26 +1,74 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
+	"connex/internal/api/auth/backend"
 	"connex/internal/api/middleware"
 	"connex/internal/api/user"
+	"connex/internal/cache"
 	"connex/pkg/jwt"
 	"connex/pkg/logger"
+	"connex/pkg/password"
+	"connex/pkg/security/breach"
+	"connex/pkg/telemetry"
 
+	"github.com/go-chi/chi/v5"
+	gojwt "github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Handler struct {
 	UserService user.Service
-	JWTSecret   string
+
+	// JWTSecret returns the current HS256 secret used to sign/verify the
+	// legacy Register/Login JWTs. NewHandler defaults this to a function
+	// that always returns the same string, but it can be backed by a
+	// config.SecretRef (see cmd/server/main.go) so a Vault- or AWS Secrets
+	// Manager-resolved secret can rotate without a restart.
+	JWTSecret func() string
+
+	// Backends drives Login: each configured backend is tried in order and
+	// the first to authenticate wins. NewHandler defaults this to the
+	// original DB backend (backend.DBBackend) so existing behavior is unchanged.
+	Backends *backend.Registry
+
+	// Tokens backs Logout and the revocation check AuthMiddleware performs
+	// on every request. NewHandler defaults to an in-memory store.
+	Tokens TokenStore
+
+	// Logins tracks failed login attempts per (ip, username) and locks the
+	// pair out past a threshold. NewHandler defaults to a RedisLoginLimiter
+	// that falls back to an in-process limiter when Redis is unavailable.
+	Logins LoginAttemptLimiter
+
+	// Sessions backs the `sid` claim embedded in issued JWTs: AuthMiddleware
+	// uses it to reject revoked or idle-expired sessions and to slide their
+	// expiration forward on each request. NewHandler defaults to an
+	// in-memory store.
+	Sessions SessionStore
+
+	// Breach, when non-nil, rejects registrations whose password has
+	// appeared in a known breach corpus (see pkg/security/breach).
+	// NewHandler leaves this nil (the check is opt-in); a failed check
+	// fails open (registration proceeds) and records a
+	// security_events_total{event_type="breach_check_failed"} metric.
+	Breach breach.BreachChecker
+
+	// AccessExpiration and RefreshExpiration bound the access/refresh token
+	// pair minted by issueTokenPair. NewHandler defaults these to 24h/7d;
+	// WithExpirations overrides them, e.g. from cfg.JWT.Expiration and
+	// cfg.JWT.RefreshExpiration.
+	AccessExpiration  time.Duration
+	RefreshExpiration time.Duration
 }
 
 type RegisterRequest struct {
@@ -35,12 +83,119 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token string     `json:"token"`
-	User  *user.User `json:"user"`
+	Token        string     `json:"token"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+	User         *user.User `json:"user"`
+}
+
+// RefreshRequest carries the refresh token presented to POST
+// /api/auth/refresh. It travels in the request body, not the Authorization
+// header, since that header is reserved for the (short-lived) access token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
+// NewHandler builds a Handler whose JWT secret is the fixed string
+// jwtSecret. Use WithJWTSecret instead to back it with a config.SecretRef
+// that can rotate at runtime.
 func NewHandler(userService user.Service, jwtSecret string) *Handler {
-	return &Handler{UserService: userService, JWTSecret: jwtSecret}
+	registry := backend.NewRegistry()
+	registry.Register(backend.NewDBBackend(userService))
+	h := &Handler{
+		UserService:       userService,
+		JWTSecret:         func() string { return jwtSecret },
+		Backends:          registry,
+		AccessExpiration:  24 * time.Hour,
+		RefreshExpiration: 7 * 24 * time.Hour,
+	}
+	h.Tokens = NewMemoryTokenStore(func(token string) (gojwt.MapClaims, error) {
+		return jwt.ParseJWT(token, h.JWTSecret())
+	})
+	h.Logins = NewRedisLoginLimiter(cache.Get(), nil)
+	h.Sessions = NewMemorySessionStore(30*time.Minute, 24*time.Hour, true)
+	return h
+}
+
+// WithTokens overrides the handler's token store, e.g. with a
+// NewRedisTokenStore so revocations survive restarts and are shared across
+// replicas.
+func (h *Handler) WithTokens(store TokenStore) *Handler {
+	h.Tokens = store
+	return h
+}
+
+// WithLogins overrides the handler's login attempt limiter, e.g. with one
+// built from NewRedisLoginLimiter(redisClient, crowdsecBouncer) so lockouts
+// feed CrowdSec LAPI as signals.
+func (h *Handler) WithLogins(limiter LoginAttemptLimiter) *Handler {
+	h.Logins = limiter
+	return h
+}
+
+// WithSessions overrides the handler's session store, e.g. with one built
+// from NewRedisSessionStore(redisClient, cfg.Session.TokenIdleTimeout,
+// cfg.Session.AbsoluteTokenLifetime, cfg.Session.EnableMultiLogin) so
+// sessions survive restarts and are shared across replicas.
+func (h *Handler) WithSessions(store SessionStore) *Handler {
+	h.Sessions = store
+	return h
+}
+
+// WithBreach overrides the handler's breach checker, e.g. with
+// breach.NewHIBPChecker(redisClient, cfg.Breach.Threshold, cfg.Breach.CacheTTL,
+// cfg.Breach.Timeout) to reject breached passwords at registration.
+func (h *Handler) WithBreach(checker breach.BreachChecker) *Handler {
+	h.Breach = checker
+	return h
+}
+
+// WithBackends overrides the handler's backend registry, e.g. to add
+// htpasswd, LDAP, or OIDC social backends alongside (or instead of) the
+// default DB backend. Order is whatever the registry was configured with.
+func (h *Handler) WithBackends(registry *backend.Registry) *Handler {
+	h.Backends = registry
+	return h
+}
+
+// WithExpirations overrides the handler's access/refresh token TTLs, e.g.
+// with cfg.JWT.Expiration and cfg.JWT.RefreshExpiration.
+func (h *Handler) WithExpirations(access, refresh time.Duration) *Handler {
+	h.AccessExpiration = access
+	h.RefreshExpiration = refresh
+	return h
+}
+
+// WithJWTSecret overrides the handler's secret accessor, e.g. with
+// ref.Load (a *config.SecretRef) so a Vault- or AWS Secrets Manager-backed
+// JWT_SECRET_REF rotates without a restart instead of the fixed string
+// NewHandler captured.
+func (h *Handler) WithJWTSecret(secret func() string) *Handler {
+	h.JWTSecret = secret
+	return h
+}
+
+// issueTokenPair starts a session for userID (recording deviceInfo, e.g. the
+// request's User-Agent) and mints an access+refresh JWT pair embedding its
+// sid, so AuthMiddleware can look the session up on every subsequent
+// request and POST /api/auth/refresh can rotate the pair without starting a
+// new session.
+func (h *Handler) issueTokenPair(ctx context.Context, userID int64, deviceInfo string) (access, refresh string, err error) {
+	var sid string
+	if h.Sessions != nil {
+		sid, err = h.Sessions.Create(ctx, userID, deviceInfo)
+		if err != nil {
+			return "", "", fmt.Errorf("could not create session: %w", err)
+		}
+	}
+	access, err = jwt.GenerateJWT(userID, h.JWTSecret(), sid, h.AccessExpiration)
+	if err != nil {
+		return "", "", err
+	}
+	refresh, err = jwt.GenerateRefreshJWT(userID, h.JWTSecret(), sid, h.RefreshExpiration)
+	if err != nil {
+		return "", "", err
+	}
+	return access, refresh, nil
 }
 
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
@@ -65,7 +220,17 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		middleware.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if h.Breach != nil {
+		breached, err := h.Breach.IsBreached(r.Context(), req.Password)
+		if err != nil {
+			logger.GetGlobal().Warn("breach check failed, failing open", zap.Error(err))
+			telemetry.RecordSecurityEvent("breach_check_failed", r.RemoteAddr)
+		} else if breached {
+			middleware.WriteError(w, http.StatusBadRequest, "password has appeared in a known data breach, please choose another")
+			return
+		}
+	}
+	hash, err := password.Hash(req.Password)
 	if err != nil {
 		middleware.WriteError(w, http.StatusInternalServerError, "failed to hash password")
 		return
@@ -73,7 +238,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	newUser := &user.User{
 		Name:         req.Name,
 		Email:        req.Email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 	}
 	created, err := h.UserService.Create(r.Context(), newUser)
 	if err != nil {
@@ -87,12 +252,12 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		zap.String("action", "register"),
 		zap.Time("timestamp", time.Now().UTC()),
 	)
-	token, err := jwt.GenerateJWT(created.ID, h.JWTSecret)
+	access, refresh, err := h.issueTokenPair(r.Context(), created.ID, r.UserAgent())
 	if err != nil {
 		middleware.WriteError(w, http.StatusInternalServerError, "failed to generate token")
 		return
 	}
-	resp := AuthResponse{Token: token, User: created}
+	resp := AuthResponse{Token: access, RefreshToken: refresh, User: created}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(resp)
@@ -112,32 +277,248 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		middleware.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	found, err := h.UserService.GetByEmail(r.Context(), req.Email)
+
+	if h.Logins != nil {
+		if locked, err := h.Logins.IsLocked(r.Context(), r.RemoteAddr, req.Email); err == nil && locked {
+			middleware.WriteError(w, http.StatusTooManyRequests,
+				"too many failed login attempts, account temporarily locked")
+			return
+		}
+	}
+
+	claims, backendName, err := h.Backends.Authenticate(r.Context(), backend.Credentials{
+		Username: req.Email,
+		Password: req.Password,
+	})
 	if err != nil {
+		if h.Logins != nil {
+			h.Logins.RecordFailure(r.Context(), r.RemoteAddr, req.Email)
+		}
 		middleware.WriteError(w, http.StatusUnauthorized, "invalid credentials")
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(found.PasswordHash), []byte(req.Password)); err != nil {
-		middleware.WriteError(w, http.StatusUnauthorized, "invalid credentials")
+
+	if h.Logins != nil {
+		h.Logins.RecordSuccess(r.Context(), r.RemoteAddr, req.Email)
+	}
+
+	found, err := h.resolveLocalUser(r.Context(), claims)
+	if err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "could not resolve user account")
 		return
 	}
+
 	logger.GetGlobal().Info("user login",
 		zap.Int64("user_id", found.ID),
 		zap.String("email", found.Email),
 		zap.String("ip", r.RemoteAddr),
 		zap.String("action", "login"),
+		zap.String("backend", backendName),
 		zap.Time("timestamp", time.Now().UTC()),
 	)
-	token, err := jwt.GenerateJWT(found.ID, h.JWTSecret)
+	access, refresh, err := h.issueTokenPair(r.Context(), found.ID, r.UserAgent())
 	if err != nil {
 		middleware.WriteError(w, http.StatusInternalServerError, "failed to generate token")
 		return
 	}
-	resp := AuthResponse{Token: token, User: found}
+	resp := AuthResponse{Token: access, RefreshToken: refresh, User: found}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// Logout revokes the jti of the bearer token presented in the Authorization
+// header, so it's rejected by AuthMiddleware even though it hasn't expired
+// yet, and revokes its session (if any) so AuthMiddleware's session check
+// also rejects any other still-valid token minted for that same session. If
+// the request body carries a refresh_token (see RefreshRequest), its jti is
+// denylisted alongside the access token's, so a client logging out can't
+// silently keep itself signed in via POST /api/auth/refresh.
+// See OAuthHandler.Logout for the OAuth2 access+refresh pair.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	header := r.Header.Get("Authorization")
+	if header == "" || !strings.HasPrefix(header, "Bearer ") {
+		middleware.WriteError(w, http.StatusBadRequest, "missing bearer token")
+		return
+	}
+	tokenStr := strings.TrimPrefix(header, "Bearer ")
+	claims, err := jwt.ParseJWT(tokenStr, h.JWTSecret())
+	if err != nil {
+		middleware.WriteError(w, http.StatusUnauthorized, "invalid or expired token")
+		return
+	}
+
+	if sid, _ := claims["sid"].(string); sid != "" && h.Sessions != nil {
+		h.Sessions.Revoke(r.Context(), sid)
+	}
+
+	if err := h.revokeClaimedJTI(r.Context(), claims); err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to revoke token")
+		return
+	}
+
+	var req RefreshRequest
+	if json.NewDecoder(r.Body).Decode(&req) == nil && req.RefreshToken != "" {
+		if refreshClaims, err := jwt.ParseJWT(req.RefreshToken, h.JWTSecret()); err == nil {
+			h.revokeClaimedJTI(r.Context(), refreshClaims)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeClaimedJTI denylists claims' jti (if any) until its claimed
+// expiration, shared by Logout's handling of the bearer access token and the
+// optional refresh token in its request body.
+func (h *Handler) revokeClaimedJTI(ctx context.Context, claims gojwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	if jti == "" || h.Tokens == nil {
+		return nil
+	}
+	exp := time.Now().Add(24 * time.Hour)
+	if expClaim, ok := claims["exp"].(float64); ok {
+		exp = time.Unix(int64(expClaim), 0)
+	}
+	return h.Tokens.Revoke(ctx, jti, exp)
+}
+
+// Refresh rotates a refresh token: it verifies req.RefreshToken is a
+// `typ: "refresh"` JWT that hasn't already been denylisted, denylists its
+// jti (so it can't be redeemed twice), and mints a fresh access+refresh pair
+// under the same session. A revoked jti presented here means the token has
+// already been rotated (or logged out) once before, which given normal
+// client behavior only happens on reuse by an attacker holding a stolen
+// refresh token — so that session is revoked outright rather than merely
+// rejecting the request.
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		middleware.WriteError(w, http.StatusBadRequest, "missing refresh token")
+		return
+	}
+
+	claims, err := jwt.ParseJWT(req.RefreshToken, h.JWTSecret())
+	if err != nil {
+		middleware.WriteError(w, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+	if typ, _ := claims["typ"].(string); typ != "refresh" {
+		middleware.WriteError(w, http.StatusUnauthorized, "not a refresh token")
+		return
+	}
+	jti, _ := claims["jti"].(string)
+	sid, _ := claims["sid"].(string)
+	userIDClaim, ok := claims["sub"].(float64)
+	if jti == "" || !ok {
+		middleware.WriteError(w, http.StatusUnauthorized, "invalid refresh token claims")
+		return
+	}
+	userID := int64(userIDClaim)
+
+	if h.Tokens != nil {
+		revoked, err := h.Tokens.IsRevoked(r.Context(), jti)
+		if err != nil {
+			middleware.WriteError(w, http.StatusInternalServerError, "failed to check token status")
+			return
+		}
+		if revoked {
+			if sid != "" && h.Sessions != nil {
+				h.Sessions.Revoke(r.Context(), sid)
+			}
+			telemetry.RecordSecurityEvent("refresh_token_reuse", r.RemoteAddr)
+			middleware.WriteError(w, http.StatusUnauthorized, "refresh token has already been used")
+			return
+		}
+		if err := h.revokeClaimedJTI(r.Context(), claims); err != nil {
+			middleware.WriteError(w, http.StatusInternalServerError, "failed to rotate token")
+			return
+		}
+	}
+
+	if sid != "" && h.Sessions != nil {
+		if err := h.Sessions.Touch(r.Context(), sid); err != nil {
+			middleware.WriteError(w, http.StatusUnauthorized, "session expired or revoked")
+			return
+		}
+	}
+
+	access, err := jwt.GenerateJWT(userID, h.JWTSecret(), sid, h.AccessExpiration)
+	if err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	refresh, err := jwt.GenerateRefreshJWT(userID, h.JWTSecret(), sid, h.RefreshExpiration)
+	if err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	writeJSON(w, http.StatusOK, AuthResponse{Token: access, RefreshToken: refresh})
+}
+
+// LogoutAll revokes every session belonging to the bearer token's user,
+// signing it out of every device, not just the one that called it.
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	header := r.Header.Get("Authorization")
+	if header == "" || !strings.HasPrefix(header, "Bearer ") {
+		middleware.WriteError(w, http.StatusBadRequest, "missing bearer token")
+		return
+	}
+	tokenStr := strings.TrimPrefix(header, "Bearer ")
+	claims, err := jwt.ParseJWT(tokenStr, h.JWTSecret())
+	if err != nil {
+		middleware.WriteError(w, http.StatusUnauthorized, "invalid or expired token")
+		return
+	}
+	userID, ok := claims["sub"].(float64)
+	if !ok {
+		middleware.WriteError(w, http.StatusUnauthorized, "invalid token claims")
+		return
+	}
+
+	if h.Sessions != nil {
+		if err := h.Sessions.RevokeAllForUser(r.Context(), int64(userID)); err != nil {
+			middleware.WriteError(w, http.StatusInternalServerError, "failed to revoke sessions")
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserSessions revokes every session belonging to the user identified
+// by the {id} URL parameter, the same effect as LogoutAll but targeted at an
+// arbitrary user rather than the bearer token's own account. Intended to be
+// mounted behind an administrative authorization layer; this repo has none
+// yet, so for now it's reachable by any authenticated caller like the rest
+// of /api/users.
+func (h *Handler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if h.Sessions != nil {
+		if err := h.Sessions.RevokeAllForUser(r.Context(), id); err != nil {
+			middleware.WriteError(w, http.StatusInternalServerError, "failed to revoke sessions")
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolveLocalUser maps a backend's claims to a local user row, auto-
+// provisioning one on first login from a non-DB backend (htpasswd, LDAP,
+// OIDC) by linking on email.
+func (h *Handler) resolveLocalUser(ctx context.Context, claims backend.Claims) (*user.User, error) {
+	found, err := h.UserService.GetByEmail(ctx, claims.Email)
+	if err == nil {
+		return found, nil
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.Email
+	}
+	return h.UserService.Create(ctx, &user.User{Name: name, Email: claims.Email})
+}
+
 // validatePassword enforces strong password policy
 func validatePassword(password string) error {
 	if len(password) < 12 {
@@ -165,17 +546,6 @@ func validatePassword(password string) error {
 	return nil
 }
 
-// isCommonPassword checks against a small list of common passwords (expand in production)
-func isCommonPassword(password string) bool {
-	common := []string{"password", "123456", "qwerty", "letmein", "admin", "welcome", "iloveyou"}
-	for _, p := range common {
-		if strings.EqualFold(password, p) {
-			return true
-		}
-	}
-	return false
-}
-
 // validateEmail checks for a valid email format (basic RFC 5322)
 func validateEmail(email string) error {
 	if len(email) > 254 {
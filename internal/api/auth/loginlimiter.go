@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"connex/pkg/security/crowdsec"
+	"connex/pkg/telemetry"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// loginWindow is the sliding window RecordFailure counts attempts over, and
+// loginThreshold is how many failures within that window trigger a lockout.
+// Failures are counted per (ip, username) pair, so a lockout never affects
+// an IP's other users or an account's other IPs. Failures spread across many
+// accounts from one IP, or across many IPs against one account, are counted
+// separately per pair and are not combined.
+const (
+	loginWindow    = 15 * time.Minute
+	loginThreshold = 5
+)
+
+// lockoutSteps is how long successive lockouts last: 1m, 5m, 30m, then 24h
+// for every offense after that.
+var lockoutSteps = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 24 * time.Hour}
+
+func lockoutDuration(step int) time.Duration {
+	if step <= 0 {
+		step = 1
+	}
+	if step > len(lockoutSteps) {
+		step = len(lockoutSteps)
+	}
+	return lockoutSteps[step-1]
+}
+
+// LoginAttemptLimiter tracks failed login attempts keyed by (ip, username)
+// and decides when repeated failures should lock the pair out.
+type LoginAttemptLimiter interface {
+	// RecordFailure records a failed attempt, returning true if it pushed
+	// (ip, username) past loginThreshold into a new lockout.
+	RecordFailure(ctx context.Context, ip, username string) (locked bool, err error)
+
+	// RecordSuccess clears (ip, username)'s failure history and lockout.
+	RecordSuccess(ctx context.Context, ip, username string) error
+
+	// IsLocked reports whether (ip, username) is currently locked out.
+	IsLocked(ctx context.Context, ip, username string) (bool, error)
+}
+
+func attemptsKey(ip, username string) string {
+	return fmt.Sprintf("login_attempts:%s:%s", ip, username)
+}
+func lockoutKey(ip, username string) string { return fmt.Sprintf("login_lockout:%s:%s", ip, username) }
+func lockoutStepKey(ip, username string) string {
+	return fmt.Sprintf("login_lockout_step:%s:%s", ip, username)
+}
+
+// RedisLoginLimiter is a sliding-window login attempt limiter shared across
+// every replica via Redis, so a lockout on one instance is honored by all
+// of them. It falls back to an in-process MemoryLoginLimiter whenever Redis
+// is unreachable (or nil, e.g. in tests), trading cross-instance
+// coordination for availability rather than failing open entirely.
+type RedisLoginLimiter struct {
+	redis    *redis.Client
+	fallback *MemoryLoginLimiter
+	bouncer  *crowdsec.Bouncer
+}
+
+// NewRedisLoginLimiter builds a RedisLoginLimiter. client may be nil (the
+// limiter then always uses its in-process fallback); bouncer may be nil to
+// skip feeding lockouts to CrowdSec LAPI as signals.
+func NewRedisLoginLimiter(client *redis.Client, bouncer *crowdsec.Bouncer) *RedisLoginLimiter {
+	fallback := NewMemoryLoginLimiter()
+	go fallback.runJanitor(5 * time.Minute)
+	return &RedisLoginLimiter{redis: client, fallback: fallback, bouncer: bouncer}
+}
+
+func (l *RedisLoginLimiter) RecordFailure(ctx context.Context, ip, username string) (bool, error) {
+	if l.redis == nil {
+		return l.fallback.RecordFailure(ctx, ip, username)
+	}
+
+	now := time.Now()
+	key := attemptsKey(ip, username)
+	pipe := l.redis.TxPipeline()
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.UnixNano()), Member: now.UnixNano()})
+	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", now.Add(-loginWindow).UnixNano()))
+	pipe.Expire(ctx, key, loginWindow)
+	card := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return l.fallback.RecordFailure(ctx, ip, username)
+	}
+	telemetry.RecordLoginAttempt("failure")
+
+	if card.Val() < int64(loginThreshold) {
+		return false, nil
+	}
+
+	step, err := l.redis.Incr(ctx, lockoutStepKey(ip, username)).Result()
+	if err != nil {
+		return false, fmt.Errorf("loginlimiter: increment lockout step: %w", err)
+	}
+	l.redis.Expire(ctx, lockoutStepKey(ip, username), 24*time.Hour)
+
+	duration := lockoutDuration(int(step))
+	if err := l.redis.Set(ctx, lockoutKey(ip, username), 1, duration).Err(); err != nil {
+		return false, fmt.Errorf("loginlimiter: set lockout: %w", err)
+	}
+
+	telemetry.RecordLoginAttempt("locked")
+	telemetry.RecordSecurityEvent("login_lockout", ip)
+	if l.bouncer != nil {
+		l.bouncer.ReportEvent("connex/login-lockout",
+			fmt.Sprintf("%s locked out after %d failed login attempts", username, card.Val()), ip)
+	}
+	return true, nil
+}
+
+func (l *RedisLoginLimiter) RecordSuccess(ctx context.Context, ip, username string) error {
+	if l.redis == nil {
+		return l.fallback.RecordSuccess(ctx, ip, username)
+	}
+	telemetry.RecordLoginAttempt("success")
+	if err := l.redis.Del(ctx, attemptsKey(ip, username), lockoutKey(ip, username), lockoutStepKey(ip, username)).Err(); err != nil {
+		return fmt.Errorf("loginlimiter: clear attempts: %w", err)
+	}
+	return nil
+}
+
+func (l *RedisLoginLimiter) IsLocked(ctx context.Context, ip, username string) (bool, error) {
+	if l.redis == nil {
+		return l.fallback.IsLocked(ctx, ip, username)
+	}
+	count, err := l.redis.Exists(ctx, lockoutKey(ip, username)).Result()
+	if err != nil {
+		return l.fallback.IsLocked(ctx, ip, username)
+	}
+	return count > 0, nil
+}
+
+// memoryLoginEntry is one (ip, username) pair's attempt history in a
+// MemoryLoginLimiter.
+type memoryLoginEntry struct {
+	key          string
+	attempts     []time.Time
+	lockoutUntil time.Time
+	lockoutStep  int
+}
+
+// MemoryLoginLimiter is the bounded, in-process fallback RedisLoginLimiter
+// uses when Redis is unreachable. It caps itself at maxEntries (ip,
+// username) pairs, evicting the least recently touched entry, so a
+// sustained attack can't grow it unbounded the way the old
+// FailedLoginTracker's plain map could.
+type MemoryLoginLimiter struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // front = most recently touched
+}
+
+// NewMemoryLoginLimiter returns an empty, ready-to-use MemoryLoginLimiter.
+func NewMemoryLoginLimiter() *MemoryLoginLimiter {
+	return &MemoryLoginLimiter{
+		maxEntries: 10000,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (m *MemoryLoginLimiter) touch(key string) *memoryLoginEntry {
+	if el, ok := m.entries[key]; ok {
+		m.order.MoveToFront(el)
+		return el.Value.(*memoryLoginEntry)
+	}
+	entry := &memoryLoginEntry{key: key}
+	el := m.order.PushFront(entry)
+	m.entries[key] = el
+	for len(m.entries) > m.maxEntries {
+		oldest := m.order.Back()
+		if oldest == nil {
+			break
+		}
+		delete(m.entries, oldest.Value.(*memoryLoginEntry).key)
+		m.order.Remove(oldest)
+	}
+	return entry
+}
+
+func (m *MemoryLoginLimiter) RecordFailure(_ context.Context, ip, username string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.touch(ip + ":" + username)
+	now := time.Now()
+	cutoff := now.Add(-loginWindow)
+	fresh := entry.attempts[:0]
+	for _, t := range entry.attempts {
+		if t.After(cutoff) {
+			fresh = append(fresh, t)
+		}
+	}
+	entry.attempts = append(fresh, now)
+
+	if len(entry.attempts) < loginThreshold {
+		return false, nil
+	}
+	entry.lockoutStep++
+	entry.lockoutUntil = now.Add(lockoutDuration(entry.lockoutStep))
+	return true, nil
+}
+
+func (m *MemoryLoginLimiter) RecordSuccess(_ context.Context, ip, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := ip + ":" + username
+	if el, ok := m.entries[key]; ok {
+		m.order.Remove(el)
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *MemoryLoginLimiter) IsLocked(_ context.Context, ip, username string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[ip+":"+username]
+	if !ok {
+		return false, nil
+	}
+	return time.Now().Before(el.Value.(*memoryLoginEntry).lockoutUntil), nil
+}
+
+// runJanitor periodically evicts entries whose attempt history and lockout
+// have both expired, so memory doesn't fill up with stale (ip, username)
+// pairs between failed-login bursts. Intended to run for the lifetime of
+// the process; see NewRedisLoginLimiter.
+func (m *MemoryLoginLimiter) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.sweep()
+	}
+}
+
+func (m *MemoryLoginLimiter) sweep() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-loginWindow)
+	for key, el := range m.entries {
+		entry := el.Value.(*memoryLoginEntry)
+		hasRecentAttempt := false
+		for _, t := range entry.attempts {
+			if t.After(cutoff) {
+				hasRecentAttempt = true
+				break
+			}
+		}
+		if !hasRecentAttempt && now.After(entry.lockoutUntil) {
+			m.order.Remove(el)
+			delete(m.entries, key)
+		}
+	}
+}
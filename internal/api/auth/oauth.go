@@ -0,0 +1,630 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connex/internal/api/middleware"
+	"connex/internal/api/user"
+	"connex/pkg/jwt"
+	"connex/pkg/password"
+
+	"github.com/go-chi/chi/v5"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantPassword          = "password"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+
+	defaultAccessTokenTTL = 15 * time.Minute
+	authCodeTTL           = 2 * time.Minute
+)
+
+// clientValidator validates createClientRequest bodies for
+// CreateClient/UpdateClient.
+var clientValidator = middleware.NewValidator()
+
+// authCode is an in-memory, short-lived authorization code issued by
+// /oauth/authorize and redeemed by /oauth/token with grant_type=authorization_code.
+type authCode struct {
+	ClientID    string
+	UserID      int64
+	RedirectURI string
+	Scope       string
+	ExpiresAt   time.Time
+}
+
+// OAuthHandler implements the OAuth2 authorization server: the token,
+// authorize, and JWKS endpoints, plus OAuth client CRUD.
+type OAuthHandler struct {
+	UserService user.Service
+	Clients     ClientStore
+	Keyring     *jwt.Keyring
+	Refresh     RefreshTokenStore
+	Issuer      string
+	Audience    string
+	AccessTTL   time.Duration
+
+	// Tokens backs /oauth/revoke, /oauth/introspect, and /oauth/logout.
+	// NewOAuthHandler defaults it to an in-memory store.
+	Tokens TokenStore
+
+	mu    sync.Mutex
+	codes map[string]*authCode
+}
+
+// NewOAuthHandler wires the OAuth2 server on top of the existing user service.
+func NewOAuthHandler(userService user.Service, clients ClientStore, keyring *jwt.Keyring, issuer, audience string) *OAuthHandler {
+	h := &OAuthHandler{
+		UserService: userService,
+		Clients:     clients,
+		Keyring:     keyring,
+		Refresh:     NewMemoryRefreshTokenStore(),
+		Issuer:      issuer,
+		Audience:    audience,
+		AccessTTL:   defaultAccessTokenTTL,
+		codes:       make(map[string]*authCode),
+	}
+	h.Tokens = NewMemoryTokenStore(func(token string) (gojwt.MapClaims, error) {
+		return jwt.ParseSignedJWT(h.Keyring, token)
+	})
+	return h
+}
+
+// WithTokens overrides the handler's token store, e.g. with a
+// NewRedisTokenStore so revocations survive restarts and are shared across
+// replicas.
+func (h *OAuthHandler) WithTokens(store TokenStore) *OAuthHandler {
+	h.Tokens = store
+	return h
+}
+
+// WithRefresh overrides the handler's refresh token store, e.g. with a
+// NewRedisRefreshTokenStore so rotation/reuse-detection state survives
+// restarts and is shared across replicas.
+func (h *OAuthHandler) WithRefresh(store RefreshTokenStore) *OAuthHandler {
+	h.Refresh = store
+	return h
+}
+
+// RegisterRoutes registers the OAuth2 endpoints and client-management API.
+// /oauth/token, /revoke, /introspect, and /logout authenticate the caller
+// themselves (client Basic auth or a bearer token), per the OAuth2/RFC 7009/
+// RFC 7662 spec, and so are mounted unauthenticated at the router level.
+//
+// resourceOwnerAuth, by contrast, protects two endpoints that have no such
+// built-in check: GET /oauth/authorize needs a Bearer-authenticated resource
+// owner already in context (see Authorize's middleware.UserIDFromContext
+// call) to approve the grant, and /oauth/clients is a plain CRUD API with no
+// authentication of its own. Callers should pass the same middleware
+// protecting the rest of the authenticated API; resourceOwnerAuth may be nil
+// only for tests driving individual handler methods directly, which leaves
+// both open.
+func (h *OAuthHandler) RegisterRoutes(r chi.Router, resourceOwnerAuth func(http.Handler) http.Handler) {
+	r.Post("/oauth/token", h.Token)
+	r.Post("/oauth/revoke", h.Revoke)
+	r.Post("/oauth/introspect", h.Introspect)
+	r.Post("/oauth/logout", h.Logout)
+	r.Get("/.well-known/jwks.json", h.JWKS)
+
+	r.Group(func(r chi.Router) {
+		if resourceOwnerAuth != nil {
+			r.Use(resourceOwnerAuth)
+		}
+		r.Get("/oauth/authorize", h.Authorize)
+		r.Route("/oauth/clients", func(r chi.Router) {
+			r.Post("/", h.CreateClient)
+			r.Get("/", h.ListClients)
+			r.Get("/{id}", h.GetClient)
+			r.Put("/{id}", h.UpdateClient)
+			r.Delete("/{id}", h.DeleteClient)
+		})
+	})
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Token implements the /oauth/token endpoint for all four supported grant types.
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "could not parse form body")
+		return
+	}
+
+	grantType := r.FormValue("grant_type")
+	switch grantType {
+	case GrantPassword:
+		h.handlePasswordGrant(w, r)
+	case GrantClientCredentials:
+		h.handleClientCredentialsGrant(w, r)
+	case GrantAuthorizationCode:
+		h.handleAuthorizationCodeGrant(w, r)
+	case GrantRefreshToken:
+		h.handleRefreshTokenGrant(w, r)
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be one of password, client_credentials, authorization_code, refresh_token")
+	}
+}
+
+func (h *OAuthHandler) handlePasswordGrant(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.authenticateClient(w, r, GrantPassword)
+	if !ok {
+		return
+	}
+
+	username := r.FormValue("username")
+	plainPassword := r.FormValue("password")
+	found, err := h.UserService.GetByEmail(r.Context(), username)
+	if err != nil {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_grant", "invalid username or password")
+		return
+	}
+	ok, needsRehash := password.Verify(plainPassword, found.PasswordHash)
+	if !ok {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_grant", "invalid username or password")
+		return
+	}
+	if needsRehash {
+		if newHash, err := password.Hash(plainPassword); err == nil {
+			found.PasswordHash = newHash
+			h.UserService.Update(r.Context(), found)
+		}
+	}
+
+	h.issueTokenPair(w, r, client, strconv.FormatInt(found.ID, 10), strings.Join(client.Scopes, " "))
+}
+
+func (h *OAuthHandler) handleClientCredentialsGrant(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.authenticateClient(w, r, GrantClientCredentials)
+	if !ok {
+		return
+	}
+	h.issueAccessToken(w, client.ClientID, strings.Join(client.Scopes, " "))
+}
+
+func (h *OAuthHandler) handleAuthorizationCodeGrant(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.authenticateClient(w, r, GrantAuthorizationCode)
+	if !ok {
+		return
+	}
+
+	code := r.FormValue("code")
+	redirectURI := r.FormValue("redirect_uri")
+
+	h.mu.Lock()
+	entry, exists := h.codes[code]
+	if exists {
+		delete(h.codes, code)
+	}
+	h.mu.Unlock()
+
+	if !exists || time.Now().After(entry.ExpiresAt) || entry.ClientID != client.ClientID || entry.RedirectURI != redirectURI {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "authorization code is invalid, expired, or was issued to a different client")
+		return
+	}
+
+	h.issueTokenPair(w, r, client, strconv.FormatInt(entry.UserID, 10), entry.Scope)
+}
+
+func (h *OAuthHandler) handleRefreshTokenGrant(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.authenticateClient(w, r, GrantRefreshToken)
+	if !ok {
+		return
+	}
+
+	refreshToken := r.FormValue("refresh_token")
+	newRefresh, userID, err := h.Refresh.Rotate(r.Context(), refreshToken)
+	if err != nil {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_grant", "refresh token is invalid or has already been used")
+		return
+	}
+
+	access, _, err := jwt.GenerateSignedJWT(h.Keyring, jwt.IssueOptions{
+		Issuer:      h.Issuer,
+		Audience:    h.Audience,
+		Subject:     strconv.FormatInt(userID, 10),
+		Expiration:  h.AccessTTL,
+		ExtraClaims: map[string]interface{}{"client_id": client.ClientID, "scope": strings.Join(client.Scopes, " ")},
+	})
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue access token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.AccessTTL.Seconds()),
+		RefreshToken: newRefresh,
+		Scope:        strings.Join(client.Scopes, " "),
+	})
+}
+
+// issueTokenPair issues an access token plus a fresh refresh token for subject.
+func (h *OAuthHandler) issueTokenPair(w http.ResponseWriter, r *http.Request, client *OAuthClient, subject, scope string) {
+	access, _, err := jwt.GenerateSignedJWT(h.Keyring, jwt.IssueOptions{
+		Issuer:      h.Issuer,
+		Audience:    h.Audience,
+		Subject:     subject,
+		Expiration:  h.AccessTTL,
+		ExtraClaims: map[string]interface{}{"client_id": client.ClientID, "scope": scope},
+	})
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue access token")
+		return
+	}
+
+	userID, _ := strconv.ParseInt(subject, 10, 64)
+	refresh, err := h.Refresh.Issue(r.Context(), userID)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue refresh token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.AccessTTL.Seconds()),
+		RefreshToken: refresh,
+		Scope:        scope,
+	})
+}
+
+func (h *OAuthHandler) issueAccessToken(w http.ResponseWriter, subject, scope string) {
+	access, _, err := jwt.GenerateSignedJWT(h.Keyring, jwt.IssueOptions{
+		Issuer:      h.Issuer,
+		Audience:    h.Audience,
+		Subject:     subject,
+		Expiration:  h.AccessTTL,
+		ExtraClaims: map[string]interface{}{"client_id": subject, "scope": scope},
+	})
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue access token")
+		return
+	}
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken: access,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.AccessTTL.Seconds()),
+		Scope:       scope,
+	})
+}
+
+// authenticateClient validates client credentials from Basic auth or form
+// parameters and checks the client is allowed to use grantType.
+func (h *OAuthHandler) authenticateClient(w http.ResponseWriter, r *http.Request, grantType string) (*OAuthClient, bool) {
+	client, ok := h.authenticateClientBasic(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !client.AllowsGrant(grantType) {
+		writeOAuthError(w, http.StatusBadRequest, "unauthorized_client", "client is not allowed to use this grant type")
+		return nil, false
+	}
+	return client, true
+}
+
+// authenticateClientBasic validates client credentials from Basic auth or
+// form parameters without checking grant-type eligibility, for endpoints
+// like /oauth/revoke and /oauth/introspect that aren't tied to one grant.
+func (h *OAuthHandler) authenticateClientBasic(w http.ResponseWriter, r *http.Request) (*OAuthClient, bool) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.FormValue("client_id")
+		clientSecret = r.FormValue("client_secret")
+	}
+	if clientID == "" {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication required")
+		return nil, false
+	}
+
+	client, err := h.Clients.Get(r.Context(), clientID)
+	if err != nil {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "unknown client")
+		return nil, false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(client.ClientSecretHash), []byte(clientSecret)) != nil {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "invalid client credentials")
+		return nil, false
+	}
+	return client, true
+}
+
+// Revoke implements RFC 7009: it revokes the access token's jti (via
+// Tokens.Revoke) or, if token isn't a JWT, treats it as an opaque refresh
+// token and revokes its whole family. Per the RFC, an invalid or unknown
+// token still gets a 200 rather than leaking which tokens exist.
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "could not parse form body")
+		return
+	}
+	if _, ok := h.authenticateClientBasic(w, r); !ok {
+		return
+	}
+
+	token := r.FormValue("token")
+	if token == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	claims, err := jwt.ParseSignedJWT(h.Keyring, token)
+	if err != nil {
+		_ = h.Refresh.RevokeFamily(r.Context(), token)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if jti, ok := claims["jti"].(string); ok && jti != "" {
+		exp := time.Now().Add(h.AccessTTL)
+		if expClaim, ok := claims["exp"].(float64); ok {
+			exp = time.Unix(int64(expClaim), 0)
+		}
+		h.Tokens.Revoke(r.Context(), jti, exp)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// introspectResponse is the RFC 7662 response shape.
+type introspectResponse struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Exp      int64  `json:"exp,omitempty"`
+	Iat      int64  `json:"iat,omitempty"`
+	Sub      string `json:"sub,omitempty"`
+	Aud      string `json:"aud,omitempty"`
+	Iss      string `json:"iss,omitempty"`
+}
+
+// Introspect implements RFC 7662. It's a protected resource: the caller must
+// authenticate as a registered OAuth client.
+func (h *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "could not parse form body")
+		return
+	}
+	if _, ok := h.authenticateClientBasic(w, r); !ok {
+		return
+	}
+
+	token := r.FormValue("token")
+	active, claims, err := h.Tokens.Introspect(r.Context(), token)
+	if err != nil || !active {
+		writeJSON(w, http.StatusOK, introspectResponse{Active: false})
+		return
+	}
+
+	resp := introspectResponse{Active: true}
+	if sub, ok := claims["sub"].(string); ok {
+		resp.Sub = sub
+		resp.Username = sub
+	}
+	if aud, ok := claims["aud"].(string); ok {
+		resp.Aud = aud
+	}
+	if iss, ok := claims["iss"].(string); ok {
+		resp.Iss = iss
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		resp.Scope = scope
+	}
+	if clientID, ok := claims["client_id"].(string); ok {
+		resp.ClientID = clientID
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		resp.Exp = int64(exp)
+	}
+	if iat, ok := claims["iat"].(float64); ok {
+		resp.Iat = int64(iat)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Logout revokes the presented access token's jti and, if refresh_token is
+// also supplied, its refresh token family, so both halves of the pair
+// issued by issueTokenPair stop working immediately.
+func (h *OAuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "could not parse form body")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token != "" {
+		if claims, err := jwt.ParseSignedJWT(h.Keyring, token); err == nil {
+			if jti, ok := claims["jti"].(string); ok && jti != "" {
+				exp := time.Now().Add(h.AccessTTL)
+				if expClaim, ok := claims["exp"].(float64); ok {
+					exp = time.Unix(int64(expClaim), 0)
+				}
+				h.Tokens.Revoke(r.Context(), jti, exp)
+			}
+		}
+	}
+
+	if refreshToken := r.FormValue("refresh_token"); refreshToken != "" {
+		_ = h.Refresh.RevokeFamily(r.Context(), refreshToken)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Authorize implements /oauth/authorize for the authorization_code flow. The
+// caller must already be authenticated (Bearer token) as the resource owner
+// granting access; on success it redirects to redirect_uri with a fresh code.
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	clientID := r.URL.Query().Get("client_id")
+	redirectURI := r.URL.Query().Get("redirect_uri")
+	state := r.URL.Query().Get("state")
+	scope := r.URL.Query().Get("scope")
+
+	client, err := h.Clients.Get(r.Context(), clientID)
+	if err != nil || !client.AllowsRedirect(redirectURI) {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "unknown client or redirect_uri")
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeOAuthError(w, http.StatusUnauthorized, "login_required", "resource owner must be authenticated")
+		return
+	}
+
+	code := uuid.NewString()
+	h.mu.Lock()
+	h.codes[code] = &authCode{
+		ClientID:    clientID,
+		UserID:      userID,
+		RedirectURI: redirectURI,
+		Scope:       scope,
+		ExpiresAt:   time.Now().Add(authCodeTTL),
+	}
+	h.mu.Unlock()
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "redirect_uri is not a valid URL")
+		return
+	}
+	q := redirect.Query()
+	q.Set("code", code)
+	if state != "" {
+		q.Set("state", state)
+	}
+	redirect.RawQuery = q.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// JWKS publishes the active and retired public keys so resource servers can
+// verify tokens independently of this one, with `kid`-based rotation.
+func (h *OAuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.Keyring.JWKS())
+}
+
+// --- OAuth client CRUD ---
+
+type createClientRequest struct {
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1"`
+	Scopes       []string `json:"scopes"`
+}
+
+type createClientResponse struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+func (h *OAuthHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	var req createClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if errs := clientValidator.Struct(req); errs != nil {
+		middleware.WriteValidationErrors(w, errs)
+		return
+	}
+
+	clientID := uuid.NewString()
+	clientSecret := uuid.NewString()
+	hash, err := bcrypt.GenerateFromPassword([]byte(clientSecret), bcrypt.DefaultCost)
+	if err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to hash client secret")
+		return
+	}
+
+	client := &OAuthClient{
+		ClientID:         clientID,
+		ClientSecretHash: string(hash),
+		GrantTypes:       req.GrantTypes,
+		RedirectURIs:     req.RedirectURIs,
+		Scopes:           req.Scopes,
+	}
+	if _, err := h.Clients.Create(r.Context(), client); err != nil {
+		middleware.ErrorMapper(w, err, "could not create client")
+		return
+	}
+
+	// The plaintext secret is only ever returned once, at creation time.
+	writeJSON(w, http.StatusCreated, createClientResponse{ClientID: clientID, ClientSecret: clientSecret})
+}
+
+func (h *OAuthHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := h.Clients.List(r.Context())
+	if err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "could not list clients")
+		return
+	}
+	writeJSON(w, http.StatusOK, clients)
+}
+
+func (h *OAuthHandler) GetClient(w http.ResponseWriter, r *http.Request) {
+	client, err := h.Clients.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.ErrorMapper(w, err, "could not get client")
+		return
+	}
+	writeJSON(w, http.StatusOK, client)
+}
+
+func (h *OAuthHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req createClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if errs := clientValidator.Struct(req); errs != nil {
+		middleware.WriteValidationErrors(w, errs)
+		return
+	}
+	client := &OAuthClient{ClientID: id, GrantTypes: req.GrantTypes, RedirectURIs: req.RedirectURIs, Scopes: req.Scopes}
+	updated, err := h.Clients.Update(r.Context(), client)
+	if err != nil {
+		middleware.ErrorMapper(w, err, "could not update client")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *OAuthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	if err := h.Clients.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		middleware.WriteError(w, http.StatusInternalServerError, "could not delete client")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeOAuthError writes an RFC 6749 section 5.2 error response.
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"connex/internal/db"
+
+	"github.com/lib/pq"
+)
+
+// OAuthClient represents a registered OAuth2 client application.
+type OAuthClient struct {
+	ClientID         string         `db:"client_id" json:"client_id"`
+	ClientSecretHash string         `db:"client_secret_hash" json:"-"`
+	GrantTypes       pq.StringArray `db:"grant_types" json:"grant_types"`
+	RedirectURIs     pq.StringArray `db:"redirect_uris" json:"redirect_uris"`
+	Scopes           pq.StringArray `db:"scopes" json:"scopes"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+}
+
+// AllowsGrant reports whether the client is permitted to use grantType.
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	for _, g := range c.GrantTypes {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRedirect reports whether redirectURI is registered for the client.
+func (c *OAuthClient) AllowsRedirect(redirectURI string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientStore persists OAuth2 clients.
+type ClientStore interface {
+	Create(ctx context.Context, c *OAuthClient) (*OAuthClient, error)
+	Get(ctx context.Context, clientID string) (*OAuthClient, error)
+	List(ctx context.Context) ([]*OAuthClient, error)
+	Update(ctx context.Context, c *OAuthClient) (*OAuthClient, error)
+	Delete(ctx context.Context, clientID string) error
+}
+
+// SQLClientStore resolves a *sqlx.DB pool per call via DB.Resolve, so it can
+// serve multiple tenants/shards without depending on package-level global
+// state, mirroring user.UserService.
+type SQLClientStore struct {
+	DB *db.Manager
+}
+
+// NewClientStore builds a SQLClientStore backed by manager. Callers that only
+// need a single pool can pass a *db.Manager with one pool registered under
+// db.DefaultTenant.
+func NewClientStore(manager *db.Manager) *SQLClientStore {
+	return &SQLClientStore{DB: manager}
+}
+
+func (s *SQLClientStore) Create(ctx context.Context, c *OAuthClient) (*OAuthClient, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `INSERT INTO oauth_clients (client_id, client_secret_hash, grant_types, redirect_uris, scopes, created_at)
+	      VALUES ($1, $2, $3, $4, $5, NOW()) RETURNING created_at`
+	err = pool.QueryRowContext(ctx, q, c.ClientID, c.ClientSecretHash, c.GrantTypes, c.RedirectURIs, c.Scopes).Scan(&c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *SQLClientStore) Get(ctx context.Context, clientID string) (*OAuthClient, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT client_id, client_secret_hash, grant_types, redirect_uris, scopes, created_at FROM oauth_clients WHERE client_id = $1`
+	c := new(OAuthClient)
+	// sql.ErrNoRows is returned as-is (not wrapped into an opaque message)
+	// so callers can branch on it, e.g. middleware.ErrorMapper mapping it
+	// to 404.
+	if err := pool.QueryRowxContext(ctx, q, clientID).StructScan(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *SQLClientStore) List(ctx context.Context) ([]*OAuthClient, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT client_id, client_secret_hash, grant_types, redirect_uris, scopes, created_at FROM oauth_clients ORDER BY client_id`
+	rows, err := pool.QueryxContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var clients []*OAuthClient
+	for rows.Next() {
+		c := new(OAuthClient)
+		if err := rows.StructScan(c); err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, nil
+}
+
+func (s *SQLClientStore) Update(ctx context.Context, c *OAuthClient) (*OAuthClient, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `UPDATE oauth_clients SET grant_types = $1, redirect_uris = $2, scopes = $3 WHERE client_id = $4`
+	_, err = pool.ExecContext(ctx, q, c.GrantTypes, c.RedirectURIs, c.Scopes, c.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *SQLClientStore) Delete(ctx context.Context, clientID string) error {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE FROM oauth_clients WHERE client_id = $1`
+	_, err = pool.ExecContext(ctx, q, clientID)
+	return err
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// refreshTTL bounds how long an issued (but not yet rotated) refresh token,
+// or a family-revocation marker, is retained.
+const refreshTTL = 30 * 24 * time.Hour
+
+// RefreshTokenStore tracks opaque refresh tokens and their rotation family
+// so that reuse of a revoked token can revoke every token descended from it.
+type RefreshTokenStore interface {
+	// Issue creates a brand new refresh token starting a new rotation family.
+	Issue(ctx context.Context, userID int64) (string, error)
+
+	// Rotate validates token, invalidates it, and issues a replacement in
+	// the same family. If token was already revoked, the entire family is
+	// revoked and ErrRefreshReused is returned.
+	Rotate(ctx context.Context, token string) (newToken string, userID int64, err error)
+
+	// RevokeFamily revokes every refresh token descended from the same
+	// initial issuance as token.
+	RevokeFamily(ctx context.Context, token string) error
+}
+
+// ErrRefreshReused is returned when a previously-rotated (and therefore
+// revoked) refresh token is presented again, indicating possible theft.
+var ErrRefreshReused = fmt.Errorf("refresh token reuse detected")
+
+// refreshTokenRecord is the server-side record for an opaque refresh token.
+type refreshTokenRecord struct {
+	UserID   int64
+	FamilyID string
+	Revoked  bool
+}
+
+// MemoryRefreshTokenStore is a single-process RefreshTokenStore. It's the
+// fallback RedisRefreshTokenStore uses when Redis is unreachable, and is
+// otherwise fine for tests/local development.
+type MemoryRefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*refreshTokenRecord
+}
+
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{tokens: make(map[string]*refreshTokenRecord)}
+}
+
+func (s *MemoryRefreshTokenStore) Issue(_ context.Context, userID int64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := uuid.NewString()
+	s.tokens[token] = &refreshTokenRecord{UserID: userID, FamilyID: uuid.NewString()}
+	return token, nil
+}
+
+func (s *MemoryRefreshTokenStore) Rotate(_ context.Context, token string) (string, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.tokens[token]
+	if !ok {
+		return "", 0, fmt.Errorf("unknown refresh token")
+	}
+	if rec.Revoked {
+		s.revokeFamilyLocked(rec.FamilyID)
+		return "", 0, ErrRefreshReused
+	}
+
+	rec.Revoked = true
+	next := uuid.NewString()
+	s.tokens[next] = &refreshTokenRecord{UserID: rec.UserID, FamilyID: rec.FamilyID}
+	return next, rec.UserID, nil
+}
+
+func (s *MemoryRefreshTokenStore) RevokeFamily(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.tokens[token]
+	if !ok {
+		return nil
+	}
+	s.revokeFamilyLocked(rec.FamilyID)
+	return nil
+}
+
+func (s *MemoryRefreshTokenStore) revokeFamilyLocked(familyID string) {
+	for _, rec := range s.tokens {
+		if rec.FamilyID == familyID {
+			rec.Revoked = true
+		}
+	}
+}
+
+// RedisRefreshTokenStore shares refresh token/rotation state across every
+// replica via Redis, so a stolen token rotated on one instance is rejected
+// (and its family revoked) by all of them. It falls back to an in-process
+// MemoryRefreshTokenStore whenever Redis is unreachable (or nil, e.g. in
+// tests), the same pattern RedisSessionStore/RedisTokenStore use.
+type RedisRefreshTokenStore struct {
+	redis    *redis.Client
+	fallback *MemoryRefreshTokenStore
+}
+
+// NewRedisRefreshTokenStore builds a RedisRefreshTokenStore. client may be
+// nil, in which case it always uses its in-process fallback.
+func NewRedisRefreshTokenStore(client *redis.Client) *RedisRefreshTokenStore {
+	return &RedisRefreshTokenStore{redis: client, fallback: NewMemoryRefreshTokenStore()}
+}
+
+func refreshTokenKey(token string) string { return fmt.Sprintf("refresh_token:%s", token) }
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family_revoked:%s", familyID)
+}
+
+func (s *RedisRefreshTokenStore) Issue(ctx context.Context, userID int64) (string, error) {
+	if s.redis == nil {
+		return s.fallback.Issue(ctx, userID)
+	}
+
+	token := uuid.NewString()
+	familyID := uuid.NewString()
+	err := s.redis.HSet(ctx, refreshTokenKey(token), map[string]interface{}{
+		"user_id":   userID,
+		"family_id": familyID,
+	}).Err()
+	if err != nil {
+		return "", fmt.Errorf("refresh token store: issue: %w", err)
+	}
+	if err := s.redis.Expire(ctx, refreshTokenKey(token), refreshTTL).Err(); err != nil {
+		return "", fmt.Errorf("refresh token store: issue: %w", err)
+	}
+	return token, nil
+}
+
+func (s *RedisRefreshTokenStore) Rotate(ctx context.Context, token string) (string, int64, error) {
+	if s.redis == nil {
+		return s.fallback.Rotate(ctx, token)
+	}
+
+	values, err := s.redis.HGetAll(ctx, refreshTokenKey(token)).Result()
+	if err != nil {
+		return "", 0, fmt.Errorf("refresh token store: rotate: %w", err)
+	}
+	if len(values) == 0 {
+		return "", 0, fmt.Errorf("unknown refresh token")
+	}
+
+	familyID := values["family_id"]
+	if n, err := s.redis.Exists(ctx, refreshFamilyKey(familyID)).Result(); err == nil && n > 0 {
+		return "", 0, ErrRefreshReused
+	}
+
+	userID, err := strconv.ParseInt(values["user_id"], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("refresh token store: rotate: %w", err)
+	}
+
+	if values["revoked"] == "1" {
+		if err := s.revokeFamily(ctx, familyID); err != nil {
+			return "", 0, err
+		}
+		return "", 0, ErrRefreshReused
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, refreshTokenKey(token), "revoked", "1")
+	next := uuid.NewString()
+	pipe.HSet(ctx, refreshTokenKey(next), map[string]interface{}{
+		"user_id":   userID,
+		"family_id": familyID,
+	})
+	pipe.Expire(ctx, refreshTokenKey(next), refreshTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", 0, fmt.Errorf("refresh token store: rotate: %w", err)
+	}
+	return next, userID, nil
+}
+
+func (s *RedisRefreshTokenStore) RevokeFamily(ctx context.Context, token string) error {
+	if s.redis == nil {
+		return s.fallback.RevokeFamily(ctx, token)
+	}
+
+	familyID, err := s.redis.HGet(ctx, refreshTokenKey(token), "family_id").Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("refresh token store: revoke family: %w", err)
+	}
+	return s.revokeFamily(ctx, familyID)
+}
+
+func (s *RedisRefreshTokenStore) revokeFamily(ctx context.Context, familyID string) error {
+	if err := s.redis.Set(ctx, refreshFamilyKey(familyID), "1", refreshTTL).Err(); err != nil {
+		return fmt.Errorf("refresh token store: revoke family: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRefreshTokenStore_IssueAndRotate(t *testing.T) {
+	store := NewMemoryRefreshTokenStore()
+	ctx := context.Background()
+
+	token, err := store.Issue(ctx, 42)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	next, userID, err := store.Rotate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), userID)
+	assert.NotEqual(t, token, next)
+}
+
+func TestMemoryRefreshTokenStore_ReuseRevokesFamily(t *testing.T) {
+	store := NewMemoryRefreshTokenStore()
+	ctx := context.Background()
+
+	token, err := store.Issue(ctx, 7)
+	require.NoError(t, err)
+
+	next, _, err := store.Rotate(ctx, token)
+	require.NoError(t, err)
+
+	// Presenting the already-rotated token again looks like theft: it
+	// should be denied and the whole family (including next) revoked.
+	_, _, err = store.Rotate(ctx, token)
+	assert.ErrorIs(t, err, ErrRefreshReused)
+
+	_, _, err = store.Rotate(ctx, next)
+	assert.ErrorIs(t, err, ErrRefreshReused, "rotating the replacement should also fail once its family is revoked")
+}
+
+func TestMemoryRefreshTokenStore_RevokeFamily(t *testing.T) {
+	store := NewMemoryRefreshTokenStore()
+	ctx := context.Background()
+
+	token, err := store.Issue(ctx, 7)
+	require.NoError(t, err)
+
+	require.NoError(t, store.RevokeFamily(ctx, token))
+
+	_, _, err = store.Rotate(ctx, token)
+	assert.ErrorIs(t, err, ErrRefreshReused)
+}
+
+func TestMemoryRefreshTokenStore_RotateUnknownToken(t *testing.T) {
+	store := NewMemoryRefreshTokenStore()
+	_, _, err := store.Rotate(context.Background(), "not-a-real-token")
+	assert.Error(t, err)
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	apiuser "connex/internal/api/user"
+	"connex/pkg/jwt"
+	"connex/pkg/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserService is a minimal in-memory apiuser.Service, mirroring
+// authn_test.go's fixture; OAuthHandler only ever calls GetByEmail/Update.
+type fakeUserService struct {
+	byEmail map[string]*apiuser.User
+}
+
+func newFakeUserService() *fakeUserService {
+	return &fakeUserService{byEmail: make(map[string]*apiuser.User)}
+}
+
+func (f *fakeUserService) Create(ctx context.Context, u *apiuser.User) (*apiuser.User, error) {
+	f.byEmail[u.Email] = u
+	return u, nil
+}
+func (f *fakeUserService) List(ctx context.Context) ([]*apiuser.User, error) { return nil, nil }
+func (f *fakeUserService) Get(ctx context.Context, id int64) (*apiuser.User, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeUserService) Update(ctx context.Context, u *apiuser.User) (*apiuser.User, error) {
+	f.byEmail[u.Email] = u
+	return u, nil
+}
+func (f *fakeUserService) Delete(ctx context.Context, id int64) error { return nil }
+func (f *fakeUserService) GetByEmail(ctx context.Context, email string) (*apiuser.User, error) {
+	u, ok := f.byEmail[email]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", email)
+	}
+	return u, nil
+}
+func (f *fakeUserService) GetByExternalID(ctx context.Context, authSource, externalID string) (*apiuser.User, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeUserService) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	return nil
+}
+func (f *fakeUserService) SoftDelete(ctx context.Context, id int64) error { return nil }
+func (f *fakeUserService) ListExternal(ctx context.Context, authSource string) ([]*apiuser.User, error) {
+	return nil, nil
+}
+
+// fakeClientStore is a minimal in-memory ClientStore for tests that don't
+// exercise the Postgres-backed SQLClientStore.
+type fakeClientStore struct {
+	byID map[string]*OAuthClient
+}
+
+func newFakeClientStore() *fakeClientStore {
+	return &fakeClientStore{byID: make(map[string]*OAuthClient)}
+}
+
+func (s *fakeClientStore) Create(ctx context.Context, c *OAuthClient) (*OAuthClient, error) {
+	s.byID[c.ClientID] = c
+	return c, nil
+}
+func (s *fakeClientStore) Get(ctx context.Context, clientID string) (*OAuthClient, error) {
+	c, ok := s.byID[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client not found: %s", clientID)
+	}
+	return c, nil
+}
+func (s *fakeClientStore) List(ctx context.Context) ([]*OAuthClient, error) {
+	var out []*OAuthClient
+	for _, c := range s.byID {
+		out = append(out, c)
+	}
+	return out, nil
+}
+func (s *fakeClientStore) Update(ctx context.Context, c *OAuthClient) (*OAuthClient, error) {
+	s.byID[c.ClientID] = c
+	return c, nil
+}
+func (s *fakeClientStore) Delete(ctx context.Context, clientID string) error {
+	delete(s.byID, clientID)
+	return nil
+}
+
+// newTestOAuthHandler wires an OAuthHandler against fakes plus a real
+// in-memory Keyring, registering a single OAuth client allowed to use every
+// grant type the tests below exercise.
+func newTestOAuthHandler(t *testing.T) (*OAuthHandler, *OAuthClient, string) {
+	t.Helper()
+
+	kp, err := jwt.KeyPairFromSeed("test", make([]byte, ed25519.SeedSize))
+	require.NoError(t, err)
+	kr := jwt.NewKeyring()
+	kr.AddKey(kp)
+
+	users := newFakeUserService()
+	clients := newFakeClientStore()
+	h := NewOAuthHandler(users, clients, kr, "connex-test", "connex-api")
+
+	const plainSecret = "s3cret"
+	hash, err := bcrypt.GenerateFromPassword([]byte(plainSecret), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	client := &OAuthClient{
+		ClientID:         "test-client",
+		ClientSecretHash: string(hash),
+		GrantTypes:       []string{GrantPassword, GrantClientCredentials, GrantRefreshToken},
+		RedirectURIs:     []string{"https://example.com/callback"},
+		Scopes:           []string{"profile"},
+	}
+	_, err = clients.Create(context.Background(), client)
+	require.NoError(t, err)
+
+	hashedPW, err := password.Hash("hunter2")
+	require.NoError(t, err)
+	_, err = users.Create(context.Background(), &apiuser.User{ID: 1, Email: "alice@example.com", PasswordHash: hashedPW})
+	require.NoError(t, err)
+
+	return h, client, plainSecret
+}
+
+func tokenRequest(t *testing.T, h *OAuthHandler, clientID, clientSecret string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(clientID, clientSecret)
+	rec := httptest.NewRecorder()
+	h.Token(rec, req)
+	return rec
+}
+
+func TestOAuthHandler_PasswordGrantIssuesTokenPair(t *testing.T) {
+	h, client, secret := newTestOAuthHandler(t)
+
+	form := url.Values{
+		"grant_type": {GrantPassword},
+		"username":   {"alice@example.com"},
+		"password":   {"hunter2"},
+	}
+	rec := tokenRequest(t, h, client.ClientID, secret, form)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"access_token"`)
+	assert.Contains(t, rec.Body.String(), `"refresh_token"`)
+}
+
+func TestOAuthHandler_PasswordGrantWrongPasswordDenied(t *testing.T) {
+	h, client, secret := newTestOAuthHandler(t)
+
+	form := url.Values{
+		"grant_type": {GrantPassword},
+		"username":   {"alice@example.com"},
+		"password":   {"wrong-password"},
+	}
+	rec := tokenRequest(t, h, client.ClientID, secret, form)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_grant")
+}
+
+func TestOAuthHandler_ClientCredentialsGrantDeniedForUnauthorizedGrantType(t *testing.T) {
+	h, _, _ := newTestOAuthHandler(t)
+
+	// A client that's only allowed the password grant must be denied
+	// client_credentials.
+	hash, err := bcrypt.GenerateFromPassword([]byte("sec"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	restricted := &OAuthClient{
+		ClientID:         "restricted-client",
+		ClientSecretHash: string(hash),
+		GrantTypes:       []string{GrantPassword},
+	}
+	clients := h.Clients.(*fakeClientStore)
+	_, err = clients.Create(context.Background(), restricted)
+	require.NoError(t, err)
+
+	form := url.Values{"grant_type": {GrantClientCredentials}}
+	rec := tokenRequest(t, h, restricted.ClientID, "sec", form)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unauthorized_client")
+}
+
+func TestOAuthHandler_RefreshTokenGrantRotatesAndRejectsReuse(t *testing.T) {
+	h, client, secret := newTestOAuthHandler(t)
+
+	form := url.Values{
+		"grant_type": {GrantPassword},
+		"username":   {"alice@example.com"},
+		"password":   {"hunter2"},
+	}
+	rec := tokenRequest(t, h, client.ClientID, secret, form)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp tokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.RefreshToken)
+
+	refreshForm := url.Values{
+		"grant_type":    {GrantRefreshToken},
+		"refresh_token": {resp.RefreshToken},
+	}
+	rec2 := tokenRequest(t, h, client.ClientID, secret, refreshForm)
+	require.Equal(t, http.StatusOK, rec2.Code)
+
+	// The original refresh token was single-use; presenting it again must
+	// be denied as reuse.
+	rec3 := tokenRequest(t, h, client.ClientID, secret, refreshForm)
+	assert.Equal(t, http.StatusUnauthorized, rec3.Code)
+}
+
+func TestOAuthHandler_RevokeThenIntrospectReportsInactive(t *testing.T) {
+	h, client, secret := newTestOAuthHandler(t)
+
+	form := url.Values{
+		"grant_type": {GrantPassword},
+		"username":   {"alice@example.com"},
+		"password":   {"hunter2"},
+	}
+	rec := tokenRequest(t, h, client.ClientID, secret, form)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp tokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	revokeForm := url.Values{"token": {resp.AccessToken}}
+	revokeReq := httptest.NewRequest(http.MethodPost, "/oauth/revoke", strings.NewReader(revokeForm.Encode()))
+	revokeReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	revokeReq.SetBasicAuth(client.ClientID, secret)
+	revokeRec := httptest.NewRecorder()
+	h.Revoke(revokeRec, revokeReq)
+	require.Equal(t, http.StatusOK, revokeRec.Code)
+
+	introForm := url.Values{"token": {resp.AccessToken}}
+	introReq := httptest.NewRequest(http.MethodPost, "/oauth/introspect", strings.NewReader(introForm.Encode()))
+	introReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	introReq.SetBasicAuth(client.ClientID, secret)
+	introRec := httptest.NewRecorder()
+	h.Introspect(introRec, introReq)
+
+	require.Equal(t, http.StatusOK, introRec.Code)
+	assert.Contains(t, introRec.Body.String(), `"active":false`)
+}
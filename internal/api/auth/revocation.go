@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// RevocationChecker reports whether a client certificate presented to
+// CertAuthMiddleware has been revoked by its issuer.
+type RevocationChecker interface {
+	IsRevoked(cert, issuer *x509.Certificate) (bool, error)
+}
+
+// CompositeRevocationChecker checks cert against every wrapped checker in
+// order, short-circuiting (and reporting revoked) on the first one that says
+// so. An empty CompositeRevocationChecker never reports a certificate as
+// revoked.
+type CompositeRevocationChecker []RevocationChecker
+
+func (c CompositeRevocationChecker) IsRevoked(cert, issuer *x509.Certificate) (bool, error) {
+	for _, checker := range c {
+		revoked, err := checker.IsRevoked(cert, issuer)
+		if err != nil {
+			return false, err
+		}
+		if revoked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CRLChecker fetches a CRL from URL on a CRLRefresh interval and checks
+// presented certificates' serial numbers against it. A failed refresh
+// leaves the previous snapshot in place, same as crowdsec.Bouncer's
+// decision cache, so a transient fetch error doesn't start letting revoked
+// certs through.
+type CRLChecker struct {
+	url        string
+	httpClient *http.Client
+
+	mu       sync.RWMutex
+	revoked  map[string]time.Time // serial (decimal string) -> revocation time
+	fetchErr error
+}
+
+// NewCRLChecker builds a CRLChecker and performs an initial synchronous
+// fetch so it's usable immediately; call Refresh periodically (e.g. from a
+// time.Ticker) to keep it current.
+func NewCRLChecker(url string) *CRLChecker {
+	c := &CRLChecker{url: url, httpClient: &http.Client{Timeout: 10 * time.Second}, revoked: make(map[string]time.Time)}
+	c.fetchErr = c.Refresh()
+	return c
+}
+
+// Refresh re-fetches and re-parses the CRL, replacing the cached revocation
+// list wholesale on success.
+func (c *CRLChecker) Refresh() error {
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return fmt.Errorf("crl: fetch %s: %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("crl: read %s: %w", c.url, err)
+	}
+
+	list, err := x509.ParseRevocationList(body)
+	if err != nil {
+		return fmt.Errorf("crl: parse %s: %w", c.url, err)
+	}
+
+	revoked := make(map[string]time.Time, len(list.RevokedCertificateEntries))
+	for _, entry := range list.RevokedCertificateEntries {
+		revoked[entry.SerialNumber.String()] = entry.RevocationTime
+	}
+
+	c.mu.Lock()
+	c.revoked = revoked
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *CRLChecker) IsRevoked(cert, _ *x509.Certificate) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, revoked := c.revoked[cert.SerialNumber.String()]
+	return revoked, nil
+}
+
+// OCSPChecker queries cert.OCSPServer live for each certificate, caching the
+// response until its NextUpdate so repeated requests from the same client
+// don't hit the responder every time.
+type OCSPChecker struct {
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]ocspCacheEntry // serial (decimal string) -> cached result
+}
+
+type ocspCacheEntry struct {
+	revoked    bool
+	validUntil time.Time
+}
+
+// NewOCSPChecker returns a ready-to-use OCSPChecker.
+func NewOCSPChecker() *OCSPChecker {
+	return &OCSPChecker{httpClient: &http.Client{Timeout: 10 * time.Second}, cache: make(map[string]ocspCacheEntry)}
+}
+
+func (c *OCSPChecker) IsRevoked(cert, issuer *x509.Certificate) (bool, error) {
+	serial := cert.SerialNumber.String()
+
+	c.mu.Lock()
+	if entry, ok := c.cache[serial]; ok && time.Now().Before(entry.validUntil) {
+		c.mu.Unlock()
+		return entry.revoked, nil
+	}
+	c.mu.Unlock()
+
+	if len(cert.OCSPServer) == 0 {
+		// No responder to ask; treat as not revoked rather than failing
+		// every request through a CA that doesn't publish OCSP.
+		return false, nil
+	}
+
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return false, fmt.Errorf("ocsp: create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(req))
+	if err != nil {
+		return false, fmt.Errorf("ocsp: request %s: %w", cert.OCSPServer[0], err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("ocsp: read response: %w", err)
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return false, fmt.Errorf("ocsp: parse response: %w", err)
+	}
+
+	revoked := parsed.Status == ocsp.Revoked
+	validUntil := parsed.NextUpdate
+	if validUntil.IsZero() {
+		validUntil = time.Now().Add(5 * time.Minute)
+	}
+	c.mu.Lock()
+	c.cache[serial] = ocspCacheEntry{revoked: revoked, validUntil: validUntil}
+	c.mu.Unlock()
+
+	return revoked, nil
+}
@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// SessionStore tracks server-side login sessions layered on top of the
+// stateless JWTs pkg/jwt signs: a session's id (the `sid` claim) lets
+// AuthMiddleware revoke a specific login, expire it after idle inactivity,
+// and revoke every session for a user at once, none of which a bare JWT
+// signature check can do on its own.
+type SessionStore interface {
+	// Create starts a new session for userID, recording deviceInfo (e.g.
+	// the request's User-Agent) for display on an "active sessions" page,
+	// and returns its session id for embedding in the issued JWT's `sid`
+	// claim.
+	Create(ctx context.Context, userID int64, deviceInfo string) (sessionID string, err error)
+
+	// Touch refreshes a session's idle timeout and returns an error if the
+	// session doesn't exist or has exceeded its idle timeout or absolute
+	// lifetime (in which case it's also revoked as a side effect).
+	Touch(ctx context.Context, sessionID string) error
+
+	// Revoke ends one session, e.g. on logout.
+	Revoke(ctx context.Context, sessionID string) error
+
+	// RevokeAllForUser ends every session belonging to userID, e.g. on
+	// logout-all or when EnableMultiLogin is false and a new login should
+	// kick out every other device.
+	RevokeAllForUser(ctx context.Context, userID int64) error
+
+	// IsValid reports whether sessionID exists and hasn't idle- or
+	// absolute-expired, without refreshing its idle timeout the way Touch
+	// does.
+	IsValid(ctx context.Context, sessionID string) (bool, error)
+}
+
+// RedisSessionStore shares session state across every replica via Redis, so
+// a revocation or logout-all on one instance is honored by all of them. It
+// uses an in-process MemorySessionStore only when its client is nil (e.g. in
+// tests); an error from a configured Redis client is returned to the caller
+// and the in-process store is not consulted.
+type RedisSessionStore struct {
+	redis    *redis.Client
+	fallback *MemorySessionStore
+
+	idleTimeout      time.Duration
+	absoluteLifetime time.Duration
+	multiLogin       bool
+}
+
+// NewRedisSessionStore builds a RedisSessionStore. client may be nil (the
+// store then always uses its in-process fallback). idleTimeout and
+// absoluteLifetime are config.SessionConfig's TokenIdleTimeout and
+// AbsoluteTokenLifetime; multiLogin is EnableMultiLogin.
+func NewRedisSessionStore(client *redis.Client, idleTimeout, absoluteLifetime time.Duration, multiLogin bool) *RedisSessionStore {
+	return &RedisSessionStore{
+		redis:            client,
+		fallback:         NewMemorySessionStore(idleTimeout, absoluteLifetime, multiLogin),
+		idleTimeout:      idleTimeout,
+		absoluteLifetime: absoluteLifetime,
+		multiLogin:       multiLogin,
+	}
+}
+
+func sessionKey(sessionID string) string  { return fmt.Sprintf("session:%s", sessionID) }
+func userSessionsKey(userID int64) string { return fmt.Sprintf("session_user:%d", userID) }
+
+func (s *RedisSessionStore) Create(ctx context.Context, userID int64, deviceInfo string) (string, error) {
+	if s.redis == nil {
+		return s.fallback.Create(ctx, userID, deviceInfo)
+	}
+	if !s.multiLogin {
+		if err := s.RevokeAllForUser(ctx, userID); err != nil {
+			return "", fmt.Errorf("session: revoke existing sessions: %w", err)
+		}
+	}
+
+	sessionID := uuid.NewString()
+	now := time.Now()
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, sessionKey(sessionID), map[string]interface{}{
+		"user_id":     userID,
+		"device_info": deviceInfo,
+		"created_at":  now.Unix(),
+	})
+	pipe.Expire(ctx, sessionKey(sessionID), s.idleTimeout)
+	pipe.SAdd(ctx, userSessionsKey(userID), sessionID)
+	pipe.Expire(ctx, userSessionsKey(userID), s.absoluteLifetime)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("session: create: %w", err)
+	}
+	return sessionID, nil
+}
+
+func (s *RedisSessionStore) Touch(ctx context.Context, sessionID string) error {
+	if s.redis == nil {
+		return s.fallback.Touch(ctx, sessionID)
+	}
+
+	createdAt, err := s.redis.HGet(ctx, sessionKey(sessionID), "created_at").Int64()
+	if err == redis.Nil {
+		return fmt.Errorf("session: not found")
+	}
+	if err != nil {
+		return fmt.Errorf("session: touch: %w", err)
+	}
+	if time.Since(time.Unix(createdAt, 0)) > s.absoluteLifetime {
+		_ = s.Revoke(ctx, sessionID)
+		return fmt.Errorf("session: absolute lifetime exceeded")
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, sessionKey(sessionID), "last_seen", time.Now().Unix())
+	pipe.Expire(ctx, sessionKey(sessionID), s.idleTimeout)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("session: touch: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisSessionStore) Revoke(ctx context.Context, sessionID string) error {
+	if s.redis == nil {
+		return s.fallback.Revoke(ctx, sessionID)
+	}
+
+	userIDStr, err := s.redis.HGet(ctx, sessionKey(sessionID), "user_id").Result()
+	if err == nil {
+		if userID, parseErr := strconv.ParseInt(userIDStr, 10, 64); parseErr == nil {
+			s.redis.SRem(ctx, userSessionsKey(userID), sessionID)
+		}
+	}
+	if err := s.redis.Del(ctx, sessionKey(sessionID)).Err(); err != nil {
+		return fmt.Errorf("session: revoke: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisSessionStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	if s.redis == nil {
+		return s.fallback.RevokeAllForUser(ctx, userID)
+	}
+
+	sessionIDs, err := s.redis.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return fmt.Errorf("session: revoke all for user: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		keys[i] = sessionKey(sessionID)
+	}
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.Del(ctx, userSessionsKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("session: revoke all for user: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisSessionStore) IsValid(ctx context.Context, sessionID string) (bool, error) {
+	if s.redis == nil {
+		return s.fallback.IsValid(ctx, sessionID)
+	}
+	createdAt, err := s.redis.HGet(ctx, sessionKey(sessionID), "created_at").Int64()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("session: is valid: %w", err)
+	}
+	if time.Since(time.Unix(createdAt, 0)) > s.absoluteLifetime {
+		_ = s.Revoke(ctx, sessionID)
+		return false, nil
+	}
+	return true, nil
+}
+
+// memorySession is one session's state in a MemorySessionStore.
+type memorySession struct {
+	userID     int64
+	deviceInfo string
+	createdAt  time.Time
+	lastSeen   time.Time
+}
+
+// MemorySessionStore is the in-process store RedisSessionStore uses when it
+// has no Redis client.
+type MemorySessionStore struct {
+	mu sync.Mutex
+
+	idleTimeout      time.Duration
+	absoluteLifetime time.Duration
+	multiLogin       bool
+
+	sessions map[string]*memorySession
+	byUser   map[int64]map[string]struct{}
+}
+
+// NewMemorySessionStore returns an empty, ready-to-use MemorySessionStore.
+func NewMemorySessionStore(idleTimeout, absoluteLifetime time.Duration, multiLogin bool) *MemorySessionStore {
+	return &MemorySessionStore{
+		idleTimeout:      idleTimeout,
+		absoluteLifetime: absoluteLifetime,
+		multiLogin:       multiLogin,
+		sessions:         make(map[string]*memorySession),
+		byUser:           make(map[int64]map[string]struct{}),
+	}
+}
+
+func (m *MemorySessionStore) Create(_ context.Context, userID int64, deviceInfo string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.multiLogin {
+		m.revokeAllForUserLocked(userID)
+	}
+
+	sessionID := uuid.NewString()
+	now := time.Now()
+	m.sessions[sessionID] = &memorySession{userID: userID, deviceInfo: deviceInfo, createdAt: now, lastSeen: now}
+	if m.byUser[userID] == nil {
+		m.byUser[userID] = make(map[string]struct{})
+	}
+	m.byUser[userID][sessionID] = struct{}{}
+	return sessionID, nil
+}
+
+func (m *MemorySessionStore) Touch(_ context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("session: not found")
+	}
+	now := time.Now()
+	if now.Sub(session.createdAt) > m.absoluteLifetime || now.Sub(session.lastSeen) > m.idleTimeout {
+		m.revokeLocked(sessionID)
+		return fmt.Errorf("session: expired")
+	}
+	session.lastSeen = now
+	return nil
+}
+
+func (m *MemorySessionStore) Revoke(_ context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revokeLocked(sessionID)
+	return nil
+}
+
+func (m *MemorySessionStore) revokeLocked(sessionID string) {
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return
+	}
+	delete(m.sessions, sessionID)
+	delete(m.byUser[session.userID], sessionID)
+}
+
+func (m *MemorySessionStore) RevokeAllForUser(_ context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revokeAllForUserLocked(userID)
+	return nil
+}
+
+func (m *MemorySessionStore) revokeAllForUserLocked(userID int64) {
+	for sessionID := range m.byUser[userID] {
+		delete(m.sessions, sessionID)
+	}
+	delete(m.byUser, userID)
+}
+
+func (m *MemorySessionStore) IsValid(_ context.Context, sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return false, nil
+	}
+	now := time.Now()
+	if now.Sub(session.createdAt) > m.absoluteLifetime || now.Sub(session.lastSeen) > m.idleTimeout {
+		m.revokeLocked(sessionID)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenStore tracks revoked access tokens by `jti` so a token can be killed
+// before its natural expiry (logout, password change, a compromised
+// session), and answers RFC 7662 introspection requests. verify resolves a
+// raw token string to its claims, so the same TokenStore shape works for
+// both the legacy HS256 tokens (auth.AuthMiddleware) and the OAuth2 Ed25519
+// tokens (OAuthHandler) — each constructs its store with its own verifier.
+type TokenStore interface {
+	Revoke(ctx context.Context, jti string, exp time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	Introspect(ctx context.Context, token string) (active bool, claims jwt.MapClaims, err error)
+}
+
+// verifyFunc checks a token's signature and standard claims and returns its
+// claims, e.g. jwt.ParseJWT(token, secret) or jwt.ParseSignedJWT(keyring, token).
+type verifyFunc func(token string) (jwt.MapClaims, error)
+
+// MemoryTokenStore is a single-process revocation list, keyed by jti with
+// the claimed expiry so Revoke doesn't grow unbounded: IsRevoked treats an
+// entry as gone once its exp has passed, and a small janitor goroutine could
+// be added later to actually free the memory (fine at today's scale).
+type MemoryTokenStore struct {
+	verify verifyFunc
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryTokenStore returns a MemoryTokenStore that uses verify to check
+// token signatures during Introspect.
+func NewMemoryTokenStore(verify verifyFunc) *MemoryTokenStore {
+	return &MemoryTokenStore{verify: verify, revoked: make(map[string]time.Time)}
+}
+
+func (s *MemoryTokenStore) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	s.mu.Lock()
+	s.revoked[jti] = exp
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryTokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	exp, ok := s.revoked[jti]
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(exp) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *MemoryTokenStore) Introspect(ctx context.Context, token string) (bool, jwt.MapClaims, error) {
+	return introspect(ctx, s, s.verify, token)
+}
+
+// RedisTokenStore shares the revocation list across every replica. Each
+// revoked jti is stored with a TTL equal to the token's remaining lifetime,
+// so the key set stays bounded without needing a janitor.
+type RedisTokenStore struct {
+	client *redis.Client
+	verify verifyFunc
+}
+
+// NewRedisTokenStore returns a RedisTokenStore backed by client, using
+// verify to check token signatures during Introspect.
+func NewRedisTokenStore(client *redis.Client, verify verifyFunc) *RedisTokenStore {
+	return &RedisTokenStore{client: client, verify: verify}
+}
+
+func (s *RedisTokenStore) key(jti string) string {
+	return fmt.Sprintf("revoked_jti:%s", jti)
+}
+
+func (s *RedisTokenStore) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		// Already expired on its own; nothing left to revoke.
+		return nil
+	}
+	return s.client.Set(ctx, s.key(jti), "1", ttl).Err()
+}
+
+func (s *RedisTokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.key(jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("tokenstore: failed to check revocation: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *RedisTokenStore) Introspect(ctx context.Context, token string) (bool, jwt.MapClaims, error) {
+	return introspect(ctx, s, s.verify, token)
+}
+
+// introspect implements RFC 7662 semantics shared by both TokenStore
+// implementations: a malformed, expired, or revoked token is simply
+// inactive, not an error.
+func introspect(ctx context.Context, store TokenStore, verify verifyFunc, token string) (bool, jwt.MapClaims, error) {
+	claims, err := verify(token)
+	if err != nil {
+		return false, nil, nil
+	}
+	jti, _ := claims["jti"].(string)
+	if jti != "" {
+		revoked, err := store.IsRevoked(ctx, jti)
+		if err != nil {
+			return false, nil, err
+		}
+		if revoked {
+			return false, claims, nil
+		}
+	}
+	return true, claims, nil
+}
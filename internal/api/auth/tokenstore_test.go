@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTokenStore_RevokeAndIsRevoked(t *testing.T) {
+	store := NewMemoryTokenStore(nil)
+	ctx := context.Background()
+
+	revoked, err := store.IsRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.False(t, revoked, "an unrevoked jti should report false")
+
+	require.NoError(t, store.Revoke(ctx, "jti-1", time.Now().Add(time.Hour)))
+
+	revoked, err = store.IsRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestMemoryTokenStore_IsRevokedFalseAfterExpiry(t *testing.T) {
+	store := NewMemoryTokenStore(nil)
+	ctx := context.Background()
+
+	require.NoError(t, store.Revoke(ctx, "jti-1", time.Now().Add(-time.Minute)))
+
+	revoked, err := store.IsRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.False(t, revoked, "a revocation entry past its exp should no longer count as revoked")
+}
+
+func TestMemoryTokenStore_Introspect(t *testing.T) {
+	verify := func(token string) (jwt.MapClaims, error) {
+		if token != "good-token" {
+			return nil, errors.New("bad token")
+		}
+		return jwt.MapClaims{"jti": "jti-1", "sub": "42"}, nil
+	}
+	store := NewMemoryTokenStore(verify)
+	ctx := context.Background()
+
+	active, claims, err := store.Introspect(ctx, "good-token")
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, "42", claims["sub"])
+
+	active, _, err = store.Introspect(ctx, "bad-token")
+	require.NoError(t, err)
+	assert.False(t, active, "a token that fails verify should report inactive, not error")
+
+	require.NoError(t, store.Revoke(ctx, "jti-1", time.Now().Add(time.Hour)))
+	active, _, err = store.Introspect(ctx, "good-token")
+	require.NoError(t, err)
+	assert.False(t, active, "a revoked jti should report inactive even though it still verifies")
+}
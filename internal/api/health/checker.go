@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Checker is implemented by a subsystem health.Registry fans HealthCheck out
+// to. Name is used as the Services map key in HealthResponse.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) ServiceInfo
+
+	// Critical reports whether this checker failing should mark the overall
+	// HealthResponse.Status "unhealthy" rather than "degraded".
+	Critical() bool
+
+	// Timeout bounds how long Check is allowed to run before Registry.Check
+	// gives up on it and reports it unhealthy on its behalf.
+	Timeout() time.Duration
+}
+
+// Registry fans a set of Checkers out concurrently, each bounded by its own
+// Timeout, and caches the aggregate result for CacheTTL so frequent callers
+// (e.g. a Kubernetes liveness probe hitting /health every second) don't
+// hammer the underlying subsystems.
+type Registry struct {
+	cacheTTL time.Duration
+	checkers []Checker
+
+	mu       sync.Mutex
+	cached   map[string]ServiceInfo
+	cachedAt time.Time
+}
+
+// NewRegistry builds an empty Registry. cacheTTL of 0 disables caching.
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL}
+}
+
+// Register adds a Checker. Not safe to call concurrently with Check.
+func (reg *Registry) Register(c Checker) {
+	reg.checkers = append(reg.checkers, c)
+}
+
+// Check runs every registered Checker concurrently, each cut off at its own
+// Timeout, and returns the per-service results keyed by Name(). A checker
+// that doesn't return by its Timeout is reported unhealthy and does not
+// delay the response, even if its goroutine keeps running in the
+// background.
+func (reg *Registry) Check(ctx context.Context) map[string]ServiceInfo {
+	reg.mu.Lock()
+	if reg.cacheTTL > 0 && reg.cached != nil && time.Since(reg.cachedAt) < reg.cacheTTL {
+		cached := reg.cached
+		reg.mu.Unlock()
+		return cached
+	}
+	reg.mu.Unlock()
+
+	results := make(map[string]ServiceInfo, len(reg.checkers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range reg.checkers {
+		wg.Add(1)
+		go func(c Checker) {
+			defer wg.Done()
+			mu.Lock()
+			results[c.Name()] = runChecker(ctx, c)
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	reg.mu.Lock()
+	reg.cached = results
+	reg.cachedAt = time.Now()
+	reg.mu.Unlock()
+
+	return results
+}
+
+// runChecker runs a single Checker bounded by its own Timeout.
+func runChecker(ctx context.Context, c Checker) ServiceInfo {
+	start := time.Now()
+	checkCtx, cancel := context.WithTimeout(ctx, c.Timeout())
+	defer cancel()
+
+	done := make(chan ServiceInfo, 1)
+	go func() { done <- c.Check(checkCtx) }()
+
+	select {
+	case info := <-done:
+		return info
+	case <-checkCtx.Done():
+		return ServiceInfo{
+			Status:  "unhealthy",
+			Message: "check timed out after " + c.Timeout().String(),
+			Latency: time.Since(start).String(),
+		}
+	}
+}
+
+// Ready reports whether every Critical checker in results reported healthy.
+func (reg *Registry) Ready(results map[string]ServiceInfo) bool {
+	for _, c := range reg.checkers {
+		if !c.Critical() {
+			continue
+		}
+		if info, ok := results[c.Name()]; !ok || info.Status != "healthy" {
+			return false
+		}
+	}
+	return true
+}
+
+// Critical reports whether a registered checker named name is critical.
+func (reg *Registry) Critical(name string) bool {
+	for _, c := range reg.checkers {
+		if c.Name() == name {
+			return c.Critical()
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChecker is a Checker whose Check blocks for delay before returning
+// status, so tests can exercise Registry.Check's timeout cutoff.
+type fakeChecker struct {
+	name     string
+	delay    time.Duration
+	timeout  time.Duration
+	critical bool
+	status   string
+}
+
+func (f fakeChecker) Name() string           { return f.name }
+func (f fakeChecker) Critical() bool         { return f.critical }
+func (f fakeChecker) Timeout() time.Duration { return f.timeout }
+func (f fakeChecker) Check(ctx context.Context) ServiceInfo {
+	select {
+	case <-time.After(f.delay):
+		return ServiceInfo{Status: f.status}
+	case <-ctx.Done():
+		return ServiceInfo{Status: "unhealthy", Message: "context canceled"}
+	}
+}
+
+func TestRegistry_SlowCheckerCutOffAtTimeout(t *testing.T) {
+	reg := NewRegistry(0)
+	reg.Register(fakeChecker{name: "slow", delay: 200 * time.Millisecond, timeout: 20 * time.Millisecond, status: "healthy"})
+
+	start := time.Now()
+	results := reg.Check(context.Background())
+	elapsed := time.Since(start)
+
+	require.Contains(t, results, "slow")
+	assert.Equal(t, "unhealthy", results["slow"].Status)
+	assert.Less(t, elapsed, 150*time.Millisecond, "Check should not block for the full checker delay")
+}
+
+func TestRegistry_DegradedVsUnhealthy(t *testing.T) {
+	reg := NewRegistry(0)
+	reg.Register(fakeChecker{name: "db", timeout: time.Second, critical: true, status: "healthy"})
+	reg.Register(fakeChecker{name: "jobs", timeout: time.Second, critical: false, status: "unhealthy"})
+
+	results := reg.Check(context.Background())
+	assert.Equal(t, "unhealthy", results["jobs"].Status)
+	assert.False(t, reg.Critical("jobs"))
+	assert.True(t, reg.Critical("db"))
+	assert.True(t, reg.Ready(results), "a non-critical failure should not affect readiness")
+}
+
+func TestRegistry_NotReadyWhenCriticalFails(t *testing.T) {
+	reg := NewRegistry(0)
+	reg.Register(fakeChecker{name: "db", timeout: time.Second, critical: true, status: "unhealthy"})
+
+	results := reg.Check(context.Background())
+	assert.False(t, reg.Ready(results))
+}
+
+func TestRegistry_CachesResultWithinTTL(t *testing.T) {
+	reg := NewRegistry(50 * time.Millisecond)
+	calls := 0
+	reg.Register(countingChecker{name: "db", calls: &calls})
+
+	reg.Check(context.Background())
+	reg.Check(context.Background())
+	assert.Equal(t, 1, calls, "second call within cacheTTL should reuse the cached result")
+
+	time.Sleep(60 * time.Millisecond)
+	reg.Check(context.Background())
+	assert.Equal(t, 2, calls, "call after cacheTTL expires should re-run the checker")
+}
+
+type countingChecker struct {
+	name  string
+	calls *int
+}
+
+func (c countingChecker) Name() string           { return c.name }
+func (c countingChecker) Critical() bool         { return true }
+func (c countingChecker) Timeout() time.Duration { return time.Second }
+func (c countingChecker) Check(ctx context.Context) ServiceInfo {
+	*c.calls++
+	return ServiceInfo{Status: "healthy"}
+}
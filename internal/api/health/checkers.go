@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"connex/internal/cache"
+	"connex/internal/db"
+	"connex/internal/job"
+)
+
+// defaultCheckTimeout bounds the built-in checkers below; each pings a
+// single dependency and should return in well under a second when healthy.
+const defaultCheckTimeout = 2 * time.Second
+
+// DBChecker pings the primary Postgres connection pool. It's Critical: the
+// API can't serve most routes without a database.
+type DBChecker struct{}
+
+func (DBChecker) Name() string           { return "database" }
+func (DBChecker) Critical() bool         { return true }
+func (DBChecker) Timeout() time.Duration { return defaultCheckTimeout }
+
+func (DBChecker) Check(ctx context.Context) ServiceInfo {
+	start := time.Now()
+	conn := db.Get()
+	if conn == nil {
+		return ServiceInfo{Status: "unhealthy", Message: "database not initialized", Latency: time.Since(start).String()}
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		return ServiceInfo{Status: "unhealthy", Message: err.Error(), Latency: time.Since(start).String()}
+	}
+	return ServiceInfo{Status: "healthy", Latency: time.Since(start).String()}
+}
+
+// RedisChecker pings the shared Redis instance backing caching, sessions,
+// and the token denylist. It's Critical: those flows degrade to
+// fail-closed/fail-open behavior that's worth surfacing as unhealthy rather
+// than merely degraded.
+type RedisChecker struct{}
+
+func (RedisChecker) Name() string           { return "redis" }
+func (RedisChecker) Critical() bool         { return true }
+func (RedisChecker) Timeout() time.Duration { return defaultCheckTimeout }
+
+func (RedisChecker) Check(ctx context.Context) ServiceInfo {
+	start := time.Now()
+	client := cache.Get()
+	if client == nil {
+		return ServiceInfo{Status: "unhealthy", Message: "redis not initialized", Latency: time.Since(start).String()}
+	}
+	if err := client.Ping(ctx).Err(); err != nil {
+		return ServiceInfo{Status: "unhealthy", Message: err.Error(), Latency: time.Since(start).String()}
+	}
+	return ServiceInfo{Status: "healthy", Latency: time.Since(start).String()}
+}
+
+// AsynqChecker pings the Asynq broker (the same Redis instance, addressed
+// through the job queue client). It's non-Critical: a stalled job queue
+// degrades background processing but shouldn't take the whole API out of
+// rotation.
+type AsynqChecker struct{}
+
+func (AsynqChecker) Name() string           { return "jobs" }
+func (AsynqChecker) Critical() bool         { return false }
+func (AsynqChecker) Timeout() time.Duration { return defaultCheckTimeout }
+
+func (AsynqChecker) Check(ctx context.Context) ServiceInfo {
+	start := time.Now()
+	if err := job.Ping(); err != nil {
+		return ServiceInfo{Status: "unhealthy", Message: err.Error(), Latency: time.Since(start).String()}
+	}
+	return ServiceInfo{Status: "healthy", Latency: time.Since(start).String()}
+}
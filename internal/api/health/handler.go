@@ -7,16 +7,31 @@ import (
 	"runtime"
 	"time"
 
-	"connex/internal/cache"
-	"connex/internal/db"
+	"connex/internal/middleware"
 )
 
+// defaultCacheTTL bounds how long Registry.Check's aggregate result is
+// reused across repeated /health calls, so a Kubernetes probe hitting this
+// endpoint at high frequency doesn't hammer Postgres/Redis/Asynq on every
+// request.
+const defaultCacheTTL = 2 * time.Second
+
 type HealthResponse struct {
-	Status    string                 `json:"status"`
-	Timestamp time.Time              `json:"timestamp"`
-	Uptime    string                 `json:"uptime"`
-	Services  map[string]ServiceInfo `json:"services"`
-	System    SystemInfo             `json:"system"`
+	Status      string                 `json:"status"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Uptime      string                 `json:"uptime"`
+	Services    map[string]ServiceInfo `json:"services"`
+	System      SystemInfo             `json:"system"`
+	Compression CompressionInfo        `json:"compression"`
+}
+
+// CompressionInfo reports custommiddleware.CompressionMiddleware's
+// cumulative activity since process start.
+type CompressionInfo struct {
+	ResponsesCompressed int64   `json:"responses_compressed"`
+	BytesIn             int64   `json:"bytes_in"`
+	BytesOut            int64   `json:"bytes_out"`
+	Ratio               float64 `json:"ratio"`
 }
 
 type ServiceInfo struct {
@@ -35,61 +50,59 @@ type SystemInfo struct {
 var startTime = time.Now()
 
 // Handler handles health check requests
-type Handler struct{}
+type Handler struct {
+	Registry *Registry
+}
 
+// NewHandler builds a Handler with the built-in DB/Redis/Asynq checkers
+// registered against a defaultCacheTTL result cache. Use WithRegistry to
+// replace it, e.g. with a longer/shorter cache TTL or additional checkers.
 func NewHandler() *Handler {
-	return &Handler{}
+	reg := NewRegistry(defaultCacheTTL)
+	reg.Register(DBChecker{})
+	reg.Register(RedisChecker{})
+	reg.Register(AsynqChecker{})
+	return &Handler{Registry: reg}
 }
 
-// HealthCheck provides a comprehensive health check
+// WithRegistry overrides the handler's checker registry.
+func (h *Handler) WithRegistry(reg *Registry) *Handler {
+	h.Registry = reg
+	return h
+}
+
+// HealthCheck provides a comprehensive health check, fanning out to every
+// registered Checker concurrently. Status is "unhealthy" if any Critical
+// checker failed, "degraded" if only non-critical checkers failed, and
+// "healthy" otherwise.
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := HealthResponse{
-		Status:    "healthy",
-		Timestamp: time.Now(),
-		Uptime:    time.Since(startTime).String(),
-		Services:  make(map[string]ServiceInfo),
-		System:    h.getSystemInfo(),
-	}
+	services := h.Registry.Check(r.Context())
 
-	// Check database
-	dbStart := time.Now()
-	dbErr := h.checkDatabase()
-	dbLatency := time.Since(dbStart)
-	if dbErr != nil {
-		response.Status = "degraded"
-		response.Services["database"] = ServiceInfo{
-			Status:  "unhealthy",
-			Message: dbErr.Error(),
-			Latency: dbLatency.String(),
+	status := "healthy"
+	for name, info := range services {
+		if info.Status == "healthy" {
+			continue
+		}
+		if h.Registry.Critical(name) {
+			status = "unhealthy"
+			break
 		}
-	} else {
-		response.Services["database"] = ServiceInfo{
-			Status:  "healthy",
-			Latency: dbLatency.String(),
+		if status == "healthy" {
+			status = "degraded"
 		}
 	}
 
-	// Check Redis
-	redisStart := time.Now()
-	redisErr := h.checkRedis()
-	redisLatency := time.Since(redisStart)
-	if redisErr != nil {
-		response.Status = "degraded"
-		response.Services["redis"] = ServiceInfo{
-			Status:  "unhealthy",
-			Message: redisErr.Error(),
-			Latency: redisLatency.String(),
-		}
-	} else {
-		response.Services["redis"] = ServiceInfo{
-			Status:  "healthy",
-			Latency: redisLatency.String(),
-		}
+	response := HealthResponse{
+		Status:      status,
+		Timestamp:   time.Now(),
+		Uptime:      time.Since(startTime).String(),
+		Services:    services,
+		System:      h.getSystemInfo(),
+		Compression: h.getCompressionInfo(),
 	}
 
-	// Set appropriate status code
 	statusCode := http.StatusOK
-	if response.Status == "degraded" {
+	if response.Status != "healthy" {
 		statusCode = http.StatusServiceUnavailable
 	}
 
@@ -107,36 +120,38 @@ func (h *Handler) SimpleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// ReadinessCheck checks if the service is ready to accept traffic
+// ReadinessCheck reports whether the service is ready to accept traffic:
+// every Critical checker (see Registry.Ready) must currently be healthy.
 func (h *Handler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
-	// Check critical dependencies
-	if err := h.checkDatabase(); err != nil {
-		w.Header().Set("Content-Type", "application/json")
+	services := h.Registry.Check(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if !h.Registry.Ready(services) {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(map[string]string{
 			"status":  "not ready",
-			"message": "database unavailable",
+			"message": "a critical dependency is unavailable",
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
 		"status": "ready",
 	})
 }
 
-func (h *Handler) checkDatabase() error {
-	db := db.Get()
-	if db == nil {
-		return fmt.Errorf("database not initialized")
+func (h *Handler) getCompressionInfo() CompressionInfo {
+	responses, bytesIn, bytesOut := middleware.CompressionStats()
+	info := CompressionInfo{
+		ResponsesCompressed: responses,
+		BytesIn:             bytesIn,
+		BytesOut:            bytesOut,
 	}
-	return db.Ping()
-}
-
-func (h *Handler) checkRedis() error {
-	return cache.HealthCheck()
+	if bytesIn > 0 {
+		info.Ratio = 1 - float64(bytesOut)/float64(bytesIn)
+	}
+	return info
 }
 
 func (h *Handler) getSystemInfo() SystemInfo {
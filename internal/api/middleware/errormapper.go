@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/lib/pq"
+)
+
+// Stable error codes ErrorMapper assigns to common typed errors, so the
+// admin UI (and any other client) can branch on Code instead of
+// string-matching Error.
+const (
+	CodeConflict = "CONFLICT"
+	CodeNotFound = "NOT_FOUND"
+	CodeTimeout  = "TIMEOUT"
+)
+
+// pqUniqueViolation is the Postgres SQLSTATE for unique_violation.
+const pqUniqueViolation = "23505"
+
+// ErrorMapper writes the ErrorResponse matching err's concrete type -
+// a Postgres unique-violation becomes 409 CONFLICT, sql.ErrNoRows becomes
+// 404 NOT_FOUND, a context deadline becomes 504 TIMEOUT - falling back to a
+// generic 500 for anything it doesn't recognize. fallback is only logged:
+// the 500 body carries getSafeErrorMessage's generic "Internal server error"
+// text. Handlers should call this instead of hand-rolling the same
+// switch at every call site.
+func ErrorMapper(w http.ResponseWriter, err error, fallback string) {
+	var pqErr *pq.Error
+
+	switch {
+	case errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation:
+		WriteStructuredError(w, http.StatusConflict, "resource already exists", CodeConflict, "")
+	case errors.Is(err, sql.ErrNoRows):
+		WriteStructuredError(w, http.StatusNotFound, "resource not found", CodeNotFound, "")
+	case errors.Is(err, context.DeadlineExceeded):
+		WriteStructuredError(w, http.StatusGatewayTimeout, "request timed out", CodeTimeout, "")
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
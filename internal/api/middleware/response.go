@@ -10,9 +10,19 @@ import (
 )
 
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
-	Request string `json:"request_id,omitempty"`
+	Error   string            `json:"error"`
+	Code    string            `json:"code,omitempty"`
+	Request string            `json:"request_id,omitempty"`
+	Fields  []ValidationError `json:"fields,omitempty"`
+}
+
+// ValidationError describes a single field that failed validation. Rule is
+// the validator tag that failed (e.g. "required", "email"), for clients
+// that want to branch on it instead of parsing Message.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
 }
 
 // WriteError writes a standardized error response
@@ -62,11 +72,20 @@ func getSafeErrorMessage(status int, msg string) string {
 	}
 }
 
-// WriteValidationError writes a validation error response
+// WriteValidationError writes a single-field validation error response.
+// Prefer WriteValidationErrors when more than one field failed.
 func WriteValidationError(w http.ResponseWriter, field, message string) {
+	WriteValidationErrors(w, []ValidationError{{Field: field, Message: message}})
+}
+
+// WriteValidationErrors writes a validation error response aggregating every
+// field that failed, so clients can highlight each one instead of guessing
+// from a single combined message.
+func WriteValidationErrors(w http.ResponseWriter, errs []ValidationError) {
 	response := ErrorResponse{
-		Error: "Validation failed",
-		Code:  "VALIDATION_ERROR",
+		Error:  "Validation failed",
+		Code:   "VALIDATION_ERROR",
+		Fields: errs,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator runs struct tag validation via go-playground/validator and
+// translates its FieldErrors into ValidationErrors, so handlers can feed the
+// result straight into WriteValidationErrors.
+type Validator struct {
+	v *validator.Validate
+}
+
+// NewValidator builds a Validator that reports fields by their JSON tag
+// (falling back to the Go field name) instead of validator's default struct
+// field name, so errors line up with the request body clients actually sent.
+func NewValidator() *Validator {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return fld.Name
+		}
+		return name
+	})
+	return &Validator{v: v}
+}
+
+// Struct validates dto and returns one ValidationError per failed field, or
+// nil if dto is valid.
+func (val *Validator) Struct(dto interface{}) []ValidationError {
+	err := val.v.Struct(dto)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		// Malformed dto (e.g. validating a non-struct) rather than a failed
+		// rule - surface it as a single opaque error instead of panicking.
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	out := make([]ValidationError, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		out = append(out, ValidationError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: validationMessage(fe),
+		})
+	}
+	return out
+}
+
+// validationMessage renders a human-readable message for the common
+// validator tags this codebase's DTOs use; anything else falls back to a
+// generic "failed validation" message naming the rule.
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s characters", fe.Field(), fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), fe.Param())
+	default:
+		return fmt.Sprintf("%s failed validation on %q", fe.Field(), fe.Tag())
+	}
+}
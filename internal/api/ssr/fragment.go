@@ -0,0 +1,97 @@
+package ssr
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"connex/internal/api/websocket"
+)
+
+// FragmentAction describes how a client should patch a Fragment's HTML into
+// the DOM, matching Turbo Stream's vocabulary.
+type FragmentAction string
+
+const (
+	FragmentReplace FragmentAction = "replace"
+	FragmentAppend  FragmentAction = "append"
+	FragmentPrepend FragmentAction = "prepend"
+	FragmentRemove  FragmentAction = "remove"
+)
+
+// turboStreamContentType is the media type RenderFragments responds with,
+// signaling a progressive-enhancement client (e.g. Turbo) to patch the DOM
+// instead of treating the body as a full document.
+const turboStreamContentType = "text/vnd.turbo-stream.html; charset=utf-8"
+
+// Fragment is one named region of a page - declared in a template with
+// `{{fragment "id"}}...{{end}}` - rendered and delivered independently of a
+// full-page response.
+type Fragment struct {
+	ID     string
+	Action FragmentAction
+	HTML   template.HTML
+}
+
+// RenderFragment executes the named region fragmentID declares (via
+// `{{fragment "id"}}...{{end}}`) within templateName, using the same
+// buildTemplateData as a full-page render so State/StateJSON stay identical
+// between the two. It's the counterpart to RenderTemplate for producing a
+// single Fragment to pass to RenderFragments or BroadcastFragments.
+func (h *Handler) RenderFragment(r *http.Request, templateName, fragmentID string, action FragmentAction, data SSRData) (Fragment, error) {
+	tmpl, exists := h.templates[templateName]
+	if !exists {
+		if err := h.LoadTemplate(templateName); err != nil {
+			return Fragment{}, err
+		}
+		tmpl = h.templates[templateName]
+	}
+
+	templateData, err := h.buildTemplateData(r, data)
+	if err != nil {
+		return Fragment{}, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "fragment:"+fragmentID, templateData); err != nil {
+		return Fragment{}, fmt.Errorf("failed to execute fragment %q of template %q: %w", fragmentID, templateName, err)
+	}
+
+	return Fragment{ID: fragmentID, Action: action, HTML: template.HTML(buf.String())}, nil
+}
+
+// RenderFragments writes fragments to w as a turbo-stream-style response: one
+// `<turbo-stream>` element per fragment, concatenated in order, for a client
+// to patch into the DOM without a full page reload.
+func (h *Handler) RenderFragments(w http.ResponseWriter, fragments []Fragment) error {
+	w.Header().Set("Content-Type", turboStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	for _, f := range fragments {
+		if _, err := fmt.Fprintf(w, "<turbo-stream action=%q target=%q><template>%s</template></turbo-stream>",
+			f.Action, f.ID, f.HTML); err != nil {
+			return fmt.Errorf("failed to write fragment %q: %w", f.ID, err)
+		}
+	}
+	return nil
+}
+
+// BroadcastFragments pushes fragments to every WebSocket subscriber of topic
+// (a Hub room) so browsers already on the page can patch the DOM live,
+// instead of only the client that triggered the render. WithHub must be
+// called first.
+func (h *Handler) BroadcastFragments(topic string, fragments []Fragment) error {
+	if h.hub == nil {
+		return fmt.Errorf("ssr: BroadcastFragments called without a hub; call WithHub first")
+	}
+
+	h.hub.Broadcast(&websocket.Message{
+		Type:      websocket.MessageTypeFragment,
+		Data:      fragments,
+		Timestamp: time.Now(),
+		Room:      topic,
+	})
+	return nil
+}
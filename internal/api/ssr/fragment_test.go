@@ -0,0 +1,73 @@
+package ssr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderFragment_MatchesFullPageAndKeepsStateInSync renders the same
+// template both as a full page and as a single fragment, and asserts the
+// fragment's markup (rendered from State) appears verbatim in the full-page
+// output and the full page's hydration state carries that same State.
+func TestRenderFragment_MatchesFullPageAndKeepsStateInSync(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "counter.html"), []byte(
+		`<html><body>`+
+			`{{fragment "count"}}<div id="count">{{.State.count}}</div>{{end}}`+
+			`{{.StateScript}}`+
+			`</body></html>`,
+	), 0o644))
+
+	h := NewHandler(dir)
+	data := SSRData{State: map[string]interface{}{"count": float64(3)}}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.RenderTemplate(rec, req, "counter", data))
+	fullPage := rec.Body.String()
+
+	fragment, err := h.RenderFragment(req, "counter", "count", FragmentReplace, data)
+	require.NoError(t, err)
+
+	assert.Contains(t, fullPage, string(fragment.HTML))
+	assert.Equal(t, FragmentReplace, fragment.Action)
+	assert.Equal(t, "count", fragment.ID)
+
+	fullPageStateJSON := fullPage[strings.Index(fullPage, `window.__SSR_STATE__ = `)+len(`window.__SSR_STATE__ = `):]
+	fullPageStateJSON = strings.TrimSuffix(fullPageStateJSON, "</script></body></html>")
+	assert.Contains(t, fullPageStateJSON, `"count":3`)
+}
+
+// TestRenderFragments_WritesTurboStreamPerFragment verifies RenderFragments
+// emits one <turbo-stream> element per Fragment, in order, with the expected
+// action/target/content-type.
+func TestRenderFragments_WritesTurboStreamPerFragment(t *testing.T) {
+	h := NewHandler(t.TempDir())
+	rec := httptest.NewRecorder()
+
+	fragments := []Fragment{
+		{ID: "count", Action: FragmentReplace, HTML: "<div id=\"count\">4</div>"},
+		{ID: "log", Action: FragmentAppend, HTML: "<li>new entry</li>"},
+	}
+	require.NoError(t, h.RenderFragments(rec, fragments))
+
+	assert.Equal(t, turboStreamContentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, `<turbo-stream action="replace" target="count"><template><div id="count">4</div></template></turbo-stream>`)
+	assert.Contains(t, body, `<turbo-stream action="append" target="log"><template><li>new entry</li></template></turbo-stream>`)
+}
+
+// TestBroadcastFragments_RequiresHub ensures a Handler without WithHub fails
+// loudly instead of nil-dereferencing on a real-time push.
+func TestBroadcastFragments_RequiresHub(t *testing.T) {
+	h := NewHandler(t.TempDir())
+	err := h.BroadcastFragments("room-1", []Fragment{{ID: "count", Action: FragmentReplace}})
+	assert.Error(t, err)
+}
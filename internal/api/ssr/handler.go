@@ -6,14 +6,32 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"connex/internal/api/websocket"
+	"connex/internal/middleware"
 	"connex/pkg/logger"
 
 	"go.uber.org/zap"
 )
 
+// templateFuncs are available to every template RenderTemplate/RenderSPA
+// parses.
+var templateFuncs = template.FuncMap{
+	"nonceAttr": nonceAttr,
+}
+
+// nonceAttr renders a `nonce="..."` attribute for an inline <script> or
+// <style> tag, so its nonce matches the one SecurityHeadersMiddleware put in
+// the Content-Security-Policy header. Use as e.g.
+// `<script {{nonceAttr .CSPNonce}}>`.
+func nonceAttr(nonce string) template.HTMLAttr {
+	return template.HTMLAttr(fmt.Sprintf(`nonce="%s"`, nonce))
+}
+
 // SSRData represents data that can be injected into SSR templates
 type SSRData struct {
 	Title       string                 `json:"title"`
@@ -22,6 +40,11 @@ type SSRData struct {
 	Meta        map[string]interface{} `json:"meta,omitempty"`
 	State       map[string]interface{} `json:"state,omitempty"`
 	Config      map[string]interface{} `json:"config,omitempty"`
+
+	// CSRFToken is populated by buildTemplateData from the request's CSRF
+	// token (see middleware.CSRFTokenFromContext) when the Handler was built
+	// with WithCSRF; callers don't need to set it themselves.
+	CSRFToken string `json:"csrfToken,omitempty"`
 }
 
 // Handler handles server-side rendering
@@ -29,22 +52,72 @@ type Handler struct {
 	templates map[string]*template.Template
 	logger    *logger.Logger
 	basePath  string
+
+	// hub broadcasts fragment updates to WebSocket subscribers; nil until
+	// WithHub is called, in which case BroadcastFragments errors instead of
+	// panicking.
+	hub *websocket.Hub
+
+	// csrfEnabled gates CSRFToken population; set via WithCSRF.
+	csrfEnabled bool
+}
+
+// Option configures optional Handler behavior at construction time.
+type Option func(*Handler)
+
+// WithCSRF enables CSRFToken propagation: buildTemplateData reads the
+// request's CSRF token (see middleware.CSRFTokenFromContext) and surfaces it
+// on SSRData.CSRFToken, in StateJSON, and as a rendered <meta
+// name="csrf-token"> tag, for pages served behind middleware.CSRFMiddleware.
+func WithCSRF() Option {
+	return func(h *Handler) { h.csrfEnabled = true }
 }
 
 // NewHandler creates a new SSR handler
-func NewHandler(templatePath string) *Handler {
-	return &Handler{
+func NewHandler(templatePath string, opts ...Option) *Handler {
+	h := &Handler{
 		templates: make(map[string]*template.Template),
 		logger:    logger.GetGlobal(),
 		basePath:  templatePath,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// WithHub attaches the WebSocket hub BroadcastFragments pushes fragment
+// updates through, mirroring auth.Handler's WithSessions/WithBreach
+// late-binding convention for optional dependencies.
+func (h *Handler) WithHub(hub *websocket.Hub) *Handler {
+	h.hub = hub
+	return h
+}
+
+// fragmentActionPattern matches the `{{fragment "id"}}` template action and
+// rewrites it to the stdlib `{{block "fragment:id" .}}` action before
+// parsing. html/template has no way to register new control-flow actions
+// via FuncMap, but block already does exactly what a named, independently
+// renderable region needs: it both defines a named template and renders it
+// inline where declared, so "fragment" is sugar over it rather than a
+// separate mechanism. RenderFragment later renders "fragment:id" directly
+// via ExecuteTemplate to produce it outside a full-page render.
+var fragmentActionPattern = regexp.MustCompile(`\{\{\s*fragment\s+"([^"]+)"\s*\}\}`)
+
+func expandFragmentActions(src []byte) []byte {
+	return fragmentActionPattern.ReplaceAll(src, []byte(`{{block "fragment:$1" .}}`))
 }
 
 // LoadTemplate loads and caches a template
 func (h *Handler) LoadTemplate(name string) error {
 	templatePath := filepath.Join(h.basePath, name+".html")
 
-	tmpl, err := template.ParseFiles(templatePath)
+	raw, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(name + ".html").Funcs(templateFuncs).Parse(string(expandFragmentActions(raw)))
 	if err != nil {
 		return fmt.Errorf("failed to parse template %s: %w", name, err)
 	}
@@ -53,8 +126,12 @@ func (h *Handler) LoadTemplate(name string) error {
 	return nil
 }
 
-// RenderTemplate renders a template with data
-func (h *Handler) RenderTemplate(w http.ResponseWriter, name string, data SSRData) error {
+// RenderTemplate renders a template with data. r supplies the per-request
+// CSP nonce SecurityHeadersMiddleware stashed on its context (see
+// middleware.NonceFromContext); templates can use it directly via
+// `.CSPNonce`/`nonceAttr`, and the auto-generated hydration script (see
+// buildStateScript) always carries it.
+func (h *Handler) RenderTemplate(w http.ResponseWriter, r *http.Request, name string, data SSRData) error {
 	tmpl, exists := h.templates[name]
 	if !exists {
 		// Try to load template if not cached
@@ -64,21 +141,9 @@ func (h *Handler) RenderTemplate(w http.ResponseWriter, name string, data SSRDat
 		tmpl = h.templates[name]
 	}
 
-	// Convert data to JSON for client-side hydration
-	stateJSON, err := json.Marshal(data)
+	templateData, err := h.buildTemplateData(r, data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
-	}
-
-	// Add state to template data
-	templateData := map[string]interface{}{
-		"Title":       data.Title,
-		"Description": data.Description,
-		"User":        data.User,
-		"Meta":        data.Meta,
-		"State":       data.State,
-		"Config":      data.Config,
-		"StateJSON":   template.JS(string(stateJSON)),
+		return err
 	}
 
 	// Render template
@@ -102,28 +167,20 @@ func (h *Handler) RenderTemplate(w http.ResponseWriter, name string, data SSRDat
 func (h *Handler) RenderSPA(w http.ResponseWriter, r *http.Request, data SSRData) error {
 	// Read the base HTML template
 	indexPath := filepath.Join(h.basePath, "index.html")
-	tmpl, err := template.ParseFiles(indexPath)
+	raw, err := os.ReadFile(indexPath)
 	if err != nil {
-		return fmt.Errorf("failed to parse index template: %w", err)
+		return fmt.Errorf("failed to read index template: %w", err)
 	}
-
-	// Convert data to JSON for client-side hydration
-	stateJSON, err := json.Marshal(data)
+	tmpl, err := template.New("index.html").Funcs(templateFuncs).Parse(string(expandFragmentActions(raw)))
 	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
+		return fmt.Errorf("failed to parse index template: %w", err)
 	}
 
-	// Prepare template data
-	templateData := map[string]interface{}{
-		"Title":       data.Title,
-		"Description": data.Description,
-		"User":        data.User,
-		"Meta":        data.Meta,
-		"State":       data.State,
-		"Config":      data.Config,
-		"StateJSON":   template.JS(string(stateJSON)),
-		"Path":        r.URL.Path,
+	templateData, err := h.buildTemplateData(r, data)
+	if err != nil {
+		return err
 	}
+	templateData["Path"] = r.URL.Path
 
 	// Render template
 	var buf bytes.Buffer
@@ -142,6 +199,62 @@ func (h *Handler) RenderSPA(w http.ResponseWriter, r *http.Request, data SSRData
 	return err
 }
 
+// buildTemplateData assembles the data common to RenderTemplate/RenderSPA:
+// the SSRData fields, the request's CSP nonce (under "CSPNonce"), a
+// ready-to-emit hydration <script> tag (under "StateScript") that embeds
+// StateJSON with that same nonce, and - when the Handler was built with
+// WithCSRF - the request's CSRF token (under "CSRFToken", and rendered as a
+// <meta> tag under "CSRFMeta"), so callers don't need to hand-wire
+// `nonceAttr`/middleware.CSRFTokenFromContext just to get safe hydration.
+func (h *Handler) buildTemplateData(r *http.Request, data SSRData) (map[string]interface{}, error) {
+	if h.csrfEnabled {
+		data.CSRFToken = middleware.CSRFTokenFromContext(r)
+	}
+
+	stateJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	nonce, _ := middleware.NonceFromContext(r.Context())
+
+	return map[string]interface{}{
+		"Title":       data.Title,
+		"Description": data.Description,
+		"User":        data.User,
+		"Meta":        data.Meta,
+		"State":       data.State,
+		"Config":      data.Config,
+		"CSPNonce":    nonce,
+		"StateJSON":   template.JS(string(stateJSON)),
+		"StateScript": buildStateScript(nonce, stateJSON),
+		"CSRFToken":   data.CSRFToken,
+		"CSRFMeta":    buildCSRFMeta(data.CSRFToken),
+	}, nil
+}
+
+// buildStateScript renders the full client-side hydration <script> tag,
+// embedding stateJSON with the given CSP nonce so it satisfies
+// SecurityHeadersMiddleware's script-src without 'unsafe-inline'.
+func buildStateScript(nonce string, stateJSON []byte) template.HTML {
+	return template.HTML(fmt.Sprintf(
+		`<script %s>window.__SSR_STATE__ = %s;</script>`,
+		nonceAttr(nonce), stateJSON,
+	))
+}
+
+// buildCSRFMeta renders a `<meta name="csrf-token" content="...">` tag for
+// the base template to place in <head>, so client-side fetch() calls can
+// read the token without parsing StateJSON. Empty when token is "" (CSRF not
+// enabled on this Handler, or the request didn't pass through
+// CSRFMiddleware).
+func buildCSRFMeta(token string) template.HTML {
+	if token == "" {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<meta name="csrf-token" content="%s">`, template.HTMLEscapeString(token)))
+}
+
 // Middleware creates SSR middleware for route-specific rendering
 func (h *Handler) Middleware(routeData map[string]SSRData) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
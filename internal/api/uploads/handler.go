@@ -0,0 +1,133 @@
+// Package uploads exposes the presigned-upload REST endpoints backing
+// internal/storage: a client requests a presigned URL, uploads directly to
+// the object store, then confirms completion so the server can verify the
+// object landed and hand it off to job.EnqueueDataProcess.
+package uploads
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"connex/internal/api/middleware"
+	"connex/internal/job"
+	"connex/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// presignExpiry bounds how long a presigned upload URL is valid for.
+const presignExpiry = 15 * time.Minute
+
+// Handler serves /uploads/presign and /uploads/complete.
+type Handler struct {
+	logger  *zap.Logger
+	storage *storage.Client
+	uploads storage.UploadStore
+}
+
+// NewHandler builds a Handler serving presigned uploads against client and
+// recording them in uploads.
+func NewHandler(logger *zap.Logger, client *storage.Client, uploads storage.UploadStore) *Handler {
+	return &Handler{logger: logger, storage: client, uploads: uploads}
+}
+
+// RegisterRoutes registers upload routes. Callers should mount r behind
+// auth.AuthMiddleware - both handlers require an authenticated user ID in
+// context.
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/presign", h.presign)
+	r.Post("/complete", h.complete)
+}
+
+type presignRequest struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+}
+
+type presignResponse struct {
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+}
+
+func (h *Handler) presign(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		middleware.WriteAuthenticationError(w, "missing user")
+		return
+	}
+
+	var req presignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Key == "" {
+		middleware.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	url, fields, err := h.storage.PresignPut(r.Context(), req.Key, req.ContentType, presignExpiry)
+	if err != nil {
+		h.logger.Error("failed to presign upload", zap.String("key", req.Key), zap.Error(err))
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to presign upload")
+		return
+	}
+
+	if _, err := h.uploads.Create(r.Context(), userID, req.Key, req.ContentType); err != nil {
+		h.logger.Error("failed to record pending upload", zap.String("key", req.Key), zap.Error(err))
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to record upload")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presignResponse{URL: url, Fields: fields})
+}
+
+type completeRequest struct {
+	Key string `json:"key"`
+}
+
+// complete verifies key actually exists in the bucket before transitioning
+// its upload record to ready and enqueuing downstream processing (e.g.
+// thumbnailing, virus scan), so a client can't fake completion for an
+// object that was never uploaded. It also checks the caller owns the
+// upload record for key, so one user can't complete (and trigger
+// processing for) another user's pending upload.
+func (h *Handler) complete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		middleware.WriteAuthenticationError(w, "missing user")
+		return
+	}
+
+	var req completeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Key == "" {
+		middleware.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	upload, err := h.uploads.Get(r.Context(), req.Key)
+	if err != nil {
+		middleware.WriteError(w, http.StatusNotFound, "upload not found")
+		return
+	}
+	if upload.UserID != userID {
+		middleware.WriteAuthorizationError(w, "upload belongs to another user")
+		return
+	}
+
+	if _, err := h.storage.StatObject(r.Context(), req.Key); err != nil {
+		middleware.WriteError(w, http.StatusNotFound, "object not found")
+		return
+	}
+
+	if err := h.uploads.MarkReady(r.Context(), req.Key); err != nil {
+		h.logger.Error("failed to mark upload ready", zap.String("key", req.Key), zap.Error(err))
+		middleware.WriteError(w, http.StatusInternalServerError, "failed to complete upload")
+		return
+	}
+
+	if err := job.EnqueueDataProcess(job.DataProcessPayload{DataID: req.Key, Process: "upload_complete"}); err != nil {
+		h.logger.Warn("failed to enqueue post-upload processing", zap.String("key", req.Key), zap.Error(err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
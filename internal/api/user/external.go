@@ -0,0 +1,104 @@
+package user
+
+import "context"
+
+// DirectoryEntry is one record from an external identity directory, as
+// reported by an ExternalAuthProvider. It carries enough information for
+// UserService to provision or reconcile a local User without the directory
+// needing to know anything about our schema.
+type DirectoryEntry struct {
+	// ExternalID is the provider's stable identifier for this entry (e.g. an
+	// LDAP objectGUID or uid), used instead of Email to key reconciliation
+	// since email addresses can change.
+	ExternalID string
+	Name       string
+	Email      string
+	Groups     []string
+}
+
+// ExternalAuthProvider authenticates credentials against, and periodically
+// syncs users from, an external identity source (e.g. an LDAP directory).
+// It is a distinct concern from auth.Backend (internal/api/auth/backend):
+// a Backend authenticates an interactive login request, while an
+// ExternalAuthProvider additionally owns provisioning and directory sync for
+// UserService.
+type ExternalAuthProvider interface {
+	// Name identifies the provider and is stored on User.AuthSource for
+	// accounts it provisions, e.g. "ldap".
+	Name() string
+
+	// Authenticate verifies username/password against the external source
+	// and returns the corresponding directory entry on success.
+	Authenticate(ctx context.Context, username, password string) (*DirectoryEntry, error)
+
+	// Sync lists every entry currently visible in the external source, for
+	// reconciliation against local users provisioned by this provider.
+	Sync(ctx context.Context) ([]*DirectoryEntry, error)
+}
+
+// SyncResult reports the outcome of reconciling UserService's local users
+// against an ExternalAuthProvider's directory.
+type SyncResult struct {
+	Created int
+	Updated int
+	Removed int
+}
+
+// SyncExternal reconciles the local users provisioned by provider against
+// its current directory contents: entries not yet known are created,
+// entries whose name/email changed are updated, and local users previously
+// provisioned by provider that no longer appear in the directory are
+// soft-deleted.
+func SyncExternal(ctx context.Context, svc Service, provider ExternalAuthProvider) (SyncResult, error) {
+	var result SyncResult
+
+	entries, err := provider.Sync(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.ExternalID] = true
+
+		existing, err := svc.GetByExternalID(ctx, provider.Name(), entry.ExternalID)
+		if err != nil {
+			u := &User{
+				Name:       entry.Name,
+				Email:      entry.Email,
+				AuthSource: provider.Name(),
+				ExternalID: entry.ExternalID,
+			}
+			if _, err := svc.Create(ctx, u); err != nil {
+				return result, err
+			}
+			result.Created++
+			continue
+		}
+
+		if existing.Name != entry.Name || existing.Email != entry.Email {
+			existing.Name = entry.Name
+			existing.Email = entry.Email
+			if _, err := svc.Update(ctx, existing); err != nil {
+				return result, err
+			}
+			result.Updated++
+		}
+	}
+
+	locals, err := svc.ListExternal(ctx, provider.Name())
+	if err != nil {
+		return result, err
+	}
+	for _, u := range locals {
+		if seen[u.ExternalID] {
+			continue
+		}
+		if err := svc.SoftDelete(ctx, u.ID); err != nil {
+			return result, err
+		}
+		result.Removed++
+	}
+
+	return result, nil
+}
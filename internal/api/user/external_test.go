@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is an in-memory ExternalAuthProvider for SyncExternal tests.
+type fakeProvider struct {
+	entries []*DirectoryEntry
+}
+
+func (f *fakeProvider) Name() string { return "ldap" }
+func (f *fakeProvider) Authenticate(ctx context.Context, username, password string) (*DirectoryEntry, error) {
+	return nil, nil
+}
+func (f *fakeProvider) Sync(ctx context.Context) ([]*DirectoryEntry, error) {
+	return f.entries, nil
+}
+
+func TestSyncExternal_CreatesUpdatesAndRemoves(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	service := newTestService(mockDB)
+	provider := &fakeProvider{
+		entries: []*DirectoryEntry{
+			{ExternalID: "new-1", Name: "New Person", Email: "new@example.com"},
+			{ExternalID: "existing-1", Name: "Updated Name", Email: "existing@example.com"},
+		},
+	}
+
+	// new-1: not found locally -> Create.
+	mock.ExpectQuery("SELECT.*FROM users.*auth_source.*external_id").
+		WithArgs("ldap", "new-1").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("INSERT INTO users").
+		WithArgs("New Person", "new@example.com", "ldap", "new-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow(10, time.Now(), time.Now()))
+
+	// existing-1: found with a stale name -> Update.
+	mock.ExpectQuery("SELECT.*FROM users.*auth_source.*external_id").
+		WithArgs("ldap", "existing-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password_hash", "auth_source", "external_id", "created_at", "updated_at"}).
+			AddRow(20, "Stale Name", "existing@example.com", "", "ldap", "existing-1", time.Now(), time.Now()))
+	mock.ExpectQuery("UPDATE users").
+		WithArgs("Updated Name", "existing@example.com", int64(20)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).
+			AddRow(time.Now(), time.Now()))
+
+	// ListExternal finds a third local LDAP user no longer in the directory.
+	mock.ExpectQuery("SELECT.*FROM users WHERE auth_source").
+		WithArgs("ldap").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "auth_source", "external_id", "created_at", "updated_at"}).
+			AddRow(20, "Updated Name", "existing@example.com", "ldap", "existing-1", time.Now(), time.Now()).
+			AddRow(30, "Gone Person", "gone@example.com", "ldap", "gone-1", time.Now(), time.Now()))
+	mock.ExpectExec("UPDATE users SET deleted_at").
+		WithArgs(int64(30)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, err := SyncExternal(context.Background(), service, provider)
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{Created: 1, Updated: 1, Removed: 1}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncExternal_NoChanges(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	service := newTestService(mockDB)
+	provider := &fakeProvider{
+		entries: []*DirectoryEntry{
+			{ExternalID: "existing-1", Name: "Same Name", Email: "existing@example.com"},
+		},
+	}
+
+	mock.ExpectQuery("SELECT.*FROM users.*auth_source.*external_id").
+		WithArgs("ldap", "existing-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password_hash", "auth_source", "external_id", "created_at", "updated_at"}).
+			AddRow(20, "Same Name", "existing@example.com", "", "ldap", "existing-1", time.Now(), time.Now()))
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE auth_source").
+		WithArgs("ldap").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "auth_source", "external_id", "created_at", "updated_at"}).
+			AddRow(20, "Same Name", "existing@example.com", "ldap", "existing-1", time.Now(), time.Now()))
+
+	result, err := SyncExternal(context.Background(), service, provider)
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
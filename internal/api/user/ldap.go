@@ -0,0 +1,122 @@
+package user
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"connex/internal/config"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPProvider is an ExternalAuthProvider backed by an LDAP/AD directory. It
+// authenticates with a search-then-bind flow (bind as the configured service
+// account, search for the user, then bind as the found DN to verify the
+// supplied password) and syncs the full SearchBase for reconciliation.
+//
+// This is distinct from backend.LDAPBackend, which performs a simpler
+// template-bind for interactive login only and does not provision or sync
+// users.
+type LDAPProvider struct {
+	cfg config.LDAPConfig
+}
+
+// NewLDAPProvider builds an LDAPProvider from cfg.
+func NewLDAPProvider(cfg config.LDAPConfig) *LDAPProvider {
+	return &LDAPProvider{cfg: cfg}
+}
+
+func (p *LDAPProvider) Name() string { return "ldap" }
+
+func (p *LDAPProvider) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(p.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: dial %s: %w", p.cfg.URL, err)
+	}
+	if p.cfg.StartTLS {
+		if err := conn.StartTLS(&tls.Config{ServerName: p.cfg.URL}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap: starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (p *LDAPProvider) attrs() []string {
+	return []string{p.cfg.GUIDAttr, p.cfg.NameAttr, p.cfg.EmailAttr, p.cfg.GroupsAttr}
+}
+
+func (p *LDAPProvider) toEntry(entry *ldap.Entry) *DirectoryEntry {
+	return &DirectoryEntry{
+		ExternalID: entry.GetAttributeValue(p.cfg.GUIDAttr),
+		Name:       entry.GetAttributeValue(p.cfg.NameAttr),
+		Email:      entry.GetAttributeValue(p.cfg.EmailAttr),
+		Groups:     entry.GetAttributeValues(p.cfg.GroupsAttr),
+	}
+}
+
+// Authenticate binds as the service account, searches SearchBase for an
+// entry matching UserFilter, then re-binds as that entry's DN with password
+// to verify it.
+func (p *LDAPProvider) Authenticate(ctx context.Context, username, password string) (*DirectoryEntry, error) {
+	conn, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(p.cfg.BindDN, p.cfg.BindPassword); err != nil {
+		return nil, fmt.Errorf("ldap: service account bind: %w", err)
+	}
+
+	filter := fmt.Sprintf(p.cfg.UserFilter, ldap.EscapeFilter(username))
+	req := ldap.NewSearchRequest(
+		p.cfg.SearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		1, 0, false, filter, p.attrs(), nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: search for %q: %w", username, err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, fmt.Errorf("ldap: no unique entry for %q", username)
+	}
+	userEntry := result.Entries[0]
+
+	if err := conn.Bind(userEntry.DN, password); err != nil {
+		return nil, fmt.Errorf("ldap: invalid credentials for %q: %w", username, err)
+	}
+
+	return p.toEntry(userEntry), nil
+}
+
+// Sync lists every entry under SearchBase matching UserFilter ("%s" treated
+// as a wildcard), for reconciliation by SyncExternal.
+func (p *LDAPProvider) Sync(ctx context.Context) ([]*DirectoryEntry, error) {
+	conn, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(p.cfg.BindDN, p.cfg.BindPassword); err != nil {
+		return nil, fmt.Errorf("ldap: service account bind: %w", err)
+	}
+
+	filter := fmt.Sprintf(p.cfg.UserFilter, "*")
+	req := ldap.NewSearchRequest(
+		p.cfg.SearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, 0, false, filter, p.attrs(), nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: sync search: %w", err)
+	}
+
+	entries := make([]*DirectoryEntry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entries = append(entries, p.toEntry(e))
+	}
+	return entries, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"connex/internal/db"
 )
@@ -15,18 +16,49 @@ type Service interface {
 	Update(ctx context.Context, u *User) (*User, error)
 	Delete(ctx context.Context, id int64) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
+
+	// GetByExternalID looks up a user previously provisioned by the named
+	// ExternalAuthProvider (see User.AuthSource/ExternalID), for directory
+	// sync reconciliation.
+	GetByExternalID(ctx context.Context, authSource, externalID string) (*User, error)
+
+	// UpdatePassword sets a new local password hash. It refuses accounts
+	// with IsExternal() == true: their password is managed by the external
+	// directory, not here.
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+
+	// SoftDelete marks a user deleted (User.DeletedAt) without removing the
+	// row, so directory sync can revoke access for entries that disappear
+	// from the directory while preserving history/foreign keys.
+	SoftDelete(ctx context.Context, id int64) error
+
+	// ListExternal returns every non-deleted user provisioned by the named
+	// ExternalAuthProvider, for directory sync reconciliation.
+	ListExternal(ctx context.Context, authSource string) ([]*User, error)
 }
 
-type UserService struct{}
+// UserService resolves a *sqlx.DB pool per call via DB.Resolve, so it can
+// serve multiple tenants/shards (or, in tests, a sqlmock pool) without
+// depending on package-level global state.
+type UserService struct {
+	DB *db.Manager
+}
 
-func NewService() *UserService {
-	return &UserService{}
+// NewService builds a UserService backed by manager. Callers that only need
+// a single pool can pass a *db.Manager with one pool registered under
+// db.DefaultTenant.
+func NewService(manager *db.Manager) *UserService {
+	return &UserService{DB: manager}
 }
 
 func (s *UserService) Create(ctx context.Context, u *User) (*User, error) {
-	q := `INSERT INTO users (name, email, created_at, updated_at) VALUES ($1, $2, NOW(), NOW()) RETURNING id, created_at, updated_at`
-	db := db.Get()
-	err := db.QueryRowContext(ctx, q, u.Name, u.Email).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `INSERT INTO users (name, email, auth_source, external_id, created_at, updated_at)
+	      VALUES ($1, $2, $3, $4, NOW(), NOW()) RETURNING id, created_at, updated_at`
+	err = pool.QueryRowContext(ctx, q, u.Name, u.Email, u.AuthSource, u.ExternalID).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -34,9 +66,12 @@ func (s *UserService) Create(ctx context.Context, u *User) (*User, error) {
 }
 
 func (s *UserService) List(ctx context.Context) ([]*User, error) {
-	q := `SELECT id, name, email, created_at, updated_at FROM users ORDER BY id`
-	db := db.Get()
-	rows, err := db.QueryxContext(ctx, q)
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT id, name, email, created_at, updated_at FROM users WHERE deleted_at IS NULL ORDER BY id`
+	rows, err := pool.QueryxContext(ctx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -53,10 +88,13 @@ func (s *UserService) List(ctx context.Context) ([]*User, error) {
 }
 
 func (s *UserService) Get(ctx context.Context, id int64) (*User, error) {
-	q := `SELECT id, name, email, created_at, updated_at FROM users WHERE id = $1`
-	db := db.Get()
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT id, name, email, created_at, updated_at FROM users WHERE id = $1 AND deleted_at IS NULL`
 	u := new(User)
-	err := db.QueryRowxContext(ctx, q, id).StructScan(u)
+	err = pool.QueryRowxContext(ctx, q, id).StructScan(u)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errors.New("user not found")
 	}
@@ -67,9 +105,12 @@ func (s *UserService) Get(ctx context.Context, id int64) (*User, error) {
 }
 
 func (s *UserService) Update(ctx context.Context, u *User) (*User, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
 	q := `UPDATE users SET name = $1, email = $2, updated_at = NOW() WHERE id = $3 RETURNING created_at, updated_at`
-	db := db.Get()
-	err := db.QueryRowContext(ctx, q, u.Name, u.Email, u.ID).Scan(&u.CreatedAt, &u.UpdatedAt)
+	err = pool.QueryRowContext(ctx, q, u.Name, u.Email, u.ID).Scan(&u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -77,17 +118,24 @@ func (s *UserService) Update(ctx context.Context, u *User) (*User, error) {
 }
 
 func (s *UserService) Delete(ctx context.Context, id int64) error {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return err
+	}
 	q := `DELETE FROM users WHERE id = $1`
-	db := db.Get()
-	_, err := db.ExecContext(ctx, q, id)
+	_, err = pool.ExecContext(ctx, q, id)
 	return err
 }
 
 func (s *UserService) GetByEmail(ctx context.Context, email string) (*User, error) {
-	q := `SELECT id, name, email, password_hash, created_at, updated_at FROM users WHERE email = $1`
-	db := db.Get()
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT id, name, email, password_hash, auth_source, external_id, created_at, updated_at
+	      FROM users WHERE email = $1 AND deleted_at IS NULL`
 	u := new(User)
-	err := db.QueryRowxContext(ctx, q, email).StructScan(u)
+	err = pool.QueryRowxContext(ctx, q, email).StructScan(u)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errors.New("user not found")
 	}
@@ -96,3 +144,76 @@ func (s *UserService) GetByEmail(ctx context.Context, email string) (*User, erro
 	}
 	return u, nil
 }
+
+// GetByExternalID looks up a user by the (authSource, externalID) pair an
+// ExternalAuthProvider assigned it, e.g. ("ldap", "<objectGUID>").
+func (s *UserService) GetByExternalID(ctx context.Context, authSource, externalID string) (*User, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT id, name, email, password_hash, auth_source, external_id, created_at, updated_at
+	      FROM users WHERE auth_source = $1 AND external_id = $2`
+	u := new(User)
+	err = pool.QueryRowxContext(ctx, q, authSource, externalID).StructScan(u)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UpdatePassword sets a new local password hash, refusing accounts whose
+// password is managed by an external directory (see User.IsExternal).
+func (s *UserService) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	u, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u.IsExternal() {
+		return fmt.Errorf("user %d: password is managed by external auth source %q", id, u.AuthSource)
+	}
+
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = pool.ExecContext(ctx, `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`, passwordHash, id)
+	return err
+}
+
+// SoftDelete marks a user deleted without removing the row.
+func (s *UserService) SoftDelete(ctx context.Context, id int64) error {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = pool.ExecContext(ctx, `UPDATE users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// ListExternal returns every non-deleted user provisioned by authSource.
+func (s *UserService) ListExternal(ctx context.Context, authSource string) ([]*User, error) {
+	pool, err := s.DB.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT id, name, email, auth_source, external_id, created_at, updated_at
+	      FROM users WHERE auth_source = $1 AND deleted_at IS NULL`
+	rows, err := pool.QueryxContext(ctx, q, authSource)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []*User
+	for rows.Next() {
+		u := new(User)
+		if err := rows.StructScan(u); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
@@ -6,17 +6,29 @@ import (
 	"testing"
 	"time"
 
+	"connex/internal/db"
+
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestService wraps a sqlmock-backed *sql.DB in a *db.Manager registered
+// under db.DefaultTenant, so UserService.DB.Resolve(ctx) finds it exactly as
+// it would a real pool.
+func newTestService(mockDB *sql.DB) *UserService {
+	manager := db.NewManager()
+	manager.SetPool(db.DefaultTenant, sqlx.NewDb(mockDB, "sqlmock"))
+	return NewService(manager)
+}
+
 func TestUserService_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
-	service := &UserService{}
+	service := newTestService(db)
 
 	tests := []struct {
 		name    string
@@ -32,7 +44,7 @@ func TestUserService_Create(t *testing.T) {
 			},
 			setup: func() {
 				mock.ExpectQuery("INSERT INTO users").
-					WithArgs("John Doe", "john@example.com").
+					WithArgs("John Doe", "john@example.com", "", "").
 					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 						AddRow(1, time.Now(), time.Now()))
 			},
@@ -46,7 +58,7 @@ func TestUserService_Create(t *testing.T) {
 			},
 			setup: func() {
 				mock.ExpectQuery("INSERT INTO users").
-					WithArgs("John Doe", "john@example.com").
+					WithArgs("John Doe", "john@example.com", "", "").
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -79,7 +91,7 @@ func TestUserService_Get(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	service := &UserService{}
+	service := newTestService(db)
 
 	tests := []struct {
 		name    string
@@ -145,7 +157,7 @@ func TestUserService_GetByEmail(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	service := &UserService{}
+	service := newTestService(db)
 
 	tests := []struct {
 		name    string
@@ -211,7 +223,7 @@ func TestUserService_List(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	service := &UserService{}
+	service := newTestService(db)
 
 	tests := []struct {
 		name    string
@@ -225,7 +237,7 @@ func TestUserService_List(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "name", "email", "password_hash", "created_at", "updated_at"}).
 					AddRow(1, "John Doe", "john@example.com", "hash", time.Now(), time.Now()).
 					AddRow(2, "Jane Doe", "jane@example.com", "hash", time.Now(), time.Now())
-				mock.ExpectQuery("SELECT.*FROM users ORDER BY id").
+				mock.ExpectQuery("SELECT.*FROM users.*ORDER BY id").
 					WillReturnRows(rows)
 			},
 			want: []*User{
@@ -238,7 +250,7 @@ func TestUserService_List(t *testing.T) {
 			name: "empty list",
 			setup: func() {
 				rows := sqlmock.NewRows([]string{"id", "name", "email", "password_hash", "created_at", "updated_at"})
-				mock.ExpectQuery("SELECT.*FROM users ORDER BY id").
+				mock.ExpectQuery("SELECT.*FROM users.*ORDER BY id").
 					WillReturnRows(rows)
 			},
 			want:    []*User{},
@@ -276,7 +288,7 @@ func TestUserService_Update(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	service := &UserService{}
+	service := newTestService(db)
 
 	tests := []struct {
 		name    string
@@ -342,7 +354,7 @@ func TestUserService_Delete(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	service := &UserService{}
+	service := newTestService(db)
 
 	tests := []struct {
 		name    string
@@ -8,12 +8,28 @@ import (
 )
 
 type User struct {
-	ID           int64     `db:"id" json:"id"`
-	Name         string    `db:"name" json:"name"`
-	Email        string    `db:"email" json:"email"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
-	PasswordHash string    `db:"password_hash" json:"-"`
+	ID           int64      `db:"id" json:"id"`
+	Name         string     `db:"name" json:"name"`
+	Email        string     `db:"email" json:"email"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+	PasswordHash string     `db:"password_hash" json:"-"`
+	DeletedAt    *time.Time `db:"deleted_at" json:"-"`
+
+	// AuthSource is "" (or "local") for a user whose password is managed
+	// here, or an ExternalAuthProvider's Name() (e.g. "ldap") for one
+	// provisioned and authenticated externally. ExternalID is that
+	// provider's stable identifier for the entry (e.g. objectGUID/uid),
+	// used by directory sync to reconcile without relying on email, which
+	// can change.
+	AuthSource string `db:"auth_source" json:"-"`
+	ExternalID string `db:"external_id" json:"-"`
+}
+
+// IsExternal reports whether u is provisioned and authenticated by an
+// ExternalAuthProvider rather than a local password.
+func (u *User) IsExternal() bool {
+	return u.AuthSource != "" && u.AuthSource != "local"
 }
 
 // Validate basic user fields for create/update
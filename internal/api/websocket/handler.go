@@ -12,18 +12,20 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
 // Message types for WebSocket communication
 const (
-	MessageTypeChat   = "chat"
-	MessageTypeSystem = "system"
-	MessageTypeAuth   = "auth"
-	MessageTypePing   = "ping"
-	MessageTypePong   = "pong"
-	MessageTypeError  = "error"
+	MessageTypeChat     = "chat"
+	MessageTypeSystem   = "system"
+	MessageTypeAuth     = "auth"
+	MessageTypePing     = "ping"
+	MessageTypePong     = "pong"
+	MessageTypeError    = "error"
+	MessageTypeFragment = "fragment"
 )
 
 // Message represents a WebSocket message
@@ -45,9 +47,17 @@ type Client struct {
 	Hub      *Hub
 	mu       sync.Mutex
 	lastPing time.Time
+
+	// log is the request-scoped logger captured at upgrade time (see
+	// logger.FromContext), carrying the same request_id as the upgrade
+	// request's access log line.
+	log *logger.Logger
 }
 
-// Hub manages all WebSocket connections
+// Hub manages all WebSocket connections. Broadcast only fans a message out
+// to clients connected to this process; publishRemote/subscribe mirror it
+// to every other replica over Redis pub/sub so Hub can sit behind a load
+// balancer with N instances instead of being a single-node chat.
 type Hub struct {
 	clients    map[*Client]bool
 	broadcast  chan *Message
@@ -58,6 +68,11 @@ type Hub struct {
 	jwtSecret  string
 	logger     *logger.Logger
 	mu         sync.RWMutex
+
+	// nodeID tags every envelope this instance publishes to Redis, so its
+	// own subscriber goroutine can recognize and skip messages looping
+	// back from itself instead of double-delivering them.
+	nodeID string
 }
 
 // Handler handles WebSocket connections
@@ -91,6 +106,7 @@ func NewHandler(jwtSecret string, redisClient *redis.Client) *Handler {
 		redis:      redisClient,
 		jwtSecret:  jwtSecret,
 		logger:     logger.GetGlobal(),
+		nodeID:     uuid.NewString(),
 	}
 
 	handler := &Handler{
@@ -100,8 +116,11 @@ func NewHandler(jwtSecret string, redisClient *redis.Client) *Handler {
 		jwtSecret: jwtSecret,
 	}
 
-	// Start the hub
+	// Start the hub and its Redis fan-out. subscribe and presenceJanitor are
+	// both no-ops when redisClient is nil, so Hub still works local-only.
 	go hub.run()
+	go hub.subscribe()
+	go hub.presenceJanitor()
 
 	return handler
 }
@@ -114,10 +133,12 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reqLogger := logger.FromContext(r.Context()).Named("websocket")
+
 	// Upgrade connection
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		h.logger.Error("WebSocket upgrade failed", zap.String("error", err.Error()))
+		reqLogger.Error("WebSocket upgrade failed", zap.String("error", err.Error()))
 		return
 	}
 
@@ -128,6 +149,7 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		Send:     make(chan []byte, 256),
 		Hub:      h.hub,
 		lastPing: time.Now(),
+		log:      reqLogger,
 	}
 
 	// Authenticate client (optional)
@@ -176,7 +198,7 @@ func (h *Handler) checkRateLimit(r *http.Request) bool {
 	ctx := context.Background()
 	count, err := h.hub.redis.Get(ctx, key).Int()
 	if err != nil && err != redis.Nil {
-		h.logger.Error("Rate limit check failed", zap.String("error", err.Error()))
+		logger.FromContext(r.Context()).Error("Rate limit check failed", zap.String("error", err.Error()))
 		return true // Allow if Redis is down
 	}
 
@@ -211,7 +233,10 @@ func (h *Hub) run() {
 			}
 			h.mu.Unlock()
 
-			h.logger.Info("Client connected", zap.String("client_id", client.ID), zap.String("user_id", client.UserID))
+			if client.Room != "" {
+				h.trackPresence(client.Room, client.ID)
+			}
+			client.log.Info("Client connected", zap.String("client_id", client.ID), zap.String("user_id", client.UserID))
 
 		case client := <-h.unregister:
 			h.mu.Lock()
@@ -227,32 +252,43 @@ func (h *Hub) run() {
 			}
 			h.mu.Unlock()
 
-			h.logger.Info("Client disconnected", zap.String("client_id", client.ID), zap.String("user_id", client.UserID))
+			if client.Room != "" {
+				h.untrackPresence(client.Room, client.ID)
+			}
+			client.log.Info("Client disconnected", zap.String("client_id", client.ID), zap.String("user_id", client.UserID))
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
-			if message.Room != "" {
-				// Broadcast to room
-				for client := range h.rooms[message.Room] {
-					select {
-					case client.Send <- h.serializeMessage(message):
-					default:
-						close(client.Send)
-						delete(h.clients, client)
-					}
-				}
-			} else {
-				// Broadcast to all clients
-				for client := range h.clients {
-					select {
-					case client.Send <- h.serializeMessage(message):
-					default:
-						close(client.Send)
-						delete(h.clients, client)
-					}
-				}
+			h.deliverLocal(message)
+			go h.publishRemote(message)
+		}
+	}
+}
+
+// deliverLocal fans message out to clients connected to this process only.
+// It's used both for messages broadcast locally and ones relayed back from
+// Redis pub/sub by another node's Broadcast call.
+func (h *Hub) deliverLocal(message *Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	data := h.serializeMessage(message)
+	if message.Room != "" {
+		for client := range h.rooms[message.Room] {
+			select {
+			case client.Send <- data:
+			default:
+				close(client.Send)
+				delete(h.clients, client)
 			}
-			h.mu.RUnlock()
+		}
+		return
+	}
+	for client := range h.clients {
+		select {
+		case client.Send <- data:
+		default:
+			close(client.Send)
+			delete(h.clients, client)
 		}
 	}
 }
@@ -267,7 +303,8 @@ func (h *Hub) serializeMessage(msg *Message) []byte {
 	return data
 }
 
-// Broadcast sends a message to all clients or a specific room
+// Broadcast sends a message to all clients or a specific room, both on this
+// process and, via Redis pub/sub, on every other connected replica.
 func (h *Hub) Broadcast(msg *Message) {
 	h.broadcast <- msg
 }
@@ -290,7 +327,7 @@ func (c *Client) readPump() {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				c.Hub.logger.Error("WebSocket read error", zap.String("error", err.Error()))
+				c.log.Error("WebSocket read error", zap.String("error", err.Error()))
 			}
 			break
 		}
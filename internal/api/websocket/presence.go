@@ -0,0 +1,132 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// presenceTTL is how long a client's presence entry survives without a
+	// heartbeat refresh. It must be comfortably longer than
+	// presenceHeartbeat so a missed tick or two doesn't flicker a still-
+	// connected client out of Presence/RoomCount.
+	presenceTTL = 45 * time.Second
+
+	// presenceHeartbeat is how often presenceJanitor refreshes every
+	// locally-connected client's presence entry.
+	presenceHeartbeat = 15 * time.Second
+)
+
+// presenceKey is the Redis key backing a single client's presence entry in
+// room. Membership is a TTL'd key rather than a plain SADD'd Set member so
+// a crashed node's entries expire on their own once presenceJanitor stops
+// refreshing them, instead of lingering until someone SREMs them.
+func presenceKey(room, clientID string) string {
+	return "ws:presence:" + room + ":" + clientID
+}
+
+// trackPresence marks client present in room until untrackPresence runs or
+// presenceTTL elapses without a heartbeat refresh.
+func (h *Hub) trackPresence(room, clientID string) {
+	if h.redis == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.redis.Set(ctx, presenceKey(room, clientID), 1, presenceTTL).Err(); err != nil {
+		h.logger.Warn("failed to record presence", zap.String("room", room), zap.String("error", err.Error()))
+	}
+}
+
+// untrackPresence removes client's presence entry from room immediately, on
+// graceful disconnect.
+func (h *Hub) untrackPresence(room, clientID string) {
+	if h.redis == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.redis.Del(ctx, presenceKey(room, clientID)).Err(); err != nil {
+		h.logger.Warn("failed to clear presence", zap.String("room", room), zap.String("error", err.Error()))
+	}
+}
+
+// presenceJanitor periodically refreshes the TTL of every locally-connected
+// client's presence entry, so they keep appearing in Presence/RoomCount
+// until this node disconnects them or crashes, at which point the entries
+// simply expire.
+func (h *Hub) presenceJanitor() {
+	if h.redis == nil {
+		return
+	}
+	ticker := time.NewTicker(presenceHeartbeat)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.mu.RLock()
+		for client := range h.clients {
+			if client.Room != "" {
+				h.trackPresence(client.Room, client.ID)
+			}
+		}
+		h.mu.RUnlock()
+	}
+}
+
+// Presence returns the IDs of clients currently present in room across
+// every node, backed by Redis. If Redis is unavailable it falls back to
+// this process's own locally-connected clients in room.
+func (h *Hub) Presence(room string) ([]string, error) {
+	if h.redis == nil {
+		return h.localPresence(room), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prefix := "ws:presence:" + room + ":"
+	var (
+		clientIDs []string
+		cursor    uint64
+	)
+	for {
+		keys, next, err := h.redis.Scan(ctx, cursor, prefix+"*", 100).Result()
+		if err != nil {
+			h.logger.Warn("presence scan failed, falling back to local clients only",
+				zap.String("room", room), zap.String("error", err.Error()))
+			return h.localPresence(room), nil
+		}
+		for _, key := range keys {
+			clientIDs = append(clientIDs, key[len(prefix):])
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return clientIDs, nil
+}
+
+// RoomCount returns the number of clients currently present in room across
+// every node.
+func (h *Hub) RoomCount(room string) (int, error) {
+	ids, err := h.Presence(room)
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// localPresence is the graceful-degradation fallback Presence and RoomCount
+// use when Redis is unavailable: just this node's own connected clients in
+// room.
+func (h *Hub) localPresence(room string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]string, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		ids = append(ids, client.ID)
+	}
+	return ids
+}
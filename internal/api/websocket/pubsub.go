@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// envelope wraps a Message for Redis pub/sub fan-out. Origin is the
+// publishing Hub's nodeID, which lets that node's own subscriber skip the
+// copy looping back to it, so its local clients don't get it twice. Dedup is
+// a random per-publish ID; subscribe does not read it.
+type envelope struct {
+	Message *Message `json:"message"`
+	Origin  string   `json:"origin"`
+	Dedup   string   `json:"dedup"`
+}
+
+// channelForRoom returns the Redis pub/sub channel a room's messages are
+// published on, or the global channel for a room-less broadcast.
+func channelForRoom(room string) string {
+	if room == "" {
+		return "ws:global"
+	}
+	return "ws:room:" + room
+}
+
+// publishRemote publishes message to Redis so every other node's subscriber
+// goroutine can relay it to its own locally-connected clients. It's
+// best-effort: deliverLocal has already reached this node's clients by the
+// time publishRemote runs, so a Redis outage only degrades delivery to
+// local-only rather than losing the message entirely.
+func (h *Hub) publishRemote(message *Message) {
+	if h.redis == nil {
+		return
+	}
+
+	env := envelope{Message: message, Origin: h.nodeID, Dedup: uuid.NewString()}
+	data, err := json.Marshal(env)
+	if err != nil {
+		h.logger.Error("failed to marshal broadcast envelope", zap.String("error", err.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.redis.Publish(ctx, channelForRoom(message.Room), data).Err(); err != nil {
+		h.logger.Warn("failed to publish broadcast to redis, degrading to local-only delivery",
+			zap.String("error", err.Error()))
+	}
+}
+
+// subscribe relays messages published by other nodes to this node's
+// locally-connected clients, for as long as the process runs. If redis is
+// nil it returns immediately, leaving the hub in local-only mode.
+func (h *Hub) subscribe() {
+	if h.redis == nil {
+		return
+	}
+
+	ctx := context.Background()
+	pubsub := h.redis.Subscribe(ctx, "ws:global")
+	if err := pubsub.PSubscribe(ctx, "ws:room:*"); err != nil {
+		h.logger.Error("failed to subscribe to room broadcast pattern, degrading to local-only delivery",
+			zap.String("error", err.Error()))
+		pubsub.Close()
+		return
+	}
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		var env envelope
+		if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+			h.logger.Error("failed to decode broadcast envelope", zap.String("error", err.Error()))
+			continue
+		}
+		if env.Origin == h.nodeID {
+			continue // this node published it; deliverLocal already reached its clients
+		}
+		h.deliverLocal(env.Message)
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,22 +14,65 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Log      LogConfig
-	Redis    RedisConfig
-	Jobs     JobsConfig
-	OTel     OTelConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Log         LogConfig
+	Redis       RedisConfig
+	Jobs        JobsConfig
+	OTel        OTelConfig
+	CrowdSec    CrowdSecConfig
+	Security    SecurityConfig
+	Session     SessionConfig
+	MTLS        MTLSConfig
+	Password    PasswordConfig
+	Breach      BreachConfig
+	Compression CompressionConfig
+	OIDC        OIDCConfig
+	LDAP        LDAPConfig
+	Storage     StorageConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port         string
 	Host         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	ReadTimeout  Duration
+	WriteTimeout Duration
+	IdleTimeout  Duration
+	TLS          TLSConfig
+}
+
+// TLSConfig selects how the HTTP server terminates TLS in-process (as
+// opposed to relying on a fronting load balancer/proxy to do it).
+type TLSConfig struct {
+	// Mode is "off" (plain HTTP, the default), "manual" (serve CertFile/
+	// KeyFile via ListenAndServeTLS), or "auto" (provision certificates on
+	// demand via ACME/autocert.Manager; also starts an HTTP-01 challenge
+	// listener on :80 that redirects everything else to HTTPS).
+	Mode     string
+	CertFile string
+	KeyFile  string
+	AutoTLS  AutoTLSConfig
+}
+
+// AutoTLSConfig configures autocert.Manager for TLS.Mode == "auto".
+type AutoTLSConfig struct {
+	// Hosts is the allow-list autocert.HostWhitelist restricts certificate
+	// requests to, so a request for an arbitrary SNI hostname can't make the
+	// server request (and rate-limit itself against) a cert for it.
+	Hosts []string
+
+	// CacheDir is where autocert.DirCache persists issued certificates
+	// across restarts.
+	CacheDir string
+
+	// Email is passed to the ACME CA for expiry/revocation notices.
+	Email string
+
+	// DirectoryURL, if set, points autocert at a private ACME CA (e.g.
+	// step-ca, Pebble) instead of Let's Encrypt's production directory.
+	DirectoryURL string
 }
 
 // DatabaseConfig holds database-related configuration
@@ -38,12 +84,247 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// PasswordRef, when non-nil, is the live handle Password was resolved
+	// from (set when DB_PASSWORD_REF is configured instead of DB_PASSWORD -
+	// see resolveSecretRefs). db.Manager doesn't yet reconnect on rotation,
+	// so today this only lets a future caller read the current value; it
+	// doesn't by itself make an existing connection pool pick up a changed
+	// password.
+	PasswordRef *SecretRef
 }
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
-	Secret     string
-	Expiration time.Duration
+	Secret string
+
+	// Expiration is the access token TTL minted by the legacy Register/Login
+	// flow (see pkg/jwt.GenerateJWT). RefreshExpiration is the TTL of its
+	// paired refresh token (see pkg/jwt.GenerateRefreshJWT), which is rotated
+	// on every use via POST /api/auth/refresh.
+	Expiration        Duration
+	RefreshExpiration Duration
+
+	// Issuer and Audience are stamped into the `iss`/`aud` claims of tokens
+	// minted by the OAuth2 authorization server (see internal/api/auth).
+	Issuer   string
+	Audience string
+
+	// SigningKeySeed is a base64-encoded 32-byte Ed25519 seed used to derive
+	// the active signing key for the OAuth2 token/JWKS endpoints. SigningKeyID
+	// becomes that key's `kid`. Secret above remains the HS256 secret used by
+	// the legacy Register/Login JWTs so both flows can coexist during rollout.
+	SigningKeySeed string
+	SigningKeyID   string
+
+	// SecretRef, when non-nil, is the live handle Secret was resolved from
+	// (set when JWT_SECRET_REF is configured instead of JWT_SECRET - see
+	// resolveSecretRefs). auth.AuthMiddleware reads SecretRef.Load() when
+	// it's set, falling back to the Secret snapshot above otherwise, so a
+	// Vault/AWSSM-backed secret can rotate without a restart.
+	SecretRef *SecretRef
+}
+
+// SessionConfig holds configuration for server-side session tracking (see
+// auth.SessionStore), layered on top of the stateless JWTs JWTConfig signs.
+type SessionConfig struct {
+	// TokenIdleTimeout is the sliding window a session stays valid for
+	// without activity; AuthMiddleware's Touch call refreshes it on every
+	// authenticated request.
+	TokenIdleTimeout Duration
+
+	// AbsoluteTokenLifetime is the hard cap on a session's age regardless
+	// of activity, after which it's treated as expired even if it was
+	// touched a second ago.
+	AbsoluteTokenLifetime Duration
+
+	// EnableMultiLogin, when false, makes a new login revoke every other
+	// session the same user already has, so only one device can be logged
+	// in at a time.
+	EnableMultiLogin bool
+}
+
+// MTLSConfig holds configuration for client-certificate authentication (see
+// auth.CertAuthMiddleware).
+type MTLSConfig struct {
+	// Enabled turns on client-certificate verification at the HTTP server
+	// level (tls.Config.ClientAuth). Individual routes still choose "jwt",
+	// "cert", or "either" via auth.AuthMode.
+	Enabled bool
+
+	// CAFile is a PEM bundle of CAs trusted to sign client certificates.
+	CAFile string
+
+	// OUAllowlist, if non-empty, restricts authentication to certificates
+	// whose Subject.OrganizationalUnit contains one of these values.
+	OUAllowlist []string
+
+	// CRLURL, if set, is fetched periodically (every CRLRefresh) and
+	// checked for the presented certificate's serial number.
+	CRLURL     string
+	CRLRefresh Duration
+
+	// OCSPEnabled turns on a live OCSP responder check (from the
+	// certificate's AuthorityInfoAccess) in addition to/instead of CRLURL.
+	OCSPEnabled bool
+}
+
+// PasswordConfig holds configuration for password hashing (see
+// pkg/password).
+type PasswordConfig struct {
+	// Pepper is mixed into every password alongside its per-hash salt before
+	// hashing/verifying. Unlike the salt, it isn't stored in the database,
+	// so a stolen password table alone isn't enough to brute-force it.
+	// Rotating it invalidates every existing hash, so treat it like
+	// JWT.Secret: set once, keep it out of version control.
+	Pepper string
+}
+
+// BreachConfig holds configuration for the HaveIBeenPwned Pwned Passwords
+// breach check performed during registration (see pkg/security/breach).
+type BreachConfig struct {
+	// Enabled turns the check on. It's opt-in since it calls out to a
+	// third-party API on every registration.
+	Enabled bool
+
+	// Threshold is the minimum HIBP breach count to reject a password for.
+	Threshold int
+
+	// CacheTTL is how long a prefix's HIBP response is cached in Redis.
+	CacheTTL Duration
+
+	// Timeout bounds each HIBP HTTP request.
+	Timeout Duration
+}
+
+// CompressionConfig controls custommiddleware.CompressionMiddleware.
+type CompressionConfig struct {
+	// Enabled turns response compression on globally.
+	Enabled bool
+
+	// Level is the gzip/brotli compression level. For gzip this is in
+	// [-2, 9] (see compress/gzip); for brotli it's in [0, 11].
+	Level int
+
+	// MinSizeBytes is the minimum response body size eligible for
+	// compression; bodies smaller than this aren't worth the CPU.
+	MinSizeBytes int
+
+	// ContentTypes is the allow-list of response Content-Types eligible for
+	// compression (exact match, or "prefix/*" for a whole subtype family).
+	// Anything already compressed (images, video, archives) should be left
+	// off this list rather than recompressed.
+	ContentTypes []string
+
+	// Brotli enables negotiating "br" in addition to gzip when the client's
+	// Accept-Encoding allows it.
+	Brotli bool
+}
+
+// OIDCConfig holds configuration for the federated login providers wired
+// into auth.Handler.Backends alongside local username/password (see
+// internal/api/auth/backend). A provider with APIBearerEnabled is also
+// usable to authenticate plain API requests (see
+// OIDCProviderConfig.APIBearerEnabled and Config.OIDCEnabled); there's no
+// separate JWKS-refresh-interval knob because authn.OIDCAuthenticator's
+// underlying go-oidc verifier already fetches and caches signing keys per
+// verification call.
+type OIDCConfig struct {
+	Keycloak OIDCProviderConfig
+	Google   OIDCProviderConfig
+	GitHub   OIDCProviderConfig
+}
+
+// OIDCProviderConfig configures one federated login provider.
+type OIDCProviderConfig struct {
+	// Enabled registers this provider's backend and its
+	// /api/auth/{name}/login + /callback routes.
+	Enabled bool
+
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL must match the callback URL registered with the provider,
+	// e.g. "https://app.example.com/api/auth/keycloak/callback".
+	RedirectURL string
+
+	// IssuerURL is the OIDC discovery issuer, e.g.
+	// "https://idp.example.com/realms/connex" for Keycloak. Unused for
+	// Google (hardcoded to https://accounts.google.com) and GitHub (not an
+	// OIDC provider).
+	IssuerURL string
+
+	// Scopes requested at the provider; "openid" is added automatically for
+	// OIDC providers if not already present.
+	Scopes []string
+
+	// APIBearerEnabled additionally lets this provider's access/ID tokens
+	// authenticate ordinary API requests through auth.AuthMiddleware (via
+	// authn.OIDCAuthenticator), alongside the redirect login flow Enabled
+	// already gates - so a Keycloak/Auth0/Dex-issued token works as a
+	// Bearer credential without retiring the local JWT login flow. Only
+	// meaningful when IssuerURL is set (Google/GitHub don't expose one).
+	APIBearerEnabled bool
+
+	// AllowedAudiences widens API Bearer token acceptance beyond ClientID:
+	// a token is accepted if its aud claim contains ClientID or any of
+	// these, for realms that mint one token shared across several
+	// audience-mapped clients. Only consulted when APIBearerEnabled.
+	AllowedAudiences []string
+
+	// UsernameClaim names the ID token claim used as the JIT-provisioned
+	// (or looked-up) local user's display name. Defaults to "name".
+	UsernameClaim string
+
+	// GroupsClaim, if set, names the ID token claim (a string array)
+	// copied onto the request's roles, the same roles AuthMiddleware
+	// already populates from a local token's `roles` claim - so
+	// authorization middleware doesn't need to know whether a request was
+	// authenticated locally or via this provider. Unset means OIDC
+	// principals carry no roles.
+	GroupsClaim string
+}
+
+// LDAPConfig configures user.LDAPProvider: simple-bind authentication
+// against an LDAP/AD server plus periodic directory sync into the local
+// users table.
+type LDAPConfig struct {
+	// Enabled registers the provider with UserService and starts the
+	// background sync loop at SyncInterval.
+	Enabled bool
+
+	// URL is passed to ldap.DialURL, e.g. "ldap://dc.example.com:389" or
+	// "ldaps://dc.example.com:636".
+	URL string
+
+	// BindDN/BindPassword authenticate the service account used for
+	// directory search (both the login search-then-bind flow and Sync).
+	BindDN       string
+	BindPassword string
+
+	// SearchBase is the subtree directory searches are scoped to, e.g.
+	// "ou=people,dc=example,dc=com".
+	SearchBase string
+
+	// UserFilter is an LDAP filter template with one %s placeholder for the
+	// ldap.EscapeFilter-escaped username/email, e.g.
+	// "(&(objectClass=person)(uid=%s))".
+	UserFilter string
+
+	// NameAttr/EmailAttr/GUIDAttr/GroupsAttr map directory attributes onto
+	// user.DirectoryEntry fields. GUIDAttr is typically "objectGUID" (AD)
+	// or "uid" (OpenLDAP) and is stored as User.ExternalID.
+	NameAttr   string
+	EmailAttr  string
+	GUIDAttr   string
+	GroupsAttr string
+
+	// StartTLS upgrades a plain "ldap://" connection before binding.
+	StartTLS bool
+
+	// SyncInterval is how often the background sync loop reconciles the
+	// local users table against the directory; see user.LDAPProvider.Sync.
+	SyncInterval Duration
 }
 
 // LogConfig holds logging-related configuration
@@ -59,12 +340,95 @@ type RedisConfig struct {
 	Port     string
 	Password string
 	DB       int
+
+	// PasswordRef, when non-nil, is the live handle Password was resolved
+	// from (set when REDIS_PASSWORD_REF is configured instead of
+	// REDIS_PASSWORD - see resolveSecretRefs).
+	PasswordRef *SecretRef
 }
 
 // JobsConfig holds background job configuration
 type JobsConfig struct {
+	// Concurrency is the asynq.Server's total worker pool size, shared
+	// across every queue in Queues per their relative Priority weighting.
 	Concurrency int
-	Queues      []string
+
+	// Queues configures each named asynq queue's priority weight and
+	// retry/dead-letter behavior. Defaults to the three queues connex has
+	// always run (critical/default/low); override wholesale via
+	// JOBS_QUEUES_JSON, e.g.
+	// `{"critical":{"priority":10,"max_retries":25},...}`.
+	Queues map[string]QueueConfig
+
+	// Schedules seeds job.InitScheduler's periodic task registrations at
+	// startup. It's deliberately not env-driven beyond this fixed list -
+	// schedules created/edited at runtime via /admin/jobs/schedules live in
+	// Postgres (see job.PostgresScheduleStore) and are synced in
+	// independently of this slice.
+	Schedules []ScheduleConfig
+}
+
+// QueueConfig configures one asynq queue.
+type QueueConfig struct {
+	// Priority is asynq's relative processing weight for this queue among
+	// all of JobsConfig.Queues (see asynq.Config.Queues) - higher is
+	// serviced more often relative to the others. It is not a hard
+	// concurrency cap.
+	Priority int `json:"priority"`
+
+	// Concurrency documents this queue's intended worker share. asynq
+	// enforces only the relative Priority weighting above within one
+	// server, not a hard per-queue concurrency limit, so job.Init doesn't
+	// currently read this field; it's recorded here so the intended sizing
+	// travels with the rest of the queue's settings for when that changes
+	// (e.g. a dedicated asynq.Server per high-isolation queue).
+	Concurrency int `json:"concurrency"`
+
+	// MaxRetries is the default asynq.MaxRetry applied to tasks enqueued
+	// onto this queue that don't specify their own (see job.QueueOptions).
+	MaxRetries int `json:"max_retries"`
+
+	// RetryBackoff is intended as this queue's default delay before
+	// retrying a failed task. asynq's RetryDelayFunc hook (the only place
+	// that controls retry timing) isn't handed which queue the failing
+	// task came from, only the task itself, so job.Init can't honor a
+	// per-queue value yet; it's recorded here for when that's available
+	// (e.g. by tagging the task payload with its queue at enqueue time).
+	RetryBackoff Duration `json:"retry_backoff"`
+
+	// DeadLetter, if set, is another queue name (a key of JobsConfig.Queues)
+	// a copy of this queue's tasks is enqueued onto once they've exhausted
+	// MaxRetries; asynq still archives the original in this queue.
+	DeadLetter string `json:"dead_letter"`
+}
+
+// ScheduleConfig defines one periodic task for job.InitScheduler to
+// register with asynq's Scheduler.
+type ScheduleConfig struct {
+	// Spec is a cron expression (e.g. "0 * * * *"), per robfig/cron syntax.
+	Spec string
+	// TaskType is one of the job.Type* constants.
+	TaskType string
+	// Payload is the JSON-encoded task payload, matching TaskType's payload
+	// struct (e.g. job.EmailPayload).
+	Payload string
+	// Queue routes the task onto a non-default asynq queue; empty uses the
+	// broker's default queue.
+	Queue string
+	// Retention keeps the task's execution result around for inspection
+	// after it completes; zero uses asynq's default.
+	Retention Duration
+}
+
+// StorageConfig configures storage.Client's connection to an S3-compatible
+// object storage backend (MinIO or AWS S3).
+type StorageConfig struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	UseSSL    bool
+	Region    string
 }
 
 // OTelConfig holds OpenTelemetry configuration
@@ -73,6 +437,57 @@ type OTelConfig struct {
 	ServiceName string
 	JaegerURL   string
 	Environment string
+
+	// Exporter selects the span (and, for the otlp-* values, metrics)
+	// exporter telemetry.Init constructs: "jaeger", "otlp-grpc", "otlp-http",
+	// "stdout", or "none" (tracing disabled but metrics/Prometheus still
+	// run). Defaults to "jaeger" so existing deployments keep working.
+	Exporter string
+
+	// OTLPEndpoint and OTLPInsecure configure the otlp-grpc/otlp-http
+	// exporters; unused otherwise.
+	OTLPEndpoint string
+	OTLPInsecure bool
+
+	// Sampler selects the trace sampler: "always_on", "always_off",
+	// "traceidratio", or "parentbased_traceidratio" (traceidratio wrapped in
+	// sdktrace.ParentBased). The latter two use SampleRatio.
+	Sampler     string
+	SampleRatio float64
+
+	// BatchTimeout and MaxQueueSize tune the span batch processor.
+	BatchTimeout Duration
+	MaxQueueSize int
+
+	// MetricsEnabled turns on the OTLP metrics pipeline in addition to
+	// Prometheus scraping. Only applies to the otlp-* exporters. The
+	// counters/histograms pkg/telemetry registers with Prometheus are not
+	// pushed through it.
+	MetricsEnabled bool
+}
+
+// CrowdSecConfig holds configuration for the CrowdSec LAPI bouncer (see
+// pkg/security/crowdsec).
+type CrowdSecConfig struct {
+	Enabled bool
+	LAPIURL string
+	APIKey  string
+
+	// Action selects how the bouncer responds to a banned request: "ban"
+	// (403), "throttle" (429), "captcha" (redirect to CaptchaURL), or
+	// "tarpit" (hold the connection for TarpitDelay, then 403).
+	Action      string
+	CaptchaURL  string
+	TarpitDelay Duration
+}
+
+// SecurityConfig holds configuration for request-inspection security
+// features (see pkg/security/detector).
+type SecurityConfig struct {
+	// RulesPath is a YAML or JSON file for the SuspiciousRequestDetector's
+	// ruleset. Empty uses detector.DefaultRuleSet. Send the process SIGHUP
+	// to re-read this file without restarting.
+	RulesPath string
 }
 
 // Load loads configuration from environment variables and .env file
@@ -83,13 +498,91 @@ func Load() (*Config, error) {
 		// fmt.Printf("Warning: .env file not found: %v\n", err)
 	}
 
+	config := loadFromEnv()
+
+	if err := config.resolveSecretRefs(context.Background()); err != nil {
+		return nil, fmt.Errorf("resolve secret refs: %w", err)
+	}
+
+	// Validate required configuration
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	return config, nil
+}
+
+// OIDCEnabled reports whether any federated login provider is configured,
+// for callers (e.g. cmd/server/main.go's health/readiness wiring) that need
+// to know whether external identity is in play at all without checking
+// each provider individually.
+func (c *Config) OIDCEnabled() bool {
+	return c.OIDC.Keycloak.Enabled || c.OIDC.Google.Enabled || c.OIDC.GitHub.Enabled
+}
+
+// resolveSecretRefs checks for the *_REF env var counterpart of each
+// rotatable secret (JWT_SECRET_REF, DB_PASSWORD_REF, REDIS_PASSWORD_REF)
+// and, when one is set, resolves it through its scheme's SecretProvider
+// instead of reading the plain env var. The resolved value seeds the
+// existing Secret/Password field so callers that don't care about
+// rotation keep working unchanged; the SecretRef itself is stashed
+// alongside it and kept current by a background Watch goroutine for
+// callers that do.
+func (c *Config) resolveSecretRefs(ctx context.Context) error {
+	if ref := os.Getenv("JWT_SECRET_REF"); ref != "" {
+		sr, err := NewSecretRef(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("JWT_SECRET_REF: %w", err)
+		}
+		c.JWT.Secret = sr.Load()
+		c.JWT.SecretRef = sr
+		go sr.Watch(context.Background(), secretRefreshFallback)
+	}
+	if ref := os.Getenv("DB_PASSWORD_REF"); ref != "" {
+		sr, err := NewSecretRef(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("DB_PASSWORD_REF: %w", err)
+		}
+		c.Database.Password = sr.Load()
+		c.Database.PasswordRef = sr
+		go sr.Watch(context.Background(), secretRefreshFallback)
+	}
+	if ref := os.Getenv("REDIS_PASSWORD_REF"); ref != "" {
+		sr, err := NewSecretRef(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("REDIS_PASSWORD_REF: %w", err)
+		}
+		c.Redis.Password = sr.Load()
+		c.Redis.PasswordRef = sr
+		go sr.Watch(context.Background(), secretRefreshFallback)
+	}
+	return nil
+}
+
+// loadFromEnv builds a Config from environment variables (and whatever
+// .env.Load already populated into the process env), without validating
+// it. It's split out from Load so LoadFromFile can start from the same
+// defaults/env values and layer a config file on top before validating
+// once.
+func loadFromEnv() *Config {
 	config := &Config{
 		Server: ServerConfig{
 			Port:         getEnv("PORT", "8080"),
 			Host:         getEnv("HOST", "0.0.0.0"),
-			ReadTimeout:  getDurationEnv("READ_TIMEOUT", 30*time.Second),
-			WriteTimeout: getDurationEnv("WRITE_TIMEOUT", 30*time.Second),
-			IdleTimeout:  getDurationEnv("IDLE_TIMEOUT", 60*time.Second),
+			ReadTimeout:  getDurationEnv("READ_TIMEOUT", Duration(30*time.Second)),
+			WriteTimeout: getDurationEnv("WRITE_TIMEOUT", Duration(30*time.Second)),
+			IdleTimeout:  getDurationEnv("IDLE_TIMEOUT", Duration(60*time.Second)),
+			TLS: TLSConfig{
+				Mode:     getEnv("SERVER_TLS_MODE", "off"),
+				CertFile: getEnv("SERVER_TLS_CERT_FILE", ""),
+				KeyFile:  getEnv("SERVER_TLS_KEY_FILE", ""),
+				AutoTLS: AutoTLSConfig{
+					Hosts:        getListEnv("SERVER_AUTOTLS_HOSTS", nil),
+					CacheDir:     getEnv("SERVER_AUTOTLS_CACHE_DIR", "./certs"),
+					Email:        getEnv("SERVER_AUTOTLS_EMAIL", ""),
+					DirectoryURL: getEnv("SERVER_AUTOTLS_DIRECTORY_URL", ""),
+				},
+			},
 		},
 		Database: DatabaseConfig{
 			URL:      getEnv("DATABASE_URL", ""),
@@ -101,8 +594,13 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnvStrict("JWT_SECRET"),
-			Expiration: getDurationEnv("JWT_EXPIRATION", 24*time.Hour),
+			Secret:            getEnv("JWT_SECRET", ""),
+			Expiration:        getDurationEnv("JWT_EXPIRATION", Duration(24*time.Hour)),
+			RefreshExpiration: getDurationEnv("JWT_REFRESH_EXPIRATION", Duration(7*24*time.Hour)),
+			Issuer:            getEnv("JWT_ISSUER", "connex"),
+			Audience:          getEnv("JWT_AUDIENCE", "connex-api"),
+			SigningKeySeed:    getEnv("JWT_SIGNING_KEY_SEED", ""),
+			SigningKeyID:      getEnv("JWT_SIGNING_KEY_ID", "default"),
 		},
 		Log: LogConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
@@ -117,35 +615,117 @@ func Load() (*Config, error) {
 		},
 		Jobs: JobsConfig{
 			Concurrency: getIntEnv("JOBS_CONCURRENCY", 10),
-			Queues:      []string{"default", "critical", "low"},
+			Queues:      getQueuesEnv("JOBS_QUEUES_JSON", defaultQueues()),
 		},
 		OTel: OTelConfig{
-			Enabled:     getEnv("OTEL_ENABLED", "false") == "true",
-			ServiceName: getEnv("OTEL_SERVICE_NAME", "connex"),
-			JaegerURL:   getEnv("OTEL_JAEGER_URL", "http://localhost:14268/api/traces"),
-			Environment: getEnv("OTEL_ENVIRONMENT", "development"),
+			Enabled:        getEnv("OTEL_ENABLED", "false") == "true",
+			ServiceName:    getEnv("OTEL_SERVICE_NAME", "connex"),
+			JaegerURL:      getEnv("OTEL_JAEGER_URL", "http://localhost:14268/api/traces"),
+			Environment:    getEnv("OTEL_ENVIRONMENT", "development"),
+			Exporter:       getEnv("OTEL_EXPORTER", "jaeger"),
+			OTLPEndpoint:   getEnv("OTEL_OTLP_ENDPOINT", "localhost:4317"),
+			OTLPInsecure:   getEnv("OTEL_OTLP_INSECURE", "true") == "true",
+			Sampler:        getEnv("OTEL_SAMPLER", "parentbased_traceidratio"),
+			SampleRatio:    getFloatEnv("OTEL_SAMPLE_RATIO", 1.0),
+			BatchTimeout:   getDurationEnv("OTEL_BATCH_TIMEOUT", Duration(5*time.Second)),
+			MaxQueueSize:   getIntEnv("OTEL_MAX_QUEUE_SIZE", 2048),
+			MetricsEnabled: getEnv("OTEL_METRICS_ENABLED", "false") == "true",
+		},
+		CrowdSec: CrowdSecConfig{
+			Enabled:     getEnv("CROWDSEC_ENABLED", "false") == "true",
+			LAPIURL:     getEnv("CROWDSEC_LAPI_URL", "http://localhost:8080"),
+			APIKey:      getEnv("CROWDSEC_API_KEY", ""),
+			Action:      getEnv("CROWDSEC_ACTION", "ban"),
+			CaptchaURL:  getEnv("CROWDSEC_CAPTCHA_URL", ""),
+			TarpitDelay: getDurationEnv("CROWDSEC_TARPIT_DELAY", Duration(5*time.Second)),
+		},
+		Security: SecurityConfig{
+			RulesPath: getEnv("SECURITY_RULES_PATH", ""),
+		},
+		Session: SessionConfig{
+			TokenIdleTimeout:      getDurationEnv("SESSION_IDLE_TIMEOUT", Duration(30*time.Minute)),
+			AbsoluteTokenLifetime: getDurationEnv("SESSION_ABSOLUTE_LIFETIME", Duration(24*time.Hour)),
+			EnableMultiLogin:      getEnv("SESSION_ENABLE_MULTI_LOGIN", "true") == "true",
+		},
+		MTLS: MTLSConfig{
+			Enabled:     getEnv("MTLS_ENABLED", "false") == "true",
+			CAFile:      getEnv("MTLS_CA_FILE", ""),
+			OUAllowlist: getListEnv("MTLS_OU_ALLOWLIST", nil),
+			CRLURL:      getEnv("MTLS_CRL_URL", ""),
+			CRLRefresh:  getDurationEnv("MTLS_CRL_REFRESH", Duration(10*time.Minute)),
+			OCSPEnabled: getEnv("MTLS_OCSP_ENABLED", "false") == "true",
+		},
+		Password: PasswordConfig{
+			Pepper: getEnv("PASSWORD_PEPPER", ""),
+		},
+		Breach: BreachConfig{
+			Enabled:   getEnv("BREACH_CHECK_ENABLED", "false") == "true",
+			Threshold: getIntEnv("BREACH_CHECK_THRESHOLD", 1),
+			CacheTTL:  getDurationEnv("BREACH_CHECK_CACHE_TTL", Duration(24*time.Hour)),
+			Timeout:   getDurationEnv("BREACH_CHECK_TIMEOUT", Duration(3*time.Second)),
+		},
+		Compression: CompressionConfig{
+			Enabled:      getEnv("COMPRESSION_ENABLED", "true") == "true",
+			Level:        getIntEnv("COMPRESSION_LEVEL", 5),
+			MinSizeBytes: getIntEnv("COMPRESSION_MIN_SIZE_BYTES", 1024),
+			ContentTypes: getListEnv("COMPRESSION_CONTENT_TYPES", []string{
+				"application/json", "text/*",
+			}),
+			Brotli: getEnv("COMPRESSION_BROTLI_ENABLED", "false") == "true",
+		},
+		OIDC: OIDCConfig{
+			Keycloak: OIDCProviderConfig{
+				Enabled:          getEnv("OIDC_KEYCLOAK_ENABLED", "false") == "true",
+				ClientID:         getEnv("OIDC_KEYCLOAK_CLIENT_ID", ""),
+				ClientSecret:     getEnv("OIDC_KEYCLOAK_CLIENT_SECRET", ""),
+				RedirectURL:      getEnv("OIDC_KEYCLOAK_REDIRECT_URL", ""),
+				IssuerURL:        getEnv("OIDC_KEYCLOAK_ISSUER_URL", ""),
+				Scopes:           getListEnv("OIDC_KEYCLOAK_SCOPES", []string{"openid", "profile", "email"}),
+				APIBearerEnabled: getEnv("OIDC_KEYCLOAK_API_BEARER_ENABLED", "false") == "true",
+				AllowedAudiences: getListEnv("OIDC_KEYCLOAK_ALLOWED_AUDIENCES", nil),
+				UsernameClaim:    getEnv("OIDC_KEYCLOAK_USERNAME_CLAIM", "name"),
+				GroupsClaim:      getEnv("OIDC_KEYCLOAK_GROUPS_CLAIM", ""),
+			},
+			Google: OIDCProviderConfig{
+				Enabled:      getEnv("OIDC_GOOGLE_ENABLED", "false") == "true",
+				ClientID:     getEnv("OIDC_GOOGLE_CLIENT_ID", ""),
+				ClientSecret: getEnv("OIDC_GOOGLE_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("OIDC_GOOGLE_REDIRECT_URL", ""),
+				Scopes:       getListEnv("OIDC_GOOGLE_SCOPES", []string{"openid", "profile", "email"}),
+			},
+			GitHub: OIDCProviderConfig{
+				Enabled:      getEnv("OIDC_GITHUB_ENABLED", "false") == "true",
+				ClientID:     getEnv("OIDC_GITHUB_CLIENT_ID", ""),
+				ClientSecret: getEnv("OIDC_GITHUB_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("OIDC_GITHUB_REDIRECT_URL", ""),
+				Scopes:       getListEnv("OIDC_GITHUB_SCOPES", []string{"read:user", "user:email"}),
+			},
+		},
+		LDAP: LDAPConfig{
+			Enabled:      getEnv("LDAP_ENABLED", "false") == "true",
+			URL:          getEnv("LDAP_URL", ""),
+			BindDN:       getEnv("LDAP_BIND_DN", ""),
+			BindPassword: getEnv("LDAP_BIND_PASSWORD", ""),
+			SearchBase:   getEnv("LDAP_SEARCH_BASE", ""),
+			UserFilter:   getEnv("LDAP_USER_FILTER", "(&(objectClass=person)(uid=%s))"),
+			NameAttr:     getEnv("LDAP_NAME_ATTR", "cn"),
+			EmailAttr:    getEnv("LDAP_EMAIL_ATTR", "mail"),
+			GUIDAttr:     getEnv("LDAP_GUID_ATTR", "uid"),
+			GroupsAttr:   getEnv("LDAP_GROUPS_ATTR", "memberOf"),
+			StartTLS:     getEnv("LDAP_START_TLS", "false") == "true",
+			SyncInterval: getDurationEnv("LDAP_SYNC_INTERVAL", Duration(time.Hour)),
+		},
+		Storage: StorageConfig{
+			Endpoint:  getEnv("STORAGE_ENDPOINT", "localhost:9000"),
+			AccessKey: getEnv("STORAGE_ACCESS_KEY", ""),
+			SecretKey: getEnv("STORAGE_SECRET_KEY", ""),
+			Bucket:    getEnv("STORAGE_BUCKET", "connex"),
+			UseSSL:    getEnv("STORAGE_USE_SSL", "false") == "true",
+			Region:    getEnv("STORAGE_REGION", "us-east-1"),
 		},
 	}
 
-	// Validate required configuration
-	if err := config.validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
-	}
-
-	return config, nil
-}
-
-// validate checks if all required configuration values are set
-func (c *Config) validate() error {
-	if c.Server.Port == "" {
-		return fmt.Errorf("PORT is required")
-	}
-
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("JWT_SECRET must be set")
-	}
-
-	return nil
+	return config
 }
 
 // getEnv gets an environment variable with a fallback default value
@@ -157,10 +737,10 @@ func getEnv(key, defaultValue string) string {
 }
 
 // getDurationEnv gets a duration environment variable with a fallback default value
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+func getDurationEnv(key string, defaultValue Duration) Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+			return Duration(duration)
 		}
 	}
 	return defaultValue
@@ -176,11 +756,54 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
-// getEnvStrict gets an environment variable or panics if not set
-func getEnvStrict(key string) string {
+// getFloatEnv gets a float64 environment variable with a fallback default value
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// defaultQueues is the queue set connex has always run, given Priority
+// weights matching the ones job.Init used to hardcode.
+func defaultQueues() map[string]QueueConfig {
+	return map[string]QueueConfig{
+		"critical": {Priority: 10, Concurrency: 6, MaxRetries: 25},
+		"default":  {Priority: 5, Concurrency: 3, MaxRetries: 25},
+		"low":      {Priority: 1, Concurrency: 1, MaxRetries: 25},
+	}
+}
+
+// getQueuesEnv decodes key as a JSON object of queue name -> QueueConfig
+// (see JOBS_QUEUES_JSON), falling back to defaultValue if key is unset or
+// fails to parse.
+func getQueuesEnv(key string, defaultValue map[string]QueueConfig) map[string]QueueConfig {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue
+	}
+	var queues map[string]QueueConfig
+	if err := json.Unmarshal([]byte(raw), &queues); err != nil {
+		return defaultValue
+	}
+	return queues
+}
+
+// getListEnv gets a comma-separated environment variable as a string slice
+// with a fallback default value. Whitespace around each item is trimmed and
+// empty items are dropped.
+func getListEnv(key string, defaultValue []string) []string {
 	value := os.Getenv(key)
 	if value == "" {
-		panic("Required environment variable missing: " + key)
+		return defaultValue
+	}
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
 	}
-	return value
+	return result
 }
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration that marshals/unmarshals as a Go duration
+// string ("30s") in JSON, YAML, and TOML (via encoding.TextMarshaler),
+// while also accepting a bare integer or float as a number of seconds on
+// unmarshal - so read_timeout: 30s and read_timeout: 30 both work in a
+// checked-in config file, and a value round-trips cleanly when exposed
+// over an admin/debug HTTP endpoint.
+type Duration time.Duration
+
+// String returns d in time.Duration's usual format (e.g. "30s").
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler, which both
+// encoding/json and BurntSushi/toml fall back to.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. text is first tried as
+// a Go duration string, then as a bare number of seconds (so "30" and
+// "1.5" both work alongside "30s").
+func (d *Duration) UnmarshalText(text []byte) error {
+	s := string(text)
+
+	if dur, err := time.ParseDuration(s); err == nil {
+		*d = Duration(dur)
+		return nil
+	}
+
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("config: invalid duration %q: must be a Go duration string (e.g. \"30s\") or a number of seconds", s)
+	}
+	*d = Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// MarshalJSON renders d as a duration string, e.g. "30s".
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts either a JSON string ("30s") or a bare JSON number
+// (30, 1.5), both interpreted the same way UnmarshalText does.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	}
+	return d.UnmarshalText(data)
+}
+
+// MarshalYAML renders d as a duration string, e.g. "30s".
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML accepts a YAML scalar that's a duration string, a bare
+// integer, or a float, interpreted the same way UnmarshalText does.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	return d.UnmarshalText([]byte(value.Value))
+}
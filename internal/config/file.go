@@ -0,0 +1,247 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"connex/pkg/logger"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFromFile builds a Config the same way Load does (defaults, then
+// environment variables), then decodes path - a .yaml/.yml or .toml file -
+// on top of it, so a checked-in file can supply most settings while env
+// vars still win for secrets and the few fields applyEnvOverrides covers.
+// The merged Config is validated once, at the end.
+func LoadFromFile(path string) (*Config, error) {
+	cfg := loadFromEnv()
+
+	if err := decodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("config: load %s: %w", path, err)
+	}
+	applyEnvOverrides(cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// decodeFile unmarshals path onto cfg, keyed by extension. Both yaml.v3 and
+// BurntSushi/toml only overwrite the fields present in the file and leave
+// the rest of cfg (already populated by loadFromEnv) untouched, which is
+// what gives LoadFromFile its layering: a file can set just the sections it
+// cares about.
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".toml":
+		return toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file extension %q (want .yaml, .yml, or .toml)", ext)
+	}
+}
+
+// applyEnvOverrides re-applies the environment variables operators most
+// need to override independent of a checked-in file: secrets (which
+// shouldn't live in the file at all) and the fields Watch treats as
+// hot-reloadable. Everything else is taken from the file, or from
+// loadFromEnv's own defaults if the file didn't set it either - adding a
+// field here is only needed if it should be overridable without editing
+// the file.
+func applyEnvOverrides(cfg *Config) {
+	if v, ok := os.LookupEnv("JWT_SECRET"); ok {
+		cfg.JWT.Secret = v
+	}
+	if v, ok := os.LookupEnv("DB_PASSWORD"); ok {
+		cfg.Database.Password = v
+	}
+	if v, ok := os.LookupEnv("REDIS_PASSWORD"); ok {
+		cfg.Redis.Password = v
+	}
+	if v, ok := os.LookupEnv("STORAGE_ACCESS_KEY"); ok {
+		cfg.Storage.AccessKey = v
+	}
+	if v, ok := os.LookupEnv("STORAGE_SECRET_KEY"); ok {
+		cfg.Storage.SecretKey = v
+	}
+
+	applyHotOverrides(cfg)
+}
+
+// applyHotOverrides applies the subset of env vars that back Watch's
+// hot-reloadable fields, shared with applyEnvOverrides so LoadFromFile and
+// Watch agree on what counts as hot.
+func applyHotOverrides(cfg *Config) {
+	if v, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		cfg.Log.Level = v
+	}
+	if v, ok := os.LookupEnv("JOBS_CONCURRENCY"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Jobs.Concurrency = n
+		}
+	}
+	if v, ok := os.LookupEnv("OTEL_SAMPLER"); ok {
+		cfg.OTel.Sampler = v
+	}
+	if v, ok := os.LookupEnv("OTEL_SAMPLE_RATIO"); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.OTel.SampleRatio = f
+		}
+	}
+}
+
+// Watcher watches a config file on disk and republishes its hot-reloadable
+// fields - Log.Level, Jobs.Concurrency, OTel.Sampler, OTel.SampleRatio - to
+// every Subscribe'd channel whenever the file changes, so subsystems can
+// re-tune without a restart. Everything else in Config is fixed at Watch
+// startup; a change to e.g. Database or JWT still requires a restart.
+type Watcher struct {
+	path string
+
+	mu      sync.Mutex
+	current Config
+	subs    map[<-chan Config]chan Config
+}
+
+// NewWatcher builds a Watcher over path, seeded with initial (typically the
+// Config LoadFromFile just returned) until the first reload.
+func NewWatcher(path string, initial Config) *Watcher {
+	return &Watcher{
+		path:    path,
+		current: initial,
+		subs:    make(map[<-chan Config]chan Config),
+	}
+}
+
+// Subscribe registers a channel that immediately receives the Watcher's
+// current Config, then the latest one after every reload. Unlike
+// events.Bus (which drops a published event for a slow subscriber so event
+// order is preserved), a buffered-but-full subscriber here has its stale
+// value replaced with the newest one - for config, the latest value is
+// always what matters, never the ones in between.
+func (w *Watcher) Subscribe() <-chan Config {
+	ch := make(chan Config, 1)
+	w.mu.Lock()
+	ch <- w.current
+	w.subs[ch] = ch
+	w.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe releases ch; it receives no further reloads and is closed.
+func (w *Watcher) Unsubscribe(ch <-chan Config) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if c, ok := w.subs[ch]; ok {
+		delete(w.subs, ch)
+		close(c)
+	}
+}
+
+// broadcast stores cfg as current and delivers it to every subscriber,
+// replacing whatever stale value sits in a full buffer instead of blocking
+// or dropping the new one.
+func (w *Watcher) broadcast(cfg Config) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.current = cfg
+	for _, ch := range w.subs {
+		select {
+		case ch <- cfg:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- cfg
+		}
+	}
+}
+
+// reload re-reads w.path and applies its hot-reloadable fields on top of
+// the Watcher's current Config, then broadcasts the result.
+func (w *Watcher) reload() error {
+	w.mu.Lock()
+	next := w.current
+	w.mu.Unlock()
+
+	fromFile := next
+	if err := decodeFile(w.path, &fromFile); err != nil {
+		return err
+	}
+	next.Log.Level = fromFile.Log.Level
+	next.Jobs.Concurrency = fromFile.Jobs.Concurrency
+	next.OTel.Sampler = fromFile.OTel.Sampler
+	next.OTel.SampleRatio = fromFile.OTel.SampleRatio
+	applyHotOverrides(&next)
+
+	w.broadcast(next)
+	return nil
+}
+
+// Watch blocks watching w.path for writes via fsnotify, reloading and
+// broadcasting on each one, until ctx is canceled. Callers should run it in
+// its own goroutine. It watches the file's parent directory rather than the
+// file itself, since editors and ConfigMap-style mounts commonly replace a
+// config file via rename instead of writing it in place, which a
+// file-level watch would miss.
+func (w *Watcher) Watch(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("config: watch: %w", err)
+	}
+	defer fsw.Close()
+
+	dir := filepath.Dir(w.path)
+	if err := fsw.Add(dir); err != nil {
+		return fmt.Errorf("config: watch %s: %w", dir, err)
+	}
+
+	log := logger.GetGlobal()
+	target := filepath.Clean(w.path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) != target {
+				continue
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if err := w.reload(); err != nil {
+				log.Warn("config: reload failed, keeping previous config",
+					zap.String("path", w.path), zap.Error(err))
+			}
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warn("config: watcher error", zap.String("path", w.path), zap.Error(err))
+		}
+	}
+}
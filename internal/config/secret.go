@@ -0,0 +1,156 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"connex/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// secretRefreshFallback is how often a SecretRef re-resolves when its
+// provider doesn't report a TTL of its own (env, file, and AWS Secrets
+// Manager all fall into this bucket; Vault reports its KV v2 lease
+// duration and that takes precedence).
+const secretRefreshFallback = 5 * time.Minute
+
+// SecretProvider resolves a secret reference to its current value. path is
+// the reference with its "scheme://" prefix already stripped. ttl is how
+// long the caller should treat the value as fresh before re-resolving;
+// zero means the provider has no opinion and the caller's own fallback
+// interval applies.
+type SecretProvider interface {
+	Resolve(ctx context.Context, path string) (value string, ttl time.Duration, err error)
+}
+
+// secretProviders maps a ref's URI scheme to the provider that resolves
+// it. Vault and AWS Secrets Manager register themselves here from their
+// own init(), same as pluggable backends elsewhere in the codebase (see
+// auth/backend.Registry).
+var secretProviders = map[string]SecretProvider{
+	"env":  envProvider{},
+	"file": fileProvider{},
+}
+
+// RegisterSecretProvider adds (or replaces) the provider used for refs
+// with the given URI scheme.
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	secretProviders[scheme] = p
+}
+
+// envProvider resolves a secret straight from an environment variable, so
+// a bare name like "JWT_SECRET" (no "://") keeps working as a ref.
+type envProvider struct{}
+
+func (envProvider) Resolve(_ context.Context, name string) (string, time.Duration, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", 0, fmt.Errorf("env var %q is not set", name)
+	}
+	return v, 0, nil
+}
+
+// fileProvider resolves a secret from a file's contents, e.g. a Kubernetes
+// Secret volume mounted at /run/secrets/jwt. A trailing newline (which most
+// secret-mounting tools add) is trimmed.
+type fileProvider struct{}
+
+func (fileProvider) Resolve(_ context.Context, path string) (string, time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("read %q: %w", path, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), 0, nil
+}
+
+// parseSecretRef splits a "scheme://path" reference into scheme and path.
+// A ref with no "://" is treated as a bare env var name under the "env"
+// scheme, matching how JWT_SECRET etc. are read today.
+func parseSecretRef(ref string) (scheme, path string) {
+	scheme, path, ok := strings.Cut(ref, "://")
+	if !ok {
+		return "env", ref
+	}
+	return scheme, path
+}
+
+// resolveSecretRef dispatches ref to the provider registered for its
+// scheme.
+func resolveSecretRef(ctx context.Context, ref string) (string, time.Duration, error) {
+	scheme, path := parseSecretRef(ref)
+	p, ok := secretProviders[scheme]
+	if !ok {
+		return "", 0, fmt.Errorf("secret: no provider registered for scheme %q", scheme)
+	}
+	value, ttl, err := p.Resolve(ctx, path)
+	if err != nil {
+		return "", 0, fmt.Errorf("secret: resolve %q: %w", ref, err)
+	}
+	return value, ttl, nil
+}
+
+// SecretRef holds a resolved secret behind an atomic pointer so readers
+// (such as the JWT middleware) never block on, or observe a
+// torn update from, a background refresh. Build one with NewSecretRef and
+// keep it current with Watch; Load is safe to call from any goroutine at
+// any time.
+type SecretRef struct {
+	ref   string
+	value atomic.Pointer[string]
+}
+
+// NewSecretRef resolves ref once, synchronously, so a misconfigured ref
+// fails startup immediately rather than surfacing later from a background
+// goroutine.
+func NewSecretRef(ctx context.Context, ref string) (*SecretRef, error) {
+	value, _, err := resolveSecretRef(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	s := &SecretRef{ref: ref}
+	s.value.Store(&value)
+	return s, nil
+}
+
+// Load returns the secret's current value.
+func (s *SecretRef) Load() string {
+	if v := s.value.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// Watch re-resolves s.ref on a timer until ctx is cancelled, honoring the
+// provider-reported TTL when there is one and falling back to fallback
+// otherwise. A failed refresh is logged and the previous value is kept in
+// place - same fail-open stance as the CrowdSec and HIBP integrations - so
+// a Vault or Secrets Manager outage doesn't take the process down.
+func (s *SecretRef) Watch(ctx context.Context, fallback time.Duration) {
+	log := logger.GetGlobal()
+	interval := fallback
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		value, ttl, err := resolveSecretRef(ctx, s.ref)
+		if err != nil {
+			log.Warn("secret: refresh failed, keeping previous value", zap.String("ref", s.ref), zap.Error(err))
+			continue
+		}
+		s.value.Store(&value)
+		if ttl > 0 {
+			interval = ttl
+		} else {
+			interval = fallback
+		}
+	}
+}
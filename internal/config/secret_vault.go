@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	RegisterSecretProvider("vault", newVaultProviderFromEnv())
+}
+
+// VaultProvider resolves secrets from a HashiCorp Vault KV v2 mount over
+// its HTTP API, authenticating with either a static token (VAULT_TOKEN) or
+// AppRole credentials (VAULT_ROLE_ID/VAULT_SECRET_ID). Registered under the
+// "vault" scheme, so refs look like "vault://secret/data/connex#jwt".
+type VaultProvider struct {
+	addr       string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	token    string
+	roleID   string
+	secretID string
+}
+
+func newVaultProviderFromEnv() *VaultProvider {
+	return &VaultProvider{
+		addr:       strings.TrimRight(getEnv("VAULT_ADDR", "http://127.0.0.1:8200"), "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		token:      getEnv("VAULT_TOKEN", ""),
+		roleID:     getEnv("VAULT_ROLE_ID", ""),
+		secretID:   getEnv("VAULT_SECRET_ID", ""),
+	}
+}
+
+// Resolve reads path (e.g. "secret/data/connex#jwt") from Vault's KV v2
+// API. The portion after "#" selects which key of the secret's data map to
+// return; it can be omitted when the secret holds exactly one key.
+func (v *VaultProvider) Resolve(ctx context.Context, path string) (string, time.Duration, error) {
+	mountPath, key, _ := strings.Cut(path, "#")
+
+	token, err := v.authToken(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("vault: authenticate: %w", err)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+		LeaseDuration int `json:"lease_duration"`
+	}
+	if err := v.request(ctx, http.MethodGet, "/v1/"+mountPath, token, nil, &body); err != nil {
+		return "", 0, fmt.Errorf("vault: read %q: %w", mountPath, err)
+	}
+
+	ttl := time.Duration(body.LeaseDuration) * time.Second
+	if key == "" {
+		if len(body.Data.Data) != 1 {
+			return "", 0, fmt.Errorf("vault: %q has %d keys, specify one with a #key suffix", mountPath, len(body.Data.Data))
+		}
+		for _, value := range body.Data.Data {
+			return value, ttl, nil
+		}
+	}
+	value, ok := body.Data.Data[key]
+	if !ok {
+		return "", 0, fmt.Errorf("vault: %q has no key %q", mountPath, key)
+	}
+	return value, ttl, nil
+}
+
+// authToken returns the configured static token, or logs into the AppRole
+// auth method and caches the resulting client token for reuse.
+func (v *VaultProvider) authToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token != "" {
+		return v.token, nil
+	}
+	if v.roleID == "" {
+		return "", fmt.Errorf("neither VAULT_TOKEN nor VAULT_ROLE_ID/VAULT_SECRET_ID is set")
+	}
+
+	reqBody, err := json.Marshal(map[string]string{"role_id": v.roleID, "secret_id": v.secretID})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := v.request(ctx, http.MethodPost, "/v1/auth/approle/login", "", reqBody, &resp); err != nil {
+		return "", fmt.Errorf("approle login: %w", err)
+	}
+	v.token = resp.Auth.ClientToken
+	return v.token, nil
+}
+
+// request issues an HTTP call against the Vault API and, when out is
+// non-nil, decodes the JSON response body into it.
+func (v *VaultProvider) request(ctx context.Context, method, path, token string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, v.addr+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(data))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
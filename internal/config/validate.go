@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError is one failed validation rule, identified by a dotted field
+// path (e.g. "database.host") so operators can be pointed at the exact
+// setting to fix instead of a single opaque message.
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// MultiError aggregates every FieldError a Validate call found, instead of
+// stopping at the first one.
+type MultiError []FieldError
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, e := range m {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// appendErr records one failed rule onto errs.
+func appendErr(errs MultiError, path, message string) MultiError {
+	return append(errs, FieldError{Path: path, Message: message})
+}
+
+// mergeErr flattens a sub-Validate's error (nil, a MultiError, or some other
+// error) onto errs.
+func mergeErr(errs MultiError, err error) MultiError {
+	if err == nil {
+		return errs
+	}
+	if me, ok := err.(MultiError); ok {
+		return append(errs, me...)
+	}
+	return append(errs, FieldError{Message: err.Error()})
+}
+
+// Validate aggregates Config's own required fields plus every subconfig's
+// Validate call into a single MultiError (nil if everything's valid). It
+// replaces the old single-message validate, which returned only the first
+// problem it found.
+func (c *Config) Validate() error {
+	var errs MultiError
+	if c.Server.Port == "" {
+		errs = appendErr(errs, "server.port", "is required")
+	}
+	errs = mergeErr(errs, c.Database.Validate())
+	errs = mergeErr(errs, c.Redis.Validate())
+	errs = mergeErr(errs, c.JWT.Validate())
+	errs = mergeErr(errs, c.Jobs.Validate())
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks that either URL or the discrete Host/DBName pair is
+// usable, and that SSLMode is a mode lib/pq understands.
+func (d DatabaseConfig) Validate() error {
+	var errs MultiError
+	if d.URL == "" {
+		if d.Host == "" {
+			errs = appendErr(errs, "database.host", "is required when database.url is unset")
+		}
+		if d.DBName == "" {
+			errs = appendErr(errs, "database.dbname", "is required when database.url is unset")
+		}
+	}
+	switch d.SSLMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+	default:
+		errs = appendErr(errs, "database.sslmode", fmt.Sprintf("must be one of disable, require, verify-ca, verify-full (got %q)", d.SSLMode))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks that Redis has an address to connect to and a sane
+// logical DB index.
+func (r RedisConfig) Validate() error {
+	var errs MultiError
+	if r.URL == "" && r.Host == "" {
+		errs = appendErr(errs, "redis.host", "is required when redis.url is unset")
+	}
+	if r.DB < 0 {
+		errs = appendErr(errs, "redis.db", "must be >= 0")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks that every queue's DeadLetter, when set, names another
+// queue actually present in Queues - pointing it at a typo'd or missing
+// name would forward exhausted tasks onto a queue no server processes.
+func (j JobsConfig) Validate() error {
+	var errs MultiError
+	for name, qc := range j.Queues {
+		if qc.DeadLetter == "" {
+			continue
+		}
+		if qc.DeadLetter == name {
+			errs = appendErr(errs, fmt.Sprintf("jobs.queues.%s.dead_letter", name), "cannot name its own queue")
+			continue
+		}
+		if _, ok := j.Queues[qc.DeadLetter]; !ok {
+			errs = appendErr(errs, fmt.Sprintf("jobs.queues.%s.dead_letter", name),
+				fmt.Sprintf("references unknown queue %q", qc.DeadLetter))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks JWT's required secret and that its token lifetimes are
+// positive.
+func (j JWTConfig) Validate() error {
+	var errs MultiError
+	if j.Secret == "" {
+		errs = appendErr(errs, "jwt.secret", "is required")
+	}
+	if j.Expiration <= 0 {
+		errs = appendErr(errs, "jwt.expiration", "must be > 0")
+	}
+	if j.RefreshExpiration <= 0 {
+		errs = appendErr(errs, "jwt.refresh_expiration", "must be > 0")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"connex/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// DefaultTenant is the pool name Init/Get and Resolve fall back to when no
+// tenant has been registered or resolved from context.
+const DefaultTenant = "default"
+
+type contextKey string
+
+const tenantKey contextKey = "db_tenant"
+
+// Manager owns a set of named *sqlx.DB pools, keyed by tenant/shard ID. It
+// replaces the old package-level singleton so callers that need more than
+// one pool (multi-tenant routing, or parallel integration tests each
+// standing up their own Postgres container) aren't forced to share one
+// global connection.
+type Manager struct {
+	mu    sync.RWMutex
+	pools map[string]*sqlx.DB
+}
+
+// NewManager returns an empty Manager ready for Register calls.
+func NewManager() *Manager {
+	return &Manager{pools: make(map[string]*sqlx.DB)}
+}
+
+// Register opens a connection pool for cfg and stores it under name,
+// replacing any pool already registered under that name.
+func (m *Manager) Register(name string, cfg config.DatabaseConfig) error {
+	var (
+		pool *sqlx.DB
+		err  error
+	)
+	if cfg.URL != "" {
+		pool, err = sqlx.Connect("postgres", cfg.URL)
+	} else {
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+		pool, err = sqlx.Connect("postgres", dsn)
+	}
+	if err != nil {
+		return fmt.Errorf("db: failed to register pool %q: %w", name, err)
+	}
+
+	m.mu.Lock()
+	if old, ok := m.pools[name]; ok {
+		old.Close()
+	}
+	m.pools[name] = pool
+	m.mu.Unlock()
+	return nil
+}
+
+// Get returns the pool registered under name, if any.
+func (m *Manager) Get(name string) (*sqlx.DB, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pool, ok := m.pools[name]
+	return pool, ok
+}
+
+// SetPool registers an already-open pool under name, bypassing Register's
+// dial. Intended for tests that wire in a sqlmock-backed *sqlx.DB.
+func (m *Manager) SetPool(name string, pool *sqlx.DB) {
+	m.mu.Lock()
+	m.pools[name] = pool
+	m.mu.Unlock()
+}
+
+// Resolve returns the pool for the tenant stored in ctx (see WithTenant and
+// TenantResolver), falling back to DefaultTenant if none is set.
+func (m *Manager) Resolve(ctx context.Context) (*sqlx.DB, error) {
+	name := DefaultTenant
+	if v, ok := ctx.Value(tenantKey).(string); ok && v != "" {
+		name = v
+	}
+	pool, ok := m.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("db: no pool registered for tenant %q", name)
+	}
+	return pool, nil
+}
+
+// Close closes every pool the Manager owns.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var firstErr error
+	for name, pool := range m.pools {
+		if err := pool.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("db: failed to close pool %q: %w", name, err)
+		}
+	}
+	return firstErr
+}
+
+// WithTenant returns a context carrying the given tenant/shard name for a
+// later Resolve call.
+func WithTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tenantKey, name)
+}
+
+// TenantFromContext returns the tenant name stored in ctx, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(tenantKey).(string)
+	return name, ok
+}
+
+// TenantResolver is a chi-compatible middleware that derives the tenant for
+// each request via fn (e.g. from a subdomain or header) and injects it into
+// the request context for later Resolve calls.
+func TenantResolver(fn func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := fn(r)
+			if name == "" {
+				name = DefaultTenant
+			}
+			ctx := WithTenant(r.Context(), name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// defaultManager backs the deprecated Init/Get package functions below.
+var defaultManager = NewManager()
+
+// DefaultManager returns the Manager backing the deprecated Init/Get
+// functions, so callers migrating to Register/Resolve can keep sharing the
+// pool Init already opened instead of dialing a second one.
+func DefaultManager() *Manager {
+	return defaultManager
+}
+
+// Init initializes the default-named database connection pool.
+//
+// Deprecated: use a *Manager with Register/Resolve instead, so multiple
+// pools (per tenant, or per test) can coexist without sharing global state.
+func Init(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	if err := defaultManager.Register(DefaultTenant, cfg); err != nil {
+		return nil, err
+	}
+	pool, _ := defaultManager.Get(DefaultTenant)
+	return pool, nil
+}
+
+// Get returns the default-named pool registered via Init.
+//
+// Deprecated: use a *Manager's Resolve(ctx) instead.
+func Get() *sqlx.DB {
+	pool, _ := defaultManager.Get(DefaultTenant)
+	return pool
+}
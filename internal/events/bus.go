@@ -0,0 +1,168 @@
+// Package events provides a small in-process pub/sub bus so live consumers
+// (currently admin.Handler's /admin/stream SSE endpoint) can observe request
+// completions and job lifecycle transitions without polling the database.
+package events
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is one message published onto a Bus.
+type Event struct {
+	// ID is a monotonically increasing, bus-scoped identifier, suitable for
+	// use as an SSE "id:" field and for Last-Event-ID-based resume.
+	ID   string      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// historySize bounds how many recent events a Bus retains for Subscribe's
+// replay.
+const historySize = 256
+
+// subscriberBuffer bounds each Subscription's channel; Publish never blocks
+// on a slow subscriber, it drops the event for that subscriber instead once
+// its buffer is full.
+const subscriberBuffer = 32
+
+// Bus fans a stream of Events out to any number of Subscriptions. The zero
+// value is not usable; construct one with NewBus.
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []Event
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus constructs an empty Bus.
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscription is a single consumer's view of a Bus, returned by Subscribe.
+// Callers must call Unsubscribe when done to release it.
+type Subscription struct {
+	bus     *Bus
+	ch      chan Event
+	dropped uint64
+}
+
+// Events returns the channel new Events are delivered on.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns how many events have been dropped for this subscription
+// because its buffer was full when published.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped
+}
+
+// Publish appends a new Event of the given type and data, delivering it to
+// every current subscriber, and returns the Event (including its assigned
+// ID) for callers that want to log or test against it.
+func (b *Bus) Publish(eventType string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	ev := Event{
+		ID:   strconv.FormatUint(b.nextID, 10),
+		Type: eventType,
+		Time: time.Now(),
+		Data: data,
+	}
+
+	b.history = append(b.history, ev)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped++
+		}
+	}
+
+	return ev
+}
+
+// Subscribe registers a new Subscription. If lastEventID is non-empty and
+// still present in the bus's history, every event published after it is
+// replayed onto the subscription's channel immediately, oldest first
+// (best-effort: once the subscriber's buffer fills during replay, the newer
+// events that don't fit are dropped and counted in Dropped, just as they
+// would be for a live publish).
+func (b *Bus) Subscribe(lastEventID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		bus: b,
+		ch:  make(chan Event, subscriberBuffer),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	for _, ev := range b.replayLocked(lastEventID) {
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped++
+		}
+	}
+
+	return sub
+}
+
+// replayLocked returns the events after lastEventID still held in history.
+// b.mu must already be held. An empty or unrecognized lastEventID replays
+// nothing, since there's no anchor to resume from.
+func (b *Bus) replayLocked(lastEventID string) []Event {
+	if lastEventID == "" {
+		return nil
+	}
+	for i, ev := range b.history {
+		if ev.ID == lastEventID {
+			return b.history[i+1:]
+		}
+	}
+	return nil
+}
+
+// Unsubscribe removes sub from the bus; its channel receives no further
+// events.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, sub)
+}
+
+// defaultBus is the process-wide Bus used by the package-level Publish/
+// Subscribe helpers, mirroring the job/telemetry packages' global-state
+// convention.
+var defaultBus = NewBus()
+
+// Default returns the process-wide Bus.
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish publishes eventType/data on the default Bus.
+func Publish(eventType string, data interface{}) Event {
+	return defaultBus.Publish(eventType, data)
+}
+
+// Subscribe subscribes to the default Bus.
+func Subscribe(lastEventID string) *Subscription {
+	return defaultBus.Subscribe(lastEventID)
+}
+
+// Unsubscribe removes sub from the default Bus.
+func Unsubscribe(sub *Subscription) {
+	defaultBus.Unsubscribe(sub)
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishDeliversToSubscribers(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe("")
+	defer bus.Unsubscribe(sub)
+
+	ev := bus.Publish("job.enqueued", map[string]string{"type": "email:send"})
+
+	select {
+	case got := <-sub.Events():
+		assert.Equal(t, ev.ID, got.ID)
+		assert.Equal(t, "job.enqueued", got.Type)
+	default:
+		t.Fatal("expected an event to be delivered")
+	}
+}
+
+func TestBus_SubscribeResumesFromLastEventID(t *testing.T) {
+	bus := NewBus()
+
+	first := bus.Publish("a", nil)
+	second := bus.Publish("b", nil)
+
+	sub := bus.Subscribe(first.ID)
+	defer bus.Unsubscribe(sub)
+
+	select {
+	case got := <-sub.Events():
+		assert.Equal(t, second.ID, got.ID)
+	default:
+		t.Fatal("expected replay of events after lastEventID")
+	}
+}
+
+func TestBus_UnknownLastEventIDReplaysNothing(t *testing.T) {
+	bus := NewBus()
+	bus.Publish("a", nil)
+
+	sub := bus.Subscribe("does-not-exist")
+	defer bus.Unsubscribe(sub)
+
+	select {
+	case ev := <-sub.Events():
+		t.Fatalf("expected no replay, got %+v", ev)
+	default:
+	}
+}
+
+func TestBus_DropsEventsForFullSubscriberBuffer(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe("")
+	defer bus.Unsubscribe(sub)
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		bus.Publish("tick", i)
+	}
+
+	require.Greater(t, sub.Dropped(), uint64(0))
+}
+
+func TestBus_UnsubscribeStopsDelivery(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe("")
+	bus.Unsubscribe(sub)
+
+	bus.Publish("after-unsubscribe", nil)
+
+	select {
+	case ev := <-sub.Events():
+		t.Fatalf("expected no delivery after unsubscribe, got %+v", ev)
+	default:
+	}
+}
@@ -7,15 +7,22 @@ import (
 	"time"
 
 	"connex/internal/config"
+	"connex/internal/events"
 
 	"github.com/hibiken/asynq"
 	"go.uber.org/zap"
 )
 
 var (
-	client *asynq.Client
-	server *asynq.Server
-	logger *zap.Logger
+	client   *asynq.Client
+	server   *asynq.Server
+	logger   *zap.Logger
+	redisOpt asynq.RedisClientOpt
+
+	// queueConfigs is cfg.Queues as handed to Init, kept around so
+	// QueueOptions and the dead-letter ErrorHandler can look up a queue's
+	// MaxRetries/DeadLetter without threading config through every call.
+	queueConfigs map[string]config.QueueConfig
 )
 
 // Job types
@@ -48,31 +55,35 @@ type DataProcessPayload struct {
 }
 
 // Init initializes the job queue system
-func Init(cfg config.JobsConfig, redisOpt asynq.RedisClientOpt, log *zap.Logger) error {
+func Init(cfg config.JobsConfig, opt asynq.RedisClientOpt, log *zap.Logger) error {
 	logger = log
+	redisOpt = opt
+	queueConfigs = cfg.Queues
 
 	// Create client
 	client = asynq.NewClient(redisOpt)
 
+	priorities := make(map[string]int, len(cfg.Queues))
+	for name, qc := range cfg.Queues {
+		priorities[name] = qc.Priority
+	}
+
 	// Create server
 	server = asynq.NewServer(
 		redisOpt,
 		asynq.Config{
-			Concurrency: cfg.Concurrency,
-			Queues: map[string]int{
-				"critical": 10,
-				"default":  5,
-				"low":      1,
-			},
+			Concurrency:  cfg.Concurrency,
+			Queues:       priorities,
+			ErrorHandler: asynq.ErrorHandlerFunc(deadLetterOnFinalFailure),
 		},
 	)
 
 	// Register handlers
 	mux := asynq.NewServeMux()
-	mux.HandleFunc(TypeEmailSend, handleEmailSend)
-	mux.HandleFunc(TypeUserWelcome, handleUserWelcome)
-	mux.HandleFunc(TypeDataProcess, handleDataProcess)
-	mux.HandleFunc(TypeCleanup, handleCleanup)
+	mux.HandleFunc(TypeEmailSend, trackJob(TypeEmailSend, handleEmailSend))
+	mux.HandleFunc(TypeUserWelcome, trackJob(TypeUserWelcome, handleUserWelcome))
+	mux.HandleFunc(TypeDataProcess, trackJob(TypeDataProcess, handleDataProcess))
+	mux.HandleFunc(TypeCleanup, trackJob(TypeCleanup, handleCleanup))
 
 	// Start server in background
 	go func() {
@@ -94,6 +105,116 @@ func GetServer() *asynq.Server {
 	return server
 }
 
+// Ping checks connectivity to the Asynq broker (the Redis instance backing
+// the job queues) by listing its queues, for use by health.AsynqChecker.
+func Ping() error {
+	insp := asynq.NewInspector(redisOpt)
+	defer insp.Close()
+	_, err := insp.Queues()
+	return err
+}
+
+// QueueStats summarizes one Asynq queue's current depth, for admin.Handler's
+// /admin/metrics endpoint.
+type QueueStats struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	Pending   int    `json:"pending"`
+	Active    int    `json:"active"`
+	Scheduled int    `json:"scheduled"`
+	Retry     int    `json:"retry"`
+	Archived  int    `json:"archived"`
+}
+
+// Queues returns current depth information for every queue registered on
+// the broker, via asynq.Inspector.
+func Queues() ([]QueueStats, error) {
+	insp := asynq.NewInspector(redisOpt)
+	defer insp.Close()
+
+	names, err := insp.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("list queues: %w", err)
+	}
+
+	stats := make([]QueueStats, 0, len(names))
+	for _, name := range names {
+		info, err := insp.GetQueueInfo(name)
+		if err != nil {
+			return nil, fmt.Errorf("queue info for %q: %w", name, err)
+		}
+		stats = append(stats, QueueStats{
+			Name:      info.Queue,
+			Size:      info.Size,
+			Pending:   info.Pending,
+			Active:    info.Active,
+			Scheduled: info.Scheduled,
+			Retry:     info.Retry,
+			Archived:  info.Archived,
+		})
+	}
+	return stats, nil
+}
+
+// publishEnqueued emits a "job.enqueued" event for admin.Handler's
+// /admin/stream SSE feed once a task has actually been handed to the
+// broker.
+func publishEnqueued(taskType string) {
+	events.Publish("job.enqueued", map[string]string{"task_type": taskType})
+}
+
+// QueueOptions returns the asynq.Option defaults configured for queue:
+// asynq.Queue(queue), plus asynq.MaxRetry(cfg.Queues[queue].MaxRetries) if
+// that queue set one. Append further options after the returned slice to
+// override any of these for a single Enqueue call, e.g.
+// client.Enqueue(task, append(job.QueueOptions("critical"), asynq.MaxRetry(1))...).
+func QueueOptions(queue string) []asynq.Option {
+	opts := []asynq.Option{asynq.Queue(queue)}
+	if qc, ok := queueConfigs[queue]; ok && qc.MaxRetries > 0 {
+		opts = append(opts, asynq.MaxRetry(qc.MaxRetries))
+	}
+	return opts
+}
+
+// deadLetterOnFinalFailure is the asynq.Server's ErrorHandler. When a
+// failing task belongs to a queue whose QueueConfig.DeadLetter is set and
+// this was its last allowed attempt, it enqueues a copy of the task onto
+// that dead-letter queue, so exhausted tasks can be processed and watched
+// there. asynq still archives the original in its own queue after this
+// handler returns.
+func deadLetterOnFinalFailure(ctx context.Context, task *asynq.Task, err error) {
+	queueName, ok := asynq.GetQueueName(ctx)
+	if !ok {
+		return
+	}
+	qc, ok := queueConfigs[queueName]
+	if !ok || qc.DeadLetter == "" {
+		return
+	}
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	if !ok {
+		return
+	}
+	if retried < maxRetry {
+		// asynq will retry this task itself; only move it once exhausted.
+		return
+	}
+
+	if _, enqueueErr := client.Enqueue(task, asynq.Queue(qc.DeadLetter)); enqueueErr != nil {
+		logger.Error("failed to move exhausted task to dead-letter queue",
+			zap.String("queue", queueName), zap.String("dead_letter", qc.DeadLetter),
+			zap.String("task_type", task.Type()), zap.Error(enqueueErr))
+		return
+	}
+	logger.Warn("task exhausted retries, moved to dead-letter queue",
+		zap.String("queue", queueName), zap.String("dead_letter", qc.DeadLetter),
+		zap.String("task_type", task.Type()), zap.Error(err))
+}
+
 // EnqueueEmail enqueues an email job
 func EnqueueEmail(payload EmailPayload, opts ...asynq.Option) error {
 	data, err := json.Marshal(payload)
@@ -101,8 +222,11 @@ func EnqueueEmail(payload EmailPayload, opts ...asynq.Option) error {
 		return err
 	}
 	task := asynq.NewTask(TypeEmailSend, data)
-	_, err = client.Enqueue(task, opts...)
-	return err
+	if _, err = client.Enqueue(task, opts...); err != nil {
+		return err
+	}
+	publishEnqueued(TypeEmailSend)
+	return nil
 }
 
 // EnqueueUserWelcome enqueues a user welcome job
@@ -112,8 +236,11 @@ func EnqueueUserWelcome(payload UserWelcomePayload, opts ...asynq.Option) error
 		return err
 	}
 	task := asynq.NewTask(TypeUserWelcome, data)
-	_, err = client.Enqueue(task, opts...)
-	return err
+	if _, err = client.Enqueue(task, opts...); err != nil {
+		return err
+	}
+	publishEnqueued(TypeUserWelcome)
+	return nil
 }
 
 // EnqueueDataProcess enqueues a data processing job
@@ -123,15 +250,44 @@ func EnqueueDataProcess(payload DataProcessPayload, opts ...asynq.Option) error
 		return err
 	}
 	task := asynq.NewTask(TypeDataProcess, data)
-	_, err = client.Enqueue(task, opts...)
-	return err
+	if _, err = client.Enqueue(task, opts...); err != nil {
+		return err
+	}
+	publishEnqueued(TypeDataProcess)
+	return nil
 }
 
-// EnqueueCleanup enqueues a cleanup job
+// cleanupUniqueTTL bounds how long a cleanup task is deduplicated for via
+// asynq.Unique, so a periodic schedule firing on overlapping replicas (or a
+// retry racing the next tick) can't run two cleanups concurrently.
+const cleanupUniqueTTL = 10 * time.Minute
+
+// EnqueueCleanup enqueues a cleanup job, deduplicated for cleanupUniqueTTL
+// so overlapping enqueues (e.g. from replicas sharing a Scheduler entry)
+// collapse into a single run.
 func EnqueueCleanup(opts ...asynq.Option) error {
 	task := asynq.NewTask(TypeCleanup, nil)
-	_, err := client.Enqueue(task, opts...)
-	return err
+	opts = append(opts, asynq.Unique(cleanupUniqueTTL))
+	if _, err := client.Enqueue(task, opts...); err != nil {
+		return err
+	}
+	publishEnqueued(TypeCleanup)
+	return nil
+}
+
+// trackJob wraps an asynq.HandlerFunc to publish a "job.succeeded" or
+// "job.failed" event for admin.Handler's /admin/stream once the handler
+// returns, without each handler needing to do so itself.
+func trackJob(taskType string, h asynq.HandlerFunc) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		err := h(ctx, t)
+		if err != nil {
+			events.Publish("job.failed", map[string]string{"task_type": taskType, "error": err.Error()})
+			return err
+		}
+		events.Publish("job.succeeded", map[string]string{"task_type": taskType})
+		return nil
+	}
 }
 
 // Job handlers
@@ -200,23 +356,30 @@ func handleCleanup(ctx context.Context, t *asynq.Task) error {
 	return nil
 }
 
-// asynqLogger implements asynq.Logger interface
+// asynqLogger adapts *zap.Logger to asynq.Logger, whose methods take
+// ...interface{} (fmt.Sprint-style) rather than zap's structured fields.
 type asynqLogger struct {
 	logger *zap.Logger
 }
 
-func (l *asynqLogger) Debug(msg string, fields map[string]interface{}) {
-	l.logger.Debug(msg, zap.Any("fields", fields))
+var _ asynq.Logger = (*asynqLogger)(nil)
+
+func (l *asynqLogger) Debug(args ...interface{}) {
+	l.logger.Sugar().Debug(args...)
+}
+
+func (l *asynqLogger) Info(args ...interface{}) {
+	l.logger.Sugar().Info(args...)
 }
 
-func (l *asynqLogger) Info(msg string, fields map[string]interface{}) {
-	l.logger.Info(msg, zap.Any("fields", fields))
+func (l *asynqLogger) Warn(args ...interface{}) {
+	l.logger.Sugar().Warn(args...)
 }
 
-func (l *asynqLogger) Warn(msg string, fields map[string]interface{}) {
-	l.logger.Warn(msg, zap.Any("fields", fields))
+func (l *asynqLogger) Error(args ...interface{}) {
+	l.logger.Sugar().Error(args...)
 }
 
-func (l *asynqLogger) Error(msg string, fields map[string]interface{}) {
-	l.logger.Error(msg, zap.Any("fields", fields))
+func (l *asynqLogger) Fatal(args ...interface{}) {
+	l.logger.Sugar().Fatal(args...)
 }
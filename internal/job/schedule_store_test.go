@@ -0,0 +1,77 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"connex/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduleStore(mockDB *sql.DB) *PostgresScheduleStore {
+	manager := db.NewManager()
+	manager.SetPool(db.DefaultTenant, sqlx.NewDb(mockDB, "sqlmock"))
+	return NewPostgresScheduleStore(manager)
+}
+
+func TestPostgresScheduleStore_Create(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	store := newTestScheduleStore(mockDB)
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO job_schedules").
+		WithArgs("0 * * * *", TypeCleanup, "", "low", int64(600)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow(1, now, now))
+
+	def, err := store.Create(context.Background(), ScheduleDefinition{
+		Spec:      "0 * * * *",
+		TaskType:  TypeCleanup,
+		Queue:     "low",
+		Retention: 10 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), def.ID)
+	assert.True(t, def.Enabled)
+	assert.Equal(t, 1, def.Version)
+}
+
+func TestPostgresScheduleStore_SetEnabled(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	store := newTestScheduleStore(mockDB)
+
+	mock.ExpectExec("UPDATE job_schedules").
+		WithArgs(int64(1), false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = store.SetEnabled(context.Background(), 1, false)
+	require.NoError(t, err)
+}
+
+func TestPostgresScheduleStore_SetEnabled_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	store := newTestScheduleStore(mockDB)
+
+	mock.ExpectExec("UPDATE job_schedules").
+		WithArgs(int64(99), true).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = store.SetEnabled(context.Background(), 99, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package job
+
+import (
+	"fmt"
+	"time"
+
+	"connex/internal/config"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// Scheduler registers periodic tasks with asynq and keeps them in sync with
+// a ScheduleStore as admins create/pause/resume/delete entries via
+// /admin/jobs/schedules, without requiring a server restart.
+type Scheduler struct {
+	asynq   *asynq.Scheduler
+	log     *zap.Logger
+	entries map[int64]schedulerEntry
+}
+
+// schedulerEntry tracks the asynq entry ID and version a ScheduleDefinition
+// was last registered under, so Sync can tell whether it needs
+// re-registering or can be left alone.
+type schedulerEntry struct {
+	entryID string
+	version int
+}
+
+// InitScheduler builds a Scheduler and registers every entry in
+// cfg.Schedules. Callers that also use a ScheduleStore should follow up
+// with Sync to layer in the Postgres-persisted definitions.
+func InitScheduler(cfg config.JobsConfig, opt asynq.RedisClientOpt, log *zap.Logger) (*Scheduler, error) {
+	s := &Scheduler{
+		asynq:   asynq.NewScheduler(opt, &asynq.SchedulerOpts{Logger: &asynqLogger{logger: log}}),
+		log:     log,
+		entries: make(map[int64]schedulerEntry),
+	}
+
+	for i, sc := range cfg.Schedules {
+		task := asynq.NewTask(sc.TaskType, []byte(sc.Payload))
+		opts := scheduleOptions(sc)
+		entryID, err := s.asynq.Register(sc.Spec, task, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("register schedule %q: %w", sc.Spec, err)
+		}
+		// Config-seeded entries have no ScheduleDefinition ID of their own;
+		// key them by negative index so they can never collide with a real
+		// Postgres-assigned ID (which Sync keys entries by).
+		s.entries[int64(-(i + 1))] = schedulerEntry{entryID: entryID}
+	}
+
+	if err := s.asynq.Start(); err != nil {
+		return nil, fmt.Errorf("start scheduler: %w", err)
+	}
+	return s, nil
+}
+
+// scheduleOptions translates a ScheduleConfig/ScheduleDefinition's Queue and
+// Retention fields into asynq.Option values.
+func scheduleOptions(sc config.ScheduleConfig) []asynq.Option {
+	var opts []asynq.Option
+	if sc.Queue != "" {
+		opts = append(opts, asynq.Queue(sc.Queue))
+	}
+	if sc.Retention > 0 {
+		opts = append(opts, asynq.Retention(time.Duration(sc.Retention)))
+	}
+	return opts
+}
+
+// Shutdown stops the scheduler's background goroutine.
+func (s *Scheduler) Shutdown() {
+	s.asynq.Shutdown()
+}
+
+// Sync reconciles the scheduler's registered entries against defs (the
+// current contents of a ScheduleStore), registering new/changed entries and
+// unregistering ones that were deleted or paused. Comparing Version lets
+// replicas each running their own Scheduler skip re-registering a
+// definition none of them have actually changed.
+func (s *Scheduler) Sync(defs []ScheduleDefinition) error {
+	seen := make(map[int64]bool, len(defs))
+
+	for _, def := range defs {
+		seen[def.ID] = true
+		existing, ok := s.entries[def.ID]
+
+		if !def.Enabled {
+			if ok {
+				if err := s.asynq.Unregister(existing.entryID); err != nil {
+					return fmt.Errorf("unregister schedule %d: %w", def.ID, err)
+				}
+				delete(s.entries, def.ID)
+			}
+			continue
+		}
+
+		if ok && existing.version == def.Version {
+			continue
+		}
+		if ok {
+			if err := s.asynq.Unregister(existing.entryID); err != nil {
+				return fmt.Errorf("unregister schedule %d: %w", def.ID, err)
+			}
+		}
+
+		task := asynq.NewTask(def.TaskType, []byte(def.Payload))
+		opts := scheduleOptions(config.ScheduleConfig{Queue: def.Queue, Retention: config.Duration(def.Retention)})
+		entryID, err := s.asynq.Register(def.Spec, task, opts...)
+		if err != nil {
+			return fmt.Errorf("register schedule %d: %w", def.ID, err)
+		}
+		s.entries[def.ID] = schedulerEntry{entryID: entryID, version: def.Version}
+	}
+
+	for id, entry := range s.entries {
+		if id < 0 {
+			// Config-seeded entry; not subject to store-driven removal.
+			continue
+		}
+		if !seen[id] {
+			if err := s.asynq.Unregister(entry.entryID); err != nil {
+				return fmt.Errorf("unregister deleted schedule %d: %w", id, err)
+			}
+			delete(s.entries, id)
+		}
+	}
+	return nil
+}
+
+// EnqueueIn enqueues task to run after delay, via asynq.ProcessIn.
+func EnqueueIn(delay time.Duration, task *asynq.Task, opts ...asynq.Option) error {
+	return enqueueScheduled(task, append(opts, asynq.ProcessIn(delay)))
+}
+
+// EnqueueAt enqueues task to run at t, via asynq.ProcessAt.
+func EnqueueAt(t time.Time, task *asynq.Task, opts ...asynq.Option) error {
+	return enqueueScheduled(task, append(opts, asynq.ProcessAt(t)))
+}
+
+func enqueueScheduled(task *asynq.Task, opts []asynq.Option) error {
+	if _, err := client.Enqueue(task, opts...); err != nil {
+		return fmt.Errorf("enqueue %s: %w", task.Type(), err)
+	}
+	publishEnqueued(task.Type())
+	return nil
+}
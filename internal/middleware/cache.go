@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"connex/internal/cache"
+	"connex/pkg/logger"
+
+	"go.uber.org/zap"
 )
 
 type CacheConfig struct {
@@ -46,6 +49,7 @@ func Cache(config CacheConfig) func(http.Handler) http.Handler {
 			// Try to get from cache
 			var cachedResponse CachedResponse
 			if err := cache.GetValue(cacheKey, &cachedResponse); err == nil {
+				logger.FromContext(r.Context()).Debug("cache hit", zap.String("cache_key", cacheKey))
 				// Serve from cache
 				for key, values := range cachedResponse.Headers {
 					for _, value := range values {
@@ -57,6 +61,7 @@ func Cache(config CacheConfig) func(http.Handler) http.Handler {
 				w.Write(cachedResponse.Body)
 				return
 			}
+			logger.FromContext(r.Context()).Debug("cache miss", zap.String("cache_key", cacheKey))
 
 			// Cache miss, capture response
 			captureWriter := &responseCapture{
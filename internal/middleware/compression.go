@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"connex/internal/config"
+	"connex/pkg/telemetry"
+
+	"github.com/andybalholm/brotli"
+)
+
+type compressionContextKey string
+
+const skipCompressionKey compressionContextKey = "skip-compression"
+
+// defaultSkipPrefixes are never compressed unless a route explicitly calls
+// AllowCompression: these endpoints can echo attacker-influenced input
+// (e.g. a validation message quoting the submitted payload) back to an
+// authenticated client, which is exactly the oracle a BREACH-style
+// compression attack needs.
+var defaultSkipPrefixes = []string{"/api/auth"}
+
+// Aggregate counters behind CompressionStats, mirrored into Prometheus via
+// telemetry.RecordCompression for anyone who wants per-encoding detail.
+var (
+	compressionResponses int64
+	compressionBytesIn   int64
+	compressionBytesOut  int64
+)
+
+// SkipCompression marks the request so CompressionMiddleware always passes
+// it through uncompressed, regardless of path.
+func SkipCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), skipCompressionKey, true)))
+	})
+}
+
+// AllowCompression opts a route back into compression despite matching one
+// of defaultSkipPrefixes, e.g. a /api/auth/* endpoint that returns static
+// content with nothing attacker-controlled to leak.
+func AllowCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), skipCompressionKey, false)))
+	})
+}
+
+func compressionSkipped(r *http.Request) bool {
+	if skip, ok := r.Context().Value(skipCompressionKey).(bool); ok {
+		return skip
+	}
+	for _, prefix := range defaultSkipPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// contentTypeAllowed checks contentType (ignoring any "; charset=..."
+// parameter) against allowlist, where an entry like "text/*" matches the
+// whole subtype family.
+func contentTypeAllowed(contentType string, allowlist []string) bool {
+	contentType, _, _ = strings.Cut(contentType, ";")
+	contentType = strings.TrimSpace(contentType)
+	for _, allowed := range allowlist {
+		if strings.HasSuffix(allowed, "/*") {
+			if strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+			continue
+		}
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding returns "br" or "gzip" depending on what acceptEncoding
+// allows and brotliEnabled permits, preferring brotli's better ratio, or ""
+// if the client accepts neither.
+func negotiateEncoding(acceptEncoding string, brotliEnabled bool) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, _, _ := strings.Cut(part, ";")
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	if brotliEnabled && accepted["br"] {
+		return "br"
+	}
+	if accepted["gzip"] {
+		return "gzip"
+	}
+	return ""
+}
+
+// CompressionMiddleware negotiates Accept-Encoding and transparently gzip-
+// or (when cfg.Brotli) brotli-encodes responses at least cfg.MinSizeBytes
+// long whose Content-Type matches cfg.ContentTypes. It does not compress
+// /api/auth/* (unless the request passed through AllowCompression before
+// reaching it) or any request that passed through SkipCompression before
+// reaching it, to avoid handing a BREACH-style oracle to handlers that echo
+// attacker-controlled input back in an authenticated response.
+func CompressionMiddleware(cfg config.CompressionConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.Enabled || compressionSkipped(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Brotli)
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressingResponseWriter{ResponseWriter: w, cfg: cfg, encoding: encoding}
+			next.ServeHTTP(cw, r)
+			cw.flush()
+		})
+	}
+}
+
+// compressingResponseWriter buffers the response body so the decision to
+// compress can be made from the final Content-Type and size, then writes
+// either the compressed or the original body in one shot on flush.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	cfg         config.CompressionConfig
+	encoding    string
+	buf         bytes.Buffer
+	statusCode  int
+	wroteHeader bool
+}
+
+func (cw *compressingResponseWriter) WriteHeader(status int) {
+	cw.statusCode = status
+	cw.wroteHeader = true
+}
+
+func (cw *compressingResponseWriter) Write(b []byte) (int, error) {
+	return cw.buf.Write(b)
+}
+
+func (cw *compressingResponseWriter) flush() {
+	if !cw.wroteHeader {
+		cw.statusCode = http.StatusOK
+	}
+	body := cw.buf.Bytes()
+
+	if cw.Header().Get("Content-Encoding") != "" ||
+		len(body) < cw.cfg.MinSizeBytes ||
+		!contentTypeAllowed(cw.Header().Get("Content-Type"), cw.cfg.ContentTypes) {
+		cw.ResponseWriter.WriteHeader(cw.statusCode)
+		cw.ResponseWriter.Write(body)
+		return
+	}
+
+	var compressed bytes.Buffer
+	switch cw.encoding {
+	case "br":
+		bw := brotli.NewWriterLevel(&compressed, cw.cfg.Level)
+		bw.Write(body)
+		bw.Close()
+	default:
+		gw, _ := gzip.NewWriterLevel(&compressed, cw.cfg.Level)
+		gw.Write(body)
+		gw.Close()
+	}
+
+	telemetry.RecordCompression(cw.encoding, len(body), compressed.Len())
+	atomic.AddInt64(&compressionResponses, 1)
+	atomic.AddInt64(&compressionBytesIn, int64(len(body)))
+	atomic.AddInt64(&compressionBytesOut, int64(compressed.Len()))
+
+	cw.Header().Set("Content-Encoding", cw.encoding)
+	cw.Header().Add("Vary", "Accept-Encoding")
+	cw.Header().Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(cw.statusCode)
+	cw.ResponseWriter.Write(compressed.Bytes())
+}
+
+// CompressionStats reports the number of responses CompressionMiddleware has
+// compressed so far, the total bytes read from handlers, and the total
+// bytes actually written to the wire, for health.Handler's detailed check.
+func CompressionStats() (responses, bytesIn, bytesOut int64) {
+	return atomic.LoadInt64(&compressionResponses), atomic.LoadInt64(&compressionBytesIn), atomic.LoadInt64(&compressionBytesOut)
+}
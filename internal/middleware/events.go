@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"connex/internal/events"
+)
+
+// RequestCompletedEvent is the payload events.Publish carries for the
+// "request.completed" event type EventsMiddleware emits.
+type RequestCompletedEvent struct {
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`
+	StatusCode int           `json:"status_code"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// EventsMiddleware publishes a "request.completed" event on the default
+// events.Bus once each request finishes, feeding admin.Handler's
+// /admin/stream SSE endpoint.
+func EventsMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			events.Publish("request.completed", RequestCompletedEvent{
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				StatusCode: rw.statusCode,
+				Duration:   time.Since(start),
+			})
+		})
+	}
+}
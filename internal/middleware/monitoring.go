@@ -2,17 +2,21 @@ package middleware
 
 import (
 	"net/http"
-	"sync"
-	"time"
 
 	"connex/pkg/logger"
+	"connex/pkg/security/crowdsec"
+	"connex/pkg/security/detector"
 	"connex/pkg/telemetry"
 
 	"go.uber.org/zap"
 )
 
-// SecurityMonitoringMiddleware monitors for security events
-func SecurityMonitoringMiddleware() func(http.Handler) http.Handler {
+// SecurityMonitoringMiddleware monitors for security events. When bouncer is
+// non-nil, detected events are also reported to it via ReportEvent so they
+// reach CrowdSec LAPI as signals (see pkg/security/crowdsec); pass nil to
+// keep local logging/telemetry without the LAPI feed. det drives suspicious-
+// request detection (see pkg/security/detector); it must not be nil.
+func SecurityMonitoringMiddleware(bouncer *crowdsec.Bouncer, det *detector.SuspiciousRequestDetector) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Capture response status for monitoring
@@ -22,90 +26,49 @@ func SecurityMonitoringMiddleware() func(http.Handler) http.Handler {
 			next.ServeHTTP(responseWriter, r)
 
 			// Monitor for security events
-			monitorSecurityEvents(r, responseWriter.statusCode)
+			monitorSecurityEvents(r, responseWriter.statusCode, bouncer, det)
 		})
 	}
 }
 
 // monitorSecurityEvents checks for various security events
-func monitorSecurityEvents(r *http.Request, statusCode int) {
+func monitorSecurityEvents(r *http.Request, statusCode int, bouncer *crowdsec.Bouncer, det *detector.SuspiciousRequestDetector) {
+	log := logger.FromContext(r.Context())
+
 	// Monitor failed authentication attempts
 	if r.URL.Path == "/api/auth/login" && statusCode == http.StatusUnauthorized {
-		logger.GetGlobal().Warn("failed login attempt",
+		log.Warn("failed login attempt",
 			zap.String("ip", r.RemoteAddr),
 			zap.String("user_agent", r.UserAgent()),
 			zap.String("path", r.URL.Path),
 			zap.Int("status", statusCode),
 		)
 		telemetry.RecordSecurityEvent("failed_login", r.RemoteAddr)
+		if bouncer != nil {
+			bouncer.ReportEvent("connex/failed-login", "repeated failed login attempt", r.RemoteAddr)
+		}
 	}
 
 	// Monitor rate limit violations
 	if statusCode == http.StatusTooManyRequests {
-		logger.GetGlobal().Warn("rate limit exceeded",
+		log.Warn("rate limit exceeded",
 			zap.String("ip", r.RemoteAddr),
 			zap.String("path", r.URL.Path),
 			zap.String("method", r.Method),
 		)
 		telemetry.RecordSecurityEvent("rate_limit_violation", r.RemoteAddr)
-	}
-
-	// Monitor suspicious patterns
-	if isSuspiciousRequest(r) {
-		logger.GetGlobal().Warn("suspicious request detected",
-			zap.String("ip", r.RemoteAddr),
-			zap.String("path", r.URL.Path),
-			zap.String("user_agent", r.UserAgent()),
-			zap.String("referer", r.Referer()),
-		)
-		telemetry.RecordSecurityEvent("suspicious_request", r.RemoteAddr)
-	}
-}
-
-// isSuspiciousRequest checks for suspicious request patterns
-func isSuspiciousRequest(r *http.Request) bool {
-	// Check for common attack patterns in User-Agent
-	suspiciousUserAgents := []string{
-		"sqlmap", "nikto", "nmap", "wget", "curl", "python", "perl",
-		"masscan", "dirb", "gobuster", "wfuzz", "burp", "zap",
-	}
-
-	userAgent := r.UserAgent()
-	for _, suspicious := range suspiciousUserAgents {
-		if containsIgnoreCase(userAgent, suspicious) {
-			return true
+		if bouncer != nil {
+			bouncer.ReportEvent("connex/rate-limit", "rate limit exceeded", r.RemoteAddr)
 		}
 	}
 
-	// Check for suspicious paths
-	suspiciousPaths := []string{
-		"/admin", "/wp-admin", "/phpmyadmin", "/config", "/.env",
-		"/.git", "/.svn", "/backup", "/test", "/debug",
-	}
-
-	path := r.URL.Path
-	for _, suspicious := range suspiciousPaths {
-		if containsIgnoreCase(path, suspicious) {
-			return true
-		}
+	// Monitor suspicious patterns. det.Check already logs, records
+	// telemetry, and reports to bouncer per matched rule; the generic
+	// security event here just keeps "suspicious_request" in the same
+	// dashboard as the other event types above.
+	if det != nil && det.Check(r) {
+		telemetry.RecordSecurityEvent("suspicious_request", r.RemoteAddr)
 	}
-
-	return false
-}
-
-// containsIgnoreCase checks if a string contains another string (case insensitive)
-func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(len(s) == len(substr) && s == substr ||
-			len(s) > len(substr) && (contains(s, substr) || contains(s, substr)))
-}
-
-// contains checks if a string contains another string
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(len(s) == len(substr) && s == substr ||
-			len(s) > len(substr) && (s[:len(substr)] == substr ||
-				contains(s[1:], substr)))
 }
 
 // securityResponseWriter captures the status code for monitoring
@@ -123,48 +86,9 @@ func (rw *securityResponseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
-// FailedLoginTracker tracks failed login attempts per IP
-type FailedLoginTracker struct {
-	attempts    map[string]int
-	lastAttempt map[string]time.Time
-	mutex       sync.RWMutex
-}
-
-var globalFailedLoginTracker = &FailedLoginTracker{
-	attempts:    make(map[string]int),
-	lastAttempt: make(map[string]time.Time),
-}
-
-// RecordFailedLogin records a failed login attempt
-func (t *FailedLoginTracker) RecordFailedLogin(ip string) {
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-
-	now := time.Now()
-	t.attempts[ip]++
-	t.lastAttempt[ip] = now
-
-	// Log warning if multiple failed attempts
-	if t.attempts[ip] >= 3 {
-		logger.GetGlobal().Warn("multiple failed login attempts",
-			zap.String("ip", ip),
-			zap.Int("attempts", t.attempts[ip]),
-			zap.Time("last_attempt", t.lastAttempt[ip]),
-		)
-	}
-}
-
-// GetFailedAttempts returns the number of failed attempts for an IP
-func (t *FailedLoginTracker) GetFailedAttempts(ip string) int {
-	t.mutex.RLock()
-	defer t.mutex.RUnlock()
-	return t.attempts[ip]
-}
-
-// ResetFailedAttempts resets failed attempts for an IP (after successful login)
-func (t *FailedLoginTracker) ResetFailedAttempts(ip string) {
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	delete(t.attempts, ip)
-	delete(t.lastAttempt, ip)
-}
+// Failed-login tracking used to be an in-process FailedLoginTracker here,
+// which meant bans didn't survive a restart and didn't propagate to other
+// replicas. It's been replaced by reporting failed logins to a
+// crowdsec.Bouncer (see monitorSecurityEvents above), which forwards them to
+// CrowdSec LAPI as signals; LAPI's own scenarios decide when enough signals
+// add up to a ban, and that decision is shared by every bouncer polling it.
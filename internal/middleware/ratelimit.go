@@ -1,24 +1,44 @@
 package middleware
 
 import (
-	"context"
-	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"connex/internal/cache"
-	"net"
+	apimiddleware "connex/internal/api/middleware"
 )
 
+// RateLimitConfig configures one rate-limited route or group of routes.
+// Limiter is resolved lazily (see resolveLimiter) so zero-value configs
+// built before Redis/cache.Init has run still work.
 type RateLimitConfig struct {
-	Requests int                          // Number of requests allowed
-	Window   time.Duration                // Time window
-	KeyFunc  func(r *http.Request) string // Function to generate rate limit key
+	Requests  int                          // Number of requests allowed per Window
+	Window    time.Duration                // Time window
+	KeyFunc   func(r *http.Request) string // Function to generate the rate limit key
+	Backend   RateLimitBackend             // Defaults to BackendRedis
+	Algorithm RateLimitAlgorithm           // Defaults to AlgorithmSlidingWindow; ignored for BackendMemory
+	Limiter   RateLimiter                  // Overrides Backend/Algorithm when set; lets a route plug in its own limiter
+}
+
+func (c RateLimitConfig) resolveLimiter() RateLimiter {
+	if c.Limiter != nil {
+		return c.Limiter
+	}
+	backend := c.Backend
+	if backend == "" {
+		backend = BackendRedis
+	}
+	return NewRateLimiter(backend, c.Algorithm)
 }
 
-// RateLimit creates a rate limiting middleware
+// RateLimit creates a rate limiting middleware. It can be applied globally
+// via r.Use or scoped to a subset of routes via r.With(...) for per-route
+// overrides (e.g. a stricter RateLimitConfig on a single sensitive endpoint).
 func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
+	limiter := config.resolveLimiter()
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			key := config.KeyFunc(r)
@@ -27,46 +47,22 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 				return
 			}
 
-			limitKey := fmt.Sprintf("rate_limit:%s", key)
-			windowKey := fmt.Sprintf("rate_limit_window:%s", key)
-
-			redis := cache.Get()
-			ctx := context.Background()
-
-			// Get current window
-			window, err := redis.Get(ctx, windowKey).Int64()
-			if err != nil && err.Error() != "redis: nil" {
-				// Redis error, allow request
+			result, err := limiter.Allow(r.Context(), key, config.Requests, config.Window)
+			if err != nil {
+				// Fail open: a rate limiter outage shouldn't take the API down.
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			now := time.Now().Unix()
-			if err != nil && err.Error() == "redis: nil" || now-window > int64(config.Window.Seconds()) {
-				// New window or expired window
-				window = now
-				redis.Set(ctx, windowKey, window, config.Window)
-				redis.Set(ctx, limitKey, 1, config.Window)
-			} else {
-				// Increment counter
-				count, err := redis.Incr(ctx, limitKey).Result()
-				if err != nil {
-					next.ServeHTTP(w, r)
-					return
-				}
-
-				if count > int64(config.Requests) {
-					w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.Requests))
-					w.Header().Set("X-RateLimit-Remaining", "0")
-					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(window+int64(config.Window.Seconds()), 10))
-					http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
-					return
-				}
-
-				remaining := config.Requests - int(count)
-				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.Requests))
-				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
-				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(window+int64(config.Window.Seconds()), 10))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			resetSeconds := int(math.Ceil(result.ResetAfter.Seconds()))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
 			}
 
 			next.ServeHTTP(w, r)
@@ -101,14 +97,67 @@ func validateIP(ip string) string {
 	return ip
 }
 
+// IPKeyFunc keys by the client's real IP address.
+func IPKeyFunc(r *http.Request) string {
+	return getRealIP(r)
+}
+
+// UserKeyFunc keys by the authenticated user ID set by auth.AuthMiddleware,
+// falling back to the client IP for unauthenticated requests so routes that
+// mix public and authenticated traffic still get a limit applied.
+func UserKeyFunc(r *http.Request) string {
+	if userID, ok := apimiddleware.UserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return getRealIP(r)
+}
+
+// ComposeKeyFuncs builds a key function that joins the non-empty keys from
+// each given KeyFunc with "|", so a route can be limited along more than one
+// axis at once (e.g. per-user and per-IP together, so a single abusive user
+// can't dodge the limit by rotating IPs, nor a single abusive IP by
+// rotating accounts). If every component returns "", the composed key is ""
+// too, consistent with a plain KeyFunc opting a request out of enforcement.
+func ComposeKeyFuncs(funcs ...func(r *http.Request) string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		var parts []string
+		for _, f := range funcs {
+			if k := f(r); k != "" {
+				parts = append(parts, k)
+			}
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// UserAndIPKeyFunc keys by both the client IP and, when authenticated, the
+// user ID set by auth.AuthMiddleware. Unlike UserKeyFunc (which falls back
+// to IP only for anonymous requests), this always includes the IP, so an
+// authenticated caller is limited on both axes simultaneously.
+var UserAndIPKeyFunc = ComposeKeyFuncs(IPKeyFunc, func(r *http.Request) string {
+	if userID, ok := apimiddleware.UserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return ""
+})
+
+// APIKeyFunc keys by the value of header, for service-to-service routes
+// authenticated with a static API key instead of a user JWT.
+func APIKeyFunc(header string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		if key := r.Header.Get(header); key != "" {
+			return "apikey:" + key
+		}
+		return ""
+	}
+}
+
 // IPRateLimit creates rate limiting based on client IP
 func IPRateLimit(requests int, window time.Duration) func(http.Handler) http.Handler {
 	return RateLimit(RateLimitConfig{
 		Requests: requests,
 		Window:   window,
-		KeyFunc: func(r *http.Request) string {
-			return getRealIP(r)
-		},
+		KeyFunc:  IPKeyFunc,
 	})
 }
 
@@ -122,3 +171,35 @@ func AuthRateLimit() func(http.Handler) http.Handler {
 		},
 	})
 }
+
+// UserRateLimit creates rate limiting keyed by authenticated user ID, for
+// routes that should throttle per-account rather than per-IP.
+func UserRateLimit(requests int, window time.Duration) func(http.Handler) http.Handler {
+	return RateLimit(RateLimitConfig{
+		Requests: requests,
+		Window:   window,
+		KeyFunc:  UserKeyFunc,
+	})
+}
+
+// APIKeyRateLimit creates rate limiting keyed by the value of an API-key
+// header, for machine-to-machine routes.
+func APIKeyRateLimit(requests int, window time.Duration, header string) func(http.Handler) http.Handler {
+	return RateLimit(RateLimitConfig{
+		Requests: requests,
+		Window:   window,
+		KeyFunc:  APIKeyFunc(header),
+	})
+}
+
+// UserAndIPRateLimit creates rate limiting keyed by both the client IP and
+// (if authenticated) the user ID, using a token bucket so legitimate bursts
+// aren't punished as harshly as a hard sliding-window cutoff would.
+func UserAndIPRateLimit(requests int, window time.Duration) func(http.Handler) http.Handler {
+	return RateLimit(RateLimitConfig{
+		Requests:  requests,
+		Window:    window,
+		KeyFunc:   UserAndIPKeyFunc,
+		Algorithm: AlgorithmTokenBucket,
+	})
+}
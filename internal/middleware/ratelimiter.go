@@ -0,0 +1,290 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"connex/internal/cache"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// RateLimitBackend selects which RateLimiter implementation NewRateLimiter
+// builds.
+type RateLimitBackend string
+
+const (
+	// BackendMemory is an in-process token bucket. It's cheap and needs no
+	// dependencies, but each replica enforces its own independent limit.
+	BackendMemory RateLimitBackend = "memory"
+
+	// BackendRedis is shared across every replica via Redis, enforced
+	// atomically with a single Lua script per request; see RateLimitAlgorithm
+	// for which algorithm that script implements.
+	BackendRedis RateLimitBackend = "redis"
+)
+
+// RateLimitAlgorithm selects which Lua script RedisRateLimiter runs. Only
+// meaningful for BackendRedis; BackendMemory is always a token bucket.
+type RateLimitAlgorithm string
+
+const (
+	// AlgorithmSlidingWindow is a sliding-window log over a sorted set: exact
+	// (no boundary burst) but costs O(requests in window) memory per key.
+	AlgorithmSlidingWindow RateLimitAlgorithm = "sliding_window"
+
+	// AlgorithmTokenBucket refills `limit` tokens every `window` (so `limit`
+	// doubles as the burst size) and spends one per request: O(1) memory per
+	// key, and it smooths out traffic instead of allowing a full burst right
+	// at the start of every window.
+	AlgorithmTokenBucket RateLimitAlgorithm = "token_bucket"
+)
+
+// RateLimitResult is what a RateLimiter.Allow call reports back to the
+// middleware so it can set standard rate-limit headers on both the allow
+// and the deny path.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+}
+
+// RateLimiter decides whether key may make another request within limit
+// requests per window. Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error)
+}
+
+// NewRateLimiter builds the RateLimiter named by backend, running algorithm
+// (ignored for BackendMemory, which is always a token bucket). An
+// unrecognized or empty backend falls back to BackendMemory; an
+// unrecognized or empty algorithm falls back to AlgorithmSlidingWindow.
+func NewRateLimiter(backend RateLimitBackend, algorithm RateLimitAlgorithm) RateLimiter {
+	switch backend {
+	case BackendRedis:
+		return NewRedisRateLimiter(cache.Get(), algorithm)
+	default:
+		return NewMemoryRateLimiter()
+	}
+}
+
+// tokenBucket holds the per-key state for MemoryRateLimiter.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryRateLimiter is an in-process token bucket limiter: each key accrues
+// limit/window tokens per second, up to a cap of limit, and every allowed
+// request spends one. It does not coordinate across replicas.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewMemoryRateLimiter returns an empty MemoryRateLimiter.
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+func (m *MemoryRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	if limit <= 0 || window <= 0 {
+		return RateLimitResult{Allowed: true, Limit: limit}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit), last: now}
+		m.buckets[key] = b
+	}
+
+	refillRate := float64(limit) / window.Seconds()
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(limit), b.tokens+elapsed*refillRate)
+	b.last = now
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration((1 - b.tokens) / refillRate * float64(time.Second))
+		return RateLimitResult{Allowed: false, Limit: limit, Remaining: 0, ResetAfter: retryAfter}, nil
+	}
+
+	b.tokens--
+	resetAfter := time.Duration((float64(limit) - b.tokens) / refillRate * float64(time.Second))
+	return RateLimitResult{Allowed: true, Limit: limit, Remaining: int(b.tokens), ResetAfter: resetAfter}, nil
+}
+
+// slidingWindowScript implements a sliding-window log over a sorted set
+// keyed by ratelimit:{key}: it trims entries older than now-window, adds the
+// current request with a random member (so concurrent requests in the same
+// millisecond don't collide), counts the window, and refreshes the key's
+// TTL. All four steps run atomically in one round trip.
+const slidingWindowScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+redis.call('ZADD', key, now, member)
+redis.call('PEXPIRE', key, window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 1
+if count > limit then
+  allowed = 0
+end
+local remaining = limit - count
+if remaining < 0 then
+  remaining = 0
+end
+
+return {allowed, remaining, now + window}
+`
+
+// tokenBucketScript implements a token bucket in a Redis hash keyed by
+// ratelimit:{key}: it refills `tokens` by elapsed-time-since-last-refill *
+// rate (capped at burst), spends one token if available, and persists the
+// new state with a TTL long enough to fully refill (so an idle key expires
+// instead of lingering forever). Refill, spend, and persist all run
+// atomically in one round trip.
+const tokenBucketScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local data = redis.call('HMGET', key, 'tokens', 'last_refill')
+local tokens = tonumber(data[1])
+local last = tonumber(data[2])
+if tokens == nil then
+  tokens = burst
+  last = now
+end
+
+local elapsed = now - last
+if elapsed < 0 then
+  elapsed = 0
+end
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry_after = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HMSET', key, 'tokens', tokens, 'last_refill', now)
+redis.call('PEXPIRE', key, ttl)
+
+return {allowed, math.floor(tokens), retry_after}
+`
+
+// RedisRateLimiter is a distributed limiter shared by every replica, backed
+// by a single Lua script per request (see RateLimitAlgorithm) so the
+// check-and-increment is atomic under concurrent requests.
+type RedisRateLimiter struct {
+	client              *redis.Client
+	algorithm           RateLimitAlgorithm
+	slidingWindowScript *redis.Script
+	tokenBucketScript   *redis.Script
+}
+
+// NewRedisRateLimiter returns a RedisRateLimiter using client and running
+// algorithm. client may be nil (e.g. Redis hasn't been initialized yet);
+// Allow then fails closed by returning an error, letting callers decide
+// whether to fail open. An empty algorithm defaults to AlgorithmSlidingWindow.
+func NewRedisRateLimiter(client *redis.Client, algorithm RateLimitAlgorithm) *RedisRateLimiter {
+	if algorithm == "" {
+		algorithm = AlgorithmSlidingWindow
+	}
+	return &RedisRateLimiter{
+		client:              client,
+		algorithm:           algorithm,
+		slidingWindowScript: redis.NewScript(slidingWindowScript),
+		tokenBucketScript:   redis.NewScript(tokenBucketScript),
+	}
+}
+
+func (r *RedisRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	if r.client == nil {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: redis client not initialized")
+	}
+	if limit <= 0 || window <= 0 {
+		return RateLimitResult{Allowed: true, Limit: limit}, nil
+	}
+
+	switch r.algorithm {
+	case AlgorithmTokenBucket:
+		return r.allowTokenBucket(ctx, key, limit, window)
+	default:
+		return r.allowSlidingWindow(ctx, key, limit, window)
+	}
+}
+
+func (r *RedisRateLimiter) allowSlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	redisKey := fmt.Sprintf("ratelimit:%s", key)
+	nowMs := time.Now().UnixMilli()
+	windowMs := window.Milliseconds()
+	member := fmt.Sprintf("%d:%s", nowMs, uuid.NewString())
+
+	res, err := r.slidingWindowScript.Run(ctx, r.client, []string{redisKey}, nowMs, windowMs, limit, member).Result()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: script failed: %w", err)
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 3 {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: unexpected script result %v", res)
+	}
+	allowed, _ := vals[0].(int64)
+	remaining, _ := vals[1].(int64)
+	resetMs, _ := vals[2].(int64)
+
+	return RateLimitResult{
+		Allowed:    allowed == 1,
+		Limit:      limit,
+		Remaining:  int(remaining),
+		ResetAfter: time.Until(time.UnixMilli(resetMs)),
+	}, nil
+}
+
+func (r *RedisRateLimiter) allowTokenBucket(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	redisKey := fmt.Sprintf("ratelimit:%s", key)
+	nowMs := float64(time.Now().UnixMilli())
+	rate := float64(limit) / float64(window.Milliseconds()) // tokens per ms
+	ttlMs := window.Milliseconds() * 2
+
+	res, err := r.tokenBucketScript.Run(ctx, r.client, []string{redisKey}, nowMs, rate, limit, ttlMs).Result()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: script failed: %w", err)
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 3 {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: unexpected script result %v", res)
+	}
+	allowed, _ := vals[0].(int64)
+	remaining, _ := vals[1].(int64)
+	retryAfterMs, _ := vals[2].(int64)
+
+	return RateLimitResult{
+		Allowed:    allowed == 1,
+		Limit:      limit,
+		Remaining:  int(remaining),
+		ResetAfter: time.Duration(retryAfterMs) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"connex/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header RequestLogger reads an inbound request ID
+// from, propagating it through to downstream services and the response; it
+// generates one when the caller didn't set it.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogger stamps every request with a request ID and injects a child
+// logger carrying request_id/method/path/remote_ip into the request's
+// context, retrievable via logger.FromContext. Downstream middleware and
+// handlers should log through that context logger rather than
+// logger.GetGlobal() so every line for a request correlates with its access
+// log entry and telemetry span; auth.AuthMiddleware further enriches it
+// with user_id once the bearer token is resolved.
+func RequestLogger() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			reqLogger := logger.GetGlobal().Named("http").WithFields(map[string]interface{}{
+				"request_id": requestID,
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"remote_ip":  r.RemoteAddr,
+			})
+
+			ctx := logger.NewContext(r.Context(), reqLogger)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -1,42 +1,88 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"net/http"
 	"os"
-	"time"
+
+	"connex/pkg/security/authn"
 
 	"github.com/gorilla/csrf"
 )
 
-// SecureMetricsMiddleware protects the metrics endpoint in production
-func SecureMetricsMiddleware() func(http.Handler) http.Handler {
+type nonceContextKey struct{}
+
+// NonceFromContext returns the CSP nonce SecurityHeadersMiddleware generated
+// for the current request, and whether one was present. Downstream
+// renderers (see ssr.Handler) use this to emit `nonce="..."` attributes that
+// actually match the Content-Security-Policy header, rather than relying on
+// 'unsafe-inline'.
+func NonceFromContext(ctx context.Context) (string, bool) {
+	nonce, ok := ctx.Value(nonceContextKey{}).(string)
+	return nonce, ok
+}
+
+// CSRFTokenFromContext returns the CSRF token CSRFMiddleware generated for
+// the current request (via gorilla/csrf), or "" if the request never passed
+// through CSRFMiddleware. Handlers that need to embed the token outside SSR
+// (see ssr.Handler's WithCSRF) can use this instead of importing gorilla/csrf
+// directly.
+func CSRFTokenFromContext(r *http.Request) string {
+	return csrf.Token(r)
+}
+
+// SecureMetricsMiddleware protects the metrics endpoint in production by
+// running requests through chain (typically a Basic + OIDC authn.Chain; see
+// pkg/security/authn). A nil chain denies every request once ENV=production,
+// rather than silently falling open.
+func SecureMetricsMiddleware(chain *authn.Chain) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// In production, require authentication for metrics
-			if os.Getenv("ENV") == "production" {
-				// Check for API key or basic auth
-				apiKey := r.Header.Get("X-API-Key")
-				if apiKey == "" {
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
-					return
-				}
-
-				// Validate API key (in production, use proper secret management)
-				expectedKey := os.Getenv("METRICS_API_KEY")
-				if expectedKey == "" || apiKey != expectedKey {
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
-					return
-				}
+			if os.Getenv("ENV") != "production" {
+				next.ServeHTTP(w, r)
+				return
 			}
-
-			next.ServeHTTP(w, r)
+			if chain == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			chain.Middleware()(next).ServeHTTP(w, r)
 		})
 	}
 }
 
-// SecurityHeadersMiddleware adds security headers to responses
-func SecurityHeadersMiddleware() func(http.Handler) http.Handler {
+// securityHeadersConfig holds SecurityHeadersMiddleware's optional settings.
+type securityHeadersConfig struct {
+	hstsGate func() bool
+}
+
+// SecurityHeadersOption configures SecurityHeadersMiddleware.
+type SecurityHeadersOption func(*securityHeadersConfig)
+
+// WithHSTSGate only emits the Strict-Transport-Security header once ready
+// reports true, so a preload promise isn't made before a certificate
+// actually backs it (e.g. tls.Server.Ready, which flips true the first
+// time a certificate is served, whether newly issued or loaded from the
+// cache). Without this option, HSTS is
+// sent on every TLS request, which is correct for a manually-provisioned
+// certificate that's already valid at startup.
+func WithHSTSGate(ready func() bool) SecurityHeadersOption {
+	return func(c *securityHeadersConfig) {
+		c.hstsGate = ready
+	}
+}
+
+// SecurityHeadersMiddleware adds security headers to responses, including a
+// Content-Security-Policy built around a single per-request nonce (see
+// generateNonce/NonceFromContext) so script-src/style-src can drop
+// 'unsafe-inline' while still allowing the inline tags ssr.Handler renders.
+func SecurityHeadersMiddleware(opts ...SecurityHeadersOption) func(http.Handler) http.Handler {
+	cfg := &securityHeadersConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Security headers
@@ -51,15 +97,24 @@ func SecurityHeadersMiddleware() func(http.Handler) http.Handler {
 			w.Header().Set("Cross-Origin-Opener-Policy", "same-origin")
 			w.Header().Set("Cross-Origin-Resource-Policy", "same-origin")
 
-			// HSTS header (only for HTTPS)
-			if r.TLS != nil {
+			// HSTS header (only for HTTPS, and only once cfg.hstsGate says
+			// it's safe to promise, when one is configured)
+			if r.TLS != nil && (cfg.hstsGate == nil || cfg.hstsGate()) {
 				w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains; preload")
 			}
 
-			// Content Security Policy (strengthened)
+			nonce, err := generateNonce()
+			if err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			// Content Security Policy (strengthened): script-src and
+			// style-src share the same nonce, since ssr.Handler only has one
+			// to embed per request.
 			csp := "default-src 'self'; " +
-				"script-src 'self' 'nonce-" + generateNonce() + "'; " +
-				"style-src 'self' 'nonce-" + generateNonce() + "'; " +
+				"script-src 'self' 'nonce-" + nonce + "'; " +
+				"style-src 'self' 'nonce-" + nonce + "'; " +
 				"img-src 'self' data: https:; " +
 				"font-src 'self'; " +
 				"connect-src 'self'; " +
@@ -71,16 +126,21 @@ func SecurityHeadersMiddleware() func(http.Handler) http.Handler {
 				"upgrade-insecure-requests"
 			w.Header().Set("Content-Security-Policy", csp)
 
-			next.ServeHTTP(w, r)
+			ctx := context.WithValue(r.Context(), nonceContextKey{}, nonce)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// generateNonce creates a random nonce for CSP
-func generateNonce() string {
-	// In production, use crypto/rand for better entropy
-	// For now, use a simple implementation
-	return "nonce-" + fmt.Sprintf("%d", time.Now().UnixNano())
+// generateNonce returns a base64-encoded 128-bit random value, suitable for
+// a CSP nonce (RFC: at least 128 bits from a cryptographically secure
+// generator).
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
 }
 
 // NoCacheMiddleware prevents caching for sensitive endpoints
@@ -96,21 +156,16 @@ func NoCacheMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
-// CSRFMiddleware adds CSRF protection to state-changing requests
+// CSRFMiddleware adds CSRF protection to state-changing requests. gorilla/csrf's
+// Protect already safelists GET/HEAD/OPTIONS/TRACE from token verification
+// while still generating a token for them - which CSRFTokenFromContext/
+// ssr.Handler's WithCSRF need on the GET request that renders the page - so
+// it's applied unconditionally rather than gated by method here.
 func CSRFMiddleware(authKey []byte) func(http.Handler) http.Handler {
-	csrfMiddleware := csrf.Protect(authKey,
+	return csrf.Protect(authKey,
 		csrf.Secure(os.Getenv("ENV") == "production"),
 		csrf.Path("/"),
 	)
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodDelete || r.Method == http.MethodPatch {
-				csrfMiddleware(next).ServeHTTP(w, r)
-			} else {
-				next.ServeHTTP(w, r)
-			}
-		})
-	}
 }
 
 // RequestSizeLimitMiddleware limits the size of request bodies
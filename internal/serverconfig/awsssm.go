@@ -0,0 +1,191 @@
+package serverconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	if s := newAWSSSMSourceFromEnv(); s != nil {
+		RegisterSource("awsssm", s)
+	}
+}
+
+// AWSSSMSource reads env vars from AWS Systems Manager Parameter Store,
+// under a fixed path prefix (CONFIG_AWSSSM_PATH, default "/connex/"): a
+// parameter named "/connex/db-host" becomes DB_HOST. It calls SSM's
+// GetParametersByPath directly, SigV4-signed by hand, for the same reason
+// internal/config's AWS Secrets Manager provider does (see
+// internal/config/secret_awssm.go) - avoiding aws-sdk-go-v2 for a handful
+// of read-only calls. Registers itself only when AWS credentials and a
+// region are actually present in the environment.
+type AWSSSMSource struct {
+	pathPrefix      string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	httpClient      *http.Client
+}
+
+func newAWSSSMSourceFromEnv() *AWSSSMSource {
+	region := getEnvDefault("AWS_REGION", getEnvDefault("AWS_DEFAULT_REGION", ""))
+	accessKeyID := getEnvDefault("AWS_ACCESS_KEY_ID", "")
+	secretAccessKey := getEnvDefault("AWS_SECRET_ACCESS_KEY", "")
+	if region == "" || accessKeyID == "" || secretAccessKey == "" {
+		return nil
+	}
+	return &AWSSSMSource{
+		pathPrefix:      getEnvDefault("CONFIG_AWSSSM_PATH", "/connex/"),
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    getEnvDefault("AWS_SESSION_TOKEN", ""),
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *AWSSSMSource) Name() string { return "awsssm" }
+
+// Load pages through every parameter under s.pathPrefix and
+// setenvIfAbsent's its name with s.pathPrefix trimmed (upper-cased, "-" to
+// "_") to its decrypted value.
+func (s *AWSSSMSource) Load(ctx context.Context) error {
+	var nextToken string
+	for {
+		page, err := s.getParametersByPath(ctx, nextToken)
+		if err != nil {
+			return fmt.Errorf("awsssm: GetParametersByPath: %w", err)
+		}
+		for _, p := range page.Parameters {
+			name := strings.TrimPrefix(p.Name, s.pathPrefix)
+			envKey := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+			setenvIfAbsent(envKey, p.Value)
+		}
+		if page.NextToken == "" {
+			return nil
+		}
+		nextToken = page.NextToken
+	}
+}
+
+type ssmParameter struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type ssmGetParametersByPathResponse struct {
+	Parameters []ssmParameter `json:"Parameters"`
+	NextToken  string         `json:"NextToken"`
+}
+
+func (s *AWSSSMSource) getParametersByPath(ctx context.Context, nextToken string) (*ssmGetParametersByPathResponse, error) {
+	reqBody := map[string]interface{}{
+		"Path":           s.pathPrefix,
+		"Recursive":      true,
+		"WithDecryption": true,
+	}
+	if nextToken != "" {
+		reqBody["NextToken"] = nextToken
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	host := fmt.Sprintf("ssm.%s.amazonaws.com", s.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+host+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM.GetParametersByPath")
+	s.sign(req, payload, host)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var out ssmGetParametersByPathResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &out, nil
+}
+
+// sign adds the Authorization, X-Amz-Date, and (when present)
+// X-Amz-Security-Token headers SigV4 requires for the "ssm" service. Same
+// narrow, single-header-set implementation as
+// internal/config/secret_awssm.go's AWSSMProvider.sign.
+func (s *AWSSSMSource) sign(req *http.Request, payload []byte, host string) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("Host", host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	signedHeaders := "content-type;host;x-amz-date;x-amz-target"
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), host, amzDate)
+	if s.sessionToken != "" {
+		signedHeaders = "content-type;host;x-amz-date;x-amz-security-token;x-amz-target"
+		canonicalHeaders += "x-amz-security-token:" + s.sessionToken + "\n"
+	}
+	canonicalHeaders += "x-amz-target:" + req.Header.Get("X-Amz-Target") + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		"POST", "/", "", canonicalHeaders, signedHeaders, sha256Hex(payload),
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/ssm/aws4_request", dateStamp, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, credentialScope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := sigV4SigningKey(s.secretAccessKey, dateStamp, s.region, "ssm")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, credentialScope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sigV4SigningKey(secretKey, dateStamp, region, service string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+secretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, region)
+	kService := hmacSHA256(kRegion, service)
+	return hmacSHA256(kService, "aws4_request")
+}
@@ -0,0 +1,106 @@
+package serverconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterSource("gcp", newGCPMetadataSource())
+}
+
+// gcpMetadataAddr is GCE's link-local metadata server, per
+// https://cloud.google.com/compute/docs/metadata/overview.
+const gcpMetadataAddr = "http://metadata.google.internal/computeMetadata/v1"
+
+// GCPMetadataSource reads env vars out of GCE/GKE custom instance
+// metadata. Deployments set one custom metadata key per env var, named
+// with the CONFIG_GCP_METADATA_PREFIX prefix (default "connex-") stripped
+// and upper-cased, e.g. instance metadata "connex-db-host" becomes
+// DB_HOST. Keys are listed from the attributes directory's
+// newline-delimited listing and each value is then fetched on its own (see
+// listAttributes).
+type GCPMetadataSource struct {
+	prefix     string
+	httpClient *http.Client
+}
+
+func newGCPMetadataSource() *GCPMetadataSource {
+	return &GCPMetadataSource{
+		prefix:     getEnvDefault("CONFIG_GCP_METADATA_PREFIX", "connex-"),
+		httpClient: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+func (s *GCPMetadataSource) Name() string { return "gcp" }
+
+// Load lists every key under the instance's custom metadata attributes
+// and, for ones starting with s.prefix, sets the corresponding env var.
+// It's a no-op (not an error) off GCE, since the metadata server simply
+// won't be reachable.
+func (s *GCPMetadataSource) Load(ctx context.Context) error {
+	attrs, err := s.listAttributes(ctx)
+	if err != nil {
+		return nil
+	}
+
+	for key, value := range attrs {
+		if !strings.HasPrefix(key, s.prefix) {
+			continue
+		}
+		envKey := strings.ToUpper(strings.ReplaceAll(strings.TrimPrefix(key, s.prefix), "-", "_"))
+		setenvIfAbsent(envKey, value)
+	}
+	return nil
+}
+
+// listAttributes fetches every key under instance/attributes/ via its
+// newline-delimited directory listing, then fetches each value - simpler
+// and more portable than depending on the metadata server's alt=json mode
+// returning a flat, unnested object.
+func (s *GCPMetadataSource) listAttributes(ctx context.Context) (map[string]string, error) {
+	body, err := s.get(ctx, "/instance/attributes/")
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]string)
+	for _, key := range strings.Split(strings.TrimSpace(body), "\n") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		value, err := s.get(ctx, "/instance/attributes/"+key)
+		if err != nil {
+			continue
+		}
+		attrs[key] = value
+	}
+	return attrs, nil
+}
+
+func (s *GCPMetadataSource) get(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataAddr+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gcp: unexpected status %d for %s", resp.StatusCode, path)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
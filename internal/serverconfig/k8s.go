@@ -0,0 +1,61 @@
+package serverconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterSource("k8s", newK8sDownwardSource())
+}
+
+// K8sDownwardSource reads env vars from a Kubernetes Downward API volume:
+// a directory where each file's name is the env var key and its contents
+// (trimmed of a trailing newline) the value, e.g. a Secret or ConfigMap
+// projected at /etc/podinfo. It's the "file per key" convention the
+// Downward API and most CSI secret-store drivers share, rather than a
+// Kubernetes-client-go watch against the API server.
+type K8sDownwardSource struct {
+	dir string
+}
+
+func newK8sDownwardSource() *K8sDownwardSource {
+	return &K8sDownwardSource{dir: getEnvDefault("CONFIG_K8S_DIR", "/etc/podinfo")}
+}
+
+func (s *K8sDownwardSource) Name() string { return "k8s" }
+
+// Load reads every regular file directly under s.dir and setenvIfAbsent's
+// its name=contents, skipping dotfiles (..data symlinks and the like that
+// Kubernetes' atomic-writer projects alongside the real files).
+func (s *K8sDownwardSource) Load(_ context.Context) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("k8s: read %s: %w", s.dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("k8s: read %s: %w", entry.Name(), err)
+		}
+		setenvIfAbsent(entry.Name(), strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
+
+func getEnvDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
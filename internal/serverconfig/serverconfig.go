@@ -0,0 +1,99 @@
+// Package serverconfig layers cloud/platform-specific configuration
+// sources on top of internal/config, which stays limited to env vars and
+// optional local files so go test ./internal/config/... never has to
+// reach a real GCP/AWS/Kubernetes API. Each source just sets process env
+// vars before config.Load reads them, so adding a new source never
+// requires touching config's structs.
+package serverconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"connex/internal/config"
+)
+
+// ConfigSource contributes environment variables to the process before
+// config.Load reads them. Implementations should only set a variable via
+// os.Setenv if it isn't already set (see setenvIfAbsent), so earlier
+// sources and the process's own environment always win over later ones.
+type ConfigSource interface {
+	// Name identifies this source in CONFIG_SOURCES and log output.
+	Name() string
+
+	// Load fetches this source's key/value pairs and applies them to the
+	// process environment.
+	Load(ctx context.Context) error
+}
+
+// sources is the registry ConfigSource plugins add themselves to, keyed
+// by the name operators use in CONFIG_SOURCES. "env" is registered as a
+// no-op: the process environment (and .env file) that config.Load reads
+// directly is already a source in its own right.
+var sources = map[string]ConfigSource{
+	"env": noopSource{},
+}
+
+// RegisterSource adds (or replaces) the source used for name. Call this
+// from an init() in a file that build-tags in the cloud SDK it needs, so a
+// binary that never imports that file doesn't pay for the dependency.
+func RegisterSource(name string, s ConfigSource) {
+	sources[name] = s
+}
+
+// noopSource backs the "env" entry in CONFIG_SOURCES; it exists so
+// listing "env" alongside real sources (e.g. "k8s,env") is valid instead
+// of erroring.
+type noopSource struct{}
+
+func (noopSource) Name() string                 { return "env" }
+func (noopSource) Load(_ context.Context) error { return nil }
+
+// SourcesFromEnv parses CONFIG_SOURCES (comma-separated, e.g.
+// "k8s,awsssm,env") into an ordered source name list. An unset or empty
+// CONFIG_SOURCES returns nil, meaning "no extra sources" - Load then
+// behaves exactly like config.Load().
+func SourcesFromEnv() []string {
+	raw := os.Getenv("CONFIG_SOURCES")
+	if raw == "" {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Load applies each named source in order - so later ones can still fill
+// in what earlier ones left unset - then delegates to config.Load for the
+// usual defaulting, secret-ref resolution, and validation.
+func Load(ctx context.Context, names []string) (*config.Config, error) {
+	for _, name := range names {
+		source, ok := sources[name]
+		if !ok {
+			return nil, fmt.Errorf("serverconfig: no source registered for %q", name)
+		}
+		if err := source.Load(ctx); err != nil {
+			return nil, fmt.Errorf("serverconfig: source %q: %w", name, err)
+		}
+	}
+	return config.Load()
+}
+
+// setenvIfAbsent sets key=value unless the process environment already
+// has a (non-empty) value for key, preserving the precedence documented
+// on ConfigSource.
+func setenvIfAbsent(key, value string) {
+	if value == "" {
+		return
+	}
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return
+	}
+	os.Setenv(key, value)
+}
@@ -0,0 +1,107 @@
+// Package storage wraps an S3-compatible object storage backend (MinIO or
+// AWS S3) for pre-signed uploads/downloads and admin bucket introspection.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"connex/internal/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Client wraps a *minio.Client bound to a single bucket.
+type Client struct {
+	mc     *minio.Client
+	bucket string
+}
+
+// New connects a Client to cfg's backend. It doesn't create the bucket -
+// operators are expected to provision it (and any lifecycle policy)
+// themselves, the same way db.Manager.Register doesn't create databases.
+func New(cfg config.StorageConfig) (*Client, error) {
+	mc, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: connect: %w", err)
+	}
+	return &Client{mc: mc, bucket: cfg.Bucket}, nil
+}
+
+// PresignPut returns a pre-signed POST policy URL and form fields (under
+// which contentType is enforced) an authenticated client can use to upload
+// directly to key, valid for expires.
+func (c *Client) PresignPut(ctx context.Context, key, contentType string, expires time.Duration) (string, map[string]string, error) {
+	policy := minio.NewPostPolicy()
+	if err := policy.SetBucket(c.bucket); err != nil {
+		return "", nil, fmt.Errorf("storage: presign put: %w", err)
+	}
+	if err := policy.SetKey(key); err != nil {
+		return "", nil, fmt.Errorf("storage: presign put: %w", err)
+	}
+	if err := policy.SetExpires(time.Now().UTC().Add(expires)); err != nil {
+		return "", nil, fmt.Errorf("storage: presign put: %w", err)
+	}
+	if contentType != "" {
+		if err := policy.SetContentType(contentType); err != nil {
+			return "", nil, fmt.Errorf("storage: presign put: %w", err)
+		}
+	}
+
+	u, formData, err := c.mc.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return "", nil, fmt.Errorf("storage: presign put: %w", err)
+	}
+	return u.String(), formData, nil
+}
+
+// PresignGet returns a pre-signed URL a client can GET key's content from
+// directly, valid for expires.
+func (c *Client) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	u, err := c.mc.PresignedGetObject(ctx, c.bucket, key, expires, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("storage: presign get: %w", err)
+	}
+	return u.String(), nil
+}
+
+// StatObject confirms key exists in the bucket, for /uploads/complete to
+// verify the client actually finished its presigned upload before
+// transitioning the upload record to ready.
+func (c *Client) StatObject(ctx context.Context, key string) (minio.ObjectInfo, error) {
+	info, err := c.mc.StatObject(ctx, c.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return minio.ObjectInfo{}, fmt.Errorf("storage: stat %q: %w", key, err)
+	}
+	return info, nil
+}
+
+// BucketStats is the bucket-wide object count/size summary admin.Handler's
+// /admin/system and /admin/metrics endpoints report.
+type BucketStats struct {
+	ObjectCount int   `json:"object_count"`
+	TotalBytes  int64 `json:"total_bytes"`
+}
+
+// BucketStats sums every object's size in the bucket via ListObjects.
+// MinIO/S3 don't expose a cheaper aggregate for this, so cost scales with
+// object count - acceptable for an admin dashboard polled infrequently, but
+// not something to call on a hot path.
+func (c *Client) BucketStats(ctx context.Context) (BucketStats, error) {
+	var stats BucketStats
+	for obj := range c.mc.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return BucketStats{}, fmt.Errorf("storage: list objects: %w", obj.Err)
+		}
+		stats.ObjectCount++
+		stats.TotalBytes += obj.Size
+	}
+	return stats, nil
+}
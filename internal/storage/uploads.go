@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"connex/internal/db"
+)
+
+// UploadStatus tracks an Upload's lifecycle from presign to post-processing.
+type UploadStatus string
+
+const (
+	// UploadPending is set when /uploads/presign issues a URL, before the
+	// client has actually uploaded anything.
+	UploadPending UploadStatus = "pending"
+	// UploadReady is set once /uploads/complete has verified the object
+	// exists in the bucket.
+	UploadReady UploadStatus = "ready"
+)
+
+// Upload is a single presigned-upload record.
+type Upload struct {
+	ID          int64        `json:"id" db:"id"`
+	Key         string       `json:"key" db:"key"`
+	UserID      int64        `json:"user_id" db:"user_id"`
+	ContentType string       `json:"content_type" db:"content_type"`
+	Status      UploadStatus `json:"status" db:"status"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// UserUploadCount is one row of admin.Handler's per-user upload counters.
+type UserUploadCount struct {
+	UserID int64 `json:"user_id"`
+	Count  int   `json:"count"`
+}
+
+// UploadStore persists Upload records across the presign/complete flow.
+type UploadStore interface {
+	// Create records a new pending upload for userID.
+	Create(ctx context.Context, userID int64, key, contentType string) (Upload, error)
+
+	// Get returns key's upload record, for callers that need to check e.g.
+	// ownership before acting on it.
+	Get(ctx context.Context, key string) (Upload, error)
+
+	// MarkReady transitions key's upload record to ready.
+	MarkReady(ctx context.Context, key string) error
+
+	// CountByUser returns how many uploads userID has made, for
+	// admin.Handler's per-user upload counters.
+	CountByUser(ctx context.Context, userID int64) (int, error)
+
+	// TopUploaders returns the limit users with the most uploads, most
+	// first, for admin.Handler's metrics endpoint.
+	TopUploaders(ctx context.Context, limit int) ([]UserUploadCount, error)
+}
+
+// PostgresUploadStore is an UploadStore backed by the uploads table.
+type PostgresUploadStore struct {
+	db *db.Manager
+}
+
+// NewPostgresUploadStore builds a PostgresUploadStore resolving pools from
+// manager.
+func NewPostgresUploadStore(manager *db.Manager) *PostgresUploadStore {
+	return &PostgresUploadStore{db: manager}
+}
+
+func (s *PostgresUploadStore) Create(ctx context.Context, userID int64, key, contentType string) (Upload, error) {
+	pool, err := s.db.Resolve(ctx)
+	if err != nil {
+		return Upload{}, err
+	}
+
+	const q = `INSERT INTO uploads (key, user_id, content_type, status)
+	      VALUES ($1, $2, $3, $4)
+	      RETURNING id, created_at, updated_at`
+	up := Upload{Key: key, UserID: userID, ContentType: contentType, Status: UploadPending}
+	row := pool.QueryRowxContext(ctx, q, key, userID, contentType, UploadPending)
+	if err := row.Scan(&up.ID, &up.CreatedAt, &up.UpdatedAt); err != nil {
+		return Upload{}, fmt.Errorf("create upload: %w", err)
+	}
+	return up, nil
+}
+
+func (s *PostgresUploadStore) Get(ctx context.Context, key string) (Upload, error) {
+	pool, err := s.db.Resolve(ctx)
+	if err != nil {
+		return Upload{}, err
+	}
+
+	const q = `SELECT id, key, user_id, content_type, status, created_at, updated_at
+	      FROM uploads WHERE key = $1`
+	var up Upload
+	if err := pool.QueryRowxContext(ctx, q, key).Scan(
+		&up.ID, &up.Key, &up.UserID, &up.ContentType, &up.Status, &up.CreatedAt, &up.UpdatedAt,
+	); err != nil {
+		return Upload{}, fmt.Errorf("get upload %q: %w", key, err)
+	}
+	return up, nil
+}
+
+func (s *PostgresUploadStore) MarkReady(ctx context.Context, key string) error {
+	pool, err := s.db.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	const q = `UPDATE uploads SET status = $2, updated_at = NOW() WHERE key = $1`
+	res, err := pool.ExecContext(ctx, q, key, UploadReady)
+	if err != nil {
+		return fmt.Errorf("mark upload ready %q: %w", key, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("upload %q not found", key)
+	}
+	return nil
+}
+
+func (s *PostgresUploadStore) CountByUser(ctx context.Context, userID int64) (int, error) {
+	pool, err := s.db.Resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	const q = `SELECT count(*) FROM uploads WHERE user_id = $1`
+	var count int
+	if err := pool.QueryRowxContext(ctx, q, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count uploads for user %d: %w", userID, err)
+	}
+	return count, nil
+}
+
+func (s *PostgresUploadStore) TopUploaders(ctx context.Context, limit int) ([]UserUploadCount, error) {
+	pool, err := s.db.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `SELECT user_id, count(*) AS count FROM uploads
+	      GROUP BY user_id ORDER BY count DESC, user_id LIMIT $1`
+	rows, err := pool.QueryxContext(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("top uploaders: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []UserUploadCount
+	for rows.Next() {
+		var c UserUploadCount
+		if err := rows.Scan(&c.UserID, &c.Count); err != nil {
+			return nil, fmt.Errorf("top uploaders: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
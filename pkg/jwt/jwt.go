@@ -1,17 +1,48 @@
 package jwt
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var defaultExpiration = 24 * time.Hour
+// GenerateJWT mints the legacy HS256 Register/Login access token, carrying a
+// `typ: "access"` claim so AuthMiddleware can reject a refresh token
+// presented in its place. sid, if non-empty, is embedded as the `sid` claim
+// so AuthMiddleware can look up the corresponding auth.SessionStore entry;
+// pass "" for flows with no session tracking.
+func GenerateJWT(userID int64, secret string, sid string, expiration time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"typ": "access",
+		"exp": time.Now().Add(expiration).Unix(),
+		"jti": uuid.NewString(),
+	}
+	if sid != "" {
+		claims["sid"] = sid
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
 
-func GenerateJWT(userID int64, secret string) (string, error) {
+// GenerateRefreshJWT mints the long-lived counterpart to GenerateJWT, carrying
+// a `typ: "refresh"` claim and its own independent `jti` so it can be
+// denylisted (via auth.TokenStore) without affecting the access token's jti.
+// sid matches the paired access token's session, so a rotated or reused
+// refresh token can be traced back to (and revoke) that session.
+func GenerateRefreshJWT(userID int64, secret string, sid string, expiration time.Duration) (string, error) {
 	claims := jwt.MapClaims{
 		"sub": userID,
-		"exp": time.Now().Add(defaultExpiration).Unix(),
+		"typ": "refresh",
+		"exp": time.Now().Add(expiration).Unix(),
+		"jti": uuid.NewString(),
+	}
+	if sid != "" {
+		claims["sid"] = sid
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
@@ -32,3 +63,231 @@ func ParseJWT(tokenStr, secret string) (jwt.MapClaims, error) {
 	}
 	return nil, jwt.ErrSignatureInvalid
 }
+
+// IssueOptions customizes the claims set by GenerateSignedJWT.
+type IssueOptions struct {
+	Issuer      string
+	Audience    string
+	Subject     string
+	Expiration  time.Duration
+	ExtraClaims map[string]interface{}
+}
+
+// GenerateSignedJWT mints a JWT with standard `iss`/`aud`/`sub`/`iat`/`exp`/`jti`
+// claims signed with the keyring's currently active key, for issuers (like the
+// OAuth2 token endpoint) that need asymmetric signatures and a stable `kid`.
+func GenerateSignedJWT(kr *Keyring, opts IssueOptions) (string, string, error) {
+	kp, err := kr.Active()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now().UTC()
+	jti := uuid.NewString()
+	claims := jwt.MapClaims{
+		"iss": opts.Issuer,
+		"aud": opts.Audience,
+		"sub": opts.Subject,
+		"iat": now.Unix(),
+		"exp": now.Add(opts.Expiration).Unix(),
+		"jti": jti,
+	}
+	for k, v := range opts.ExtraClaims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kp.KID
+	signed, err := token.SignedString(kp.PrivateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, jti, nil
+}
+
+const (
+	// DefaultAccessTokenTTL and DefaultRefreshTokenTTL are GenerateTokenPair's
+	// default lifetimes.
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenPair is an access/refresh token pair minted by GenerateTokenPair or
+// rotated by RefreshTokens.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	AccessJTI    string
+	RefreshJTI   string
+}
+
+// GenerateTokenPair mints an access/refresh token pair for userID, both
+// signed with kr's active key. The access token carries `nbf`, `jti`, `kid`
+// (via the header), and `roles`, with DefaultAccessTokenTTL; the refresh
+// token is a separate signed JWT with its own `jti` and `fam` (a rotation
+// family id used by RefreshTokens to detect reuse), `typ: "refresh"`, and
+// DefaultRefreshTokenTTL.
+func GenerateTokenPair(kr *Keyring, userID int64, roles []string) (TokenPair, error) {
+	return generateTokenPair(kr, userID, roles, uuid.NewString())
+}
+
+func generateTokenPair(kr *Keyring, userID int64, roles []string, family string) (TokenPair, error) {
+	subject := strconv.FormatInt(userID, 10)
+	now := time.Now().UTC()
+
+	access, accessJTI, err := GenerateSignedJWT(kr, IssueOptions{
+		Subject:    subject,
+		Expiration: DefaultAccessTokenTTL,
+		ExtraClaims: map[string]interface{}{
+			"typ":   "access",
+			"nbf":   now.Unix(),
+			"roles": roles,
+		},
+	})
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("generate access token: %w", err)
+	}
+
+	refresh, refreshJTI, err := GenerateSignedJWT(kr, IssueOptions{
+		Subject:    subject,
+		Expiration: DefaultRefreshTokenTTL,
+		ExtraClaims: map[string]interface{}{
+			"typ": "refresh",
+			"nbf": now.Unix(),
+			"fam": family,
+		},
+	})
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		AccessJTI:    accessJTI,
+		RefreshJTI:   refreshJTI,
+	}, nil
+}
+
+// RolesFromClaims extracts the `roles` claim GenerateTokenPair sets on an
+// access token, for authorization middleware consuming a parsed token's
+// claims. It returns nil if the claim is absent or isn't a string array
+// (e.g. a token minted before roles support existed).
+func RolesFromClaims(claims jwt.MapClaims) []string {
+	raw, ok := claims["roles"].([]interface{})
+	if !ok {
+		return nil
+	}
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok {
+			roles = append(roles, s)
+		}
+	}
+	return roles
+}
+
+// Revoker is satisfied by any jti-keyed revocation store — notably
+// auth.TokenStore already matches this shape — letting RefreshTokens check
+// and record revocations without pkg/jwt depending on internal/api/auth.
+type Revoker interface {
+	Revoke(ctx context.Context, jti string, exp time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// ErrRefreshReused is returned by RefreshTokens when a previously rotated
+// (and therefore already-revoked) refresh token is presented again,
+// indicating it may have been stolen.
+var ErrRefreshReused = fmt.Errorf("refresh token reuse detected")
+
+// familyRevocationKey namespaces a rotation family's "the whole chain is
+// compromised" marker inside the same jti-keyed Revoker used for individual
+// tokens, so no separate storage is needed for family-wide revocation.
+func familyRevocationKey(family string) string {
+	return "fam:" + family
+}
+
+// RefreshTokens verifies refreshToken, then rotates it: the presented
+// token's jti is revoked (one-time use) and a brand-new pair is issued in
+// the same rotation family. If refreshToken's jti was already revoked —
+// meaning it was presented a second time — that's reuse of a stolen token,
+// so the entire family is revoked via revoker and ErrRefreshReused is
+// returned. revoker may be nil to skip revocation/reuse checks entirely.
+func RefreshTokens(ctx context.Context, kr *Keyring, revoker Revoker, refreshToken string, roles []string) (TokenPair, error) {
+	claims, err := ParseSignedJWT(kr, refreshToken)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("refresh: %w", err)
+	}
+	if typ, _ := claims["typ"].(string); typ != "refresh" {
+		return TokenPair{}, fmt.Errorf("refresh: not a refresh token")
+	}
+
+	jti, _ := claims["jti"].(string)
+	family, _ := claims["fam"].(string)
+	subject, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("refresh: invalid subject claim: %w", err)
+	}
+
+	if revoker != nil {
+		if family != "" {
+			if revoked, err := revoker.IsRevoked(ctx, familyRevocationKey(family)); err == nil && revoked {
+				return TokenPair{}, ErrRefreshReused
+			}
+		}
+		if jti != "" {
+			revoked, err := revoker.IsRevoked(ctx, jti)
+			if err != nil {
+				return TokenPair{}, fmt.Errorf("refresh: check revocation: %w", err)
+			}
+			if revoked {
+				if family != "" {
+					_ = revoker.Revoke(ctx, familyRevocationKey(family), time.Now().Add(DefaultRefreshTokenTTL))
+				}
+				return TokenPair{}, ErrRefreshReused
+			}
+			if err := revoker.Revoke(ctx, jti, expiryFromClaims(claims)); err != nil {
+				return TokenPair{}, fmt.Errorf("refresh: mark token used: %w", err)
+			}
+		}
+	}
+
+	return generateTokenPair(kr, userID, roles, family)
+}
+
+// expiryFromClaims reads the standard `exp` claim (seconds since epoch) as a
+// time.Time, defaulting to DefaultRefreshTokenTTL from now if it's missing
+// or malformed.
+func expiryFromClaims(claims jwt.MapClaims) time.Time {
+	if exp, ok := claims["exp"].(float64); ok {
+		return time.Unix(int64(exp), 0)
+	}
+	return time.Now().Add(DefaultRefreshTokenTTL)
+}
+
+// ParseSignedJWT verifies a JWT signed by GenerateSignedJWT, resolving the
+// verification key from the token's `kid` header against the keyring.
+func ParseSignedJWT(kr *Keyring, tokenStr string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("token missing kid header")
+		}
+		kp, ok := kr.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		return kp.PublicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, jwt.ErrSignatureInvalid
+}
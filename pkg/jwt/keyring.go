@@ -0,0 +1,174 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KeyPair is a single signing key identified by a `kid`, used for asymmetric
+// JWT signing (EdDSA) and JWKS publication.
+type KeyPair struct {
+	KID        string
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// Keyring holds a set of signing keys and the currently active one. Old keys
+// are kept around (but not used for new signatures) so tokens signed before a
+// rotation still verify until they expire.
+type Keyring struct {
+	mu        sync.RWMutex
+	keys      map[string]*KeyPair
+	activeKID string
+
+	// retiredAt holds the grace-window deadline for each retired kid (see
+	// Retire); Lookup rejects a kid once its deadline has passed.
+	retiredAt map[string]time.Time
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]*KeyPair), retiredAt: make(map[string]time.Time)}
+}
+
+// GenerateKeyPair creates a new random Ed25519 key pair with the given kid.
+func GenerateKeyPair(kid string) (*KeyPair, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	return &KeyPair{KID: kid, PrivateKey: priv, PublicKey: pub}, nil
+}
+
+// KeyPairFromSeed derives a deterministic Ed25519 key pair from a 32-byte
+// seed, for callers (e.g. the OAuth2 server's startup wiring) that need the
+// same signing key back across a restart instead of GenerateKeyPair's fresh
+// random key every time.
+func KeyPairFromSeed(kid string, seed []byte) (*KeyPair, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("jwt: key seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+	return &KeyPair{KID: kid, PrivateKey: priv, PublicKey: pub}, nil
+}
+
+// AddKey registers a key pair under the keyring. The first key added becomes
+// active by default.
+func (k *Keyring) AddKey(kp *KeyPair) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[kp.KID] = kp
+	if k.activeKID == "" {
+		k.activeKID = kp.KID
+	}
+}
+
+// SetActive marks kid as the key used to sign new tokens. The kid must
+// already have been added via AddKey.
+func (k *Keyring) SetActive(kid string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[kid]; !ok {
+		return fmt.Errorf("unknown kid: %s", kid)
+	}
+	k.activeKID = kid
+	return nil
+}
+
+// Active returns the key pair currently used to sign new tokens.
+func (k *Keyring) Active() (*KeyPair, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.activeKID == "" {
+		return nil, fmt.Errorf("keyring has no active key")
+	}
+	return k.keys[k.activeKID], nil
+}
+
+// Lookup returns the key pair for a given kid, used to verify a token's
+// signature regardless of whether that kid is still active. It returns
+// ok=false for an unknown kid, or one that was Retired and whose grace
+// window has elapsed.
+func (k *Keyring) Lookup(kid string) (*KeyPair, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if deadline, retired := k.retiredAt[kid]; retired && time.Now().After(deadline) {
+		return nil, false
+	}
+	kp, ok := k.keys[kid]
+	return kp, ok
+}
+
+// Retire marks kid as no longer eligible to sign new tokens, giving tokens
+// already signed with it grace to still verify until grace elapses. kid
+// must not be the currently active signing key; call SetActive with a
+// replacement first.
+func (k *Keyring) Retire(kid string, grace time.Duration) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[kid]; !ok {
+		return fmt.Errorf("unknown kid: %s", kid)
+	}
+	if kid == k.activeKID {
+		return fmt.Errorf("cannot retire the active signing key %s; call SetActive first", kid)
+	}
+	k.retiredAt[kid] = time.Now().Add(grace)
+	return nil
+}
+
+// Prune permanently removes every retired key whose grace window has
+// elapsed, so JWKS stops publishing them and Lookup no longer has anything
+// to reject. Safe to call periodically, e.g. from the same ticker that
+// drives key rotation.
+func (k *Keyring) Prune() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	for kid, deadline := range k.retiredAt {
+		if now.After(deadline) {
+			delete(k.keys, kid)
+			delete(k.retiredAt, kid)
+		}
+	}
+}
+
+// JWK is the JSON representation of a single public key, per RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+}
+
+// JWKSet is the `/.well-known/jwks.json` response body.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS builds the public JWK Set for every key currently held in the
+// keyring, active or retired, so clients can still verify tokens signed
+// before the last rotation.
+func (k *Keyring) JWKS() JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(k.keys))}
+	for _, kp := range k.keys {
+		set.Keys = append(set.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(kp.PublicKey),
+			Use: "sig",
+			Kid: kp.KID,
+			Alg: "EdDSA",
+		})
+	}
+	return set
+}
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPairFromSeed_Deterministic(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+
+	kp1, err := KeyPairFromSeed("default", seed)
+	require.NoError(t, err)
+	kp2, err := KeyPairFromSeed("default", seed)
+	require.NoError(t, err)
+
+	assert.Equal(t, kp1.PublicKey, kp2.PublicKey)
+	assert.Equal(t, kp1.PrivateKey, kp2.PrivateKey)
+}
+
+func TestKeyPairFromSeed_RejectsWrongLength(t *testing.T) {
+	_, err := KeyPairFromSeed("default", make([]byte, 16))
+	assert.Error(t, err)
+}
+
+func TestKeyPairFromSeed_PublishesInJWKS(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	kp, err := KeyPairFromSeed("default", seed)
+	require.NoError(t, err)
+
+	kr := NewKeyring()
+	kr.AddKey(kp)
+
+	active, err := kr.Active()
+	require.NoError(t, err)
+	assert.Equal(t, "default", active.KID)
+
+	jwks := kr.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "default", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+}
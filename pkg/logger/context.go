@@ -0,0 +1,24 @@
+package logger
+
+import "context"
+
+type contextKey string
+
+const loggerContextKey contextKey = "logger"
+
+// NewContext returns a copy of ctx carrying l, retrievable with FromContext.
+// Middleware (see the RequestLogger middleware in internal/middleware) uses
+// this to thread a request-scoped child logger through r.Context().
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the logger stashed in ctx by NewContext, or the
+// global logger if ctx carries none. Callers can use this unconditionally
+// instead of checking for a request-scoped logger first.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*Logger); ok && l != nil {
+		return l
+	}
+	return GetGlobal()
+}
@@ -10,6 +10,10 @@ type Logger struct {
 	*zap.Logger
 }
 
+// ringBufferSize bounds how many recent log entries GetRingBuffer's tap
+// retains for /admin/logs; older entries are overwritten.
+const ringBufferSize = 500
+
 // New creates a new logger instance
 func New(level string, env string) (*Logger, error) {
 	var config zap.Config
@@ -29,10 +33,15 @@ func New(level string, env string) (*Logger, error) {
 	config.Level = zap.NewAtomicLevelAt(logLevel)
 
 	// Create logger
-	logger, err := config.Build()
+	var ring *RingBuffer
+	logger, err := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		ring = NewRingBuffer(core, ringBufferSize)
+		return ring
+	}))
 	if err != nil {
 		return nil, err
 	}
+	globalRingBuffer = ring
 
 	return &Logger{logger}, nil
 }
@@ -56,6 +65,13 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	return &Logger{l.Logger.With(zapFields...)}
 }
 
+// Named returns a sub-logger carrying component as its logger name (e.g.
+// "http", "websocket"), so log lines can be filtered by subsystem. Repeated
+// calls compose, matching zap.Logger.Named's "." joining.
+func (l *Logger) Named(component string) *Logger {
+	return &Logger{l.Logger.Named(component)}
+}
+
 // Sync flushes any buffered log entries
 func (l *Logger) Sync() error {
 	return l.Logger.Sync()
@@ -64,6 +80,18 @@ func (l *Logger) Sync() error {
 // Global logger instance
 var globalLogger *Logger
 
+// globalRingBuffer is the ring-buffer tap installed on whichever logger New
+// most recently built (including via InitGlobal), or nil before that's
+// happened once.
+var globalRingBuffer *RingBuffer
+
+// GetRingBuffer returns the ring buffer tapping the global logger's recent
+// entries (see internal/api/admin's LogSource/ZapTapLogSource), or nil if no
+// logger has been built yet.
+func GetRingBuffer() *RingBuffer {
+	return globalRingBuffer
+}
+
 // InitGlobal initializes the global logger
 func InitGlobal(level string, env string) error {
 	logger, err := New(level, env)
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is one captured log line, detailed enough for a consumer like
+// internal/api/admin's LogSource to render without re-parsing zap output.
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// ringStore is the fixed-size circular buffer backing a RingBuffer and every
+// core derived from it via With, so logger.With(...) sub-loggers still tap
+// into the same buffer instead of starting a new one.
+type ringStore struct {
+	mu   sync.Mutex
+	buf  []Entry
+	next int
+	full bool
+}
+
+func (s *ringStore) add(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf[s.next] = e
+	s.next = (s.next + 1) % len(s.buf)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// snapshot returns up to n of the most recent entries, oldest first, in a
+// freshly allocated slice safe for the caller to keep. n <= 0 returns
+// everything currently buffered.
+func (s *ringStore) snapshot(n int) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var all []Entry
+	if s.full {
+		all = append(all, s.buf[s.next:]...)
+		all = append(all, s.buf[:s.next]...)
+	} else {
+		all = append(all, s.buf[:s.next]...)
+	}
+
+	if n > 0 && n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
+
+// RingBuffer is a zapcore.Core that taps every entry written through it into
+// a fixed-size circular buffer, in addition to passing it through to the
+// wrapped core unchanged. Install it with zap.WrapCore (see New) so
+// /admin/logs can read recent application logs without scraping stdout or a
+// log file.
+type RingBuffer struct {
+	zapcore.Core
+	store  *ringStore
+	fields []zapcore.Field
+}
+
+// NewRingBuffer wraps core, tapping up to size recent entries.
+func NewRingBuffer(core zapcore.Core, size int) *RingBuffer {
+	return &RingBuffer{
+		Core:  core,
+		store: &ringStore{buf: make([]Entry, size)},
+	}
+}
+
+// With satisfies zapcore.Core; the returned core shares this one's ring
+// buffer so fields attached further down the logger tree (e.g. via
+// Logger.Named/With) are still captured.
+func (r *RingBuffer) With(fields []zapcore.Field) zapcore.Core {
+	return &RingBuffer{
+		Core:   r.Core.With(fields),
+		store:  r.store,
+		fields: append(append([]zapcore.Field{}, r.fields...), fields...),
+	}
+}
+
+// Check satisfies zapcore.Core, registering this core so Write is called
+// whenever the wrapped core would log the entry.
+func (r *RingBuffer) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if r.Core.Enabled(ent.Level) {
+		return ce.AddCore(ent, r)
+	}
+	return ce
+}
+
+// Write taps ent into the ring buffer, then delegates to the wrapped core.
+func (r *RingBuffer) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range r.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	r.store.add(Entry{
+		Time:    ent.Time,
+		Level:   ent.Level.String(),
+		Message: ent.Message,
+		Fields:  enc.Fields,
+	})
+
+	return r.Core.Write(ent, fields)
+}
+
+// Entries returns up to n of the most recent captured log entries, oldest
+// first. n <= 0 returns everything currently buffered.
+func (r *RingBuffer) Entries(n int) []Entry {
+	return r.store.snapshot(n)
+}
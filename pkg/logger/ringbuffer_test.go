@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRingBuffer_EntriesReturnsMostRecentOldestFirst(t *testing.T) {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{}), zapcore.AddSync(io.Discard), zapcore.DebugLevel)
+	ring := NewRingBuffer(core, 3)
+	logger := zap.New(ring)
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+	logger.Info("four")
+
+	entries := ring.Entries(0)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "two", entries[0].Message)
+	assert.Equal(t, "three", entries[1].Message)
+	assert.Equal(t, "four", entries[2].Message)
+}
+
+func TestRingBuffer_EntriesRespectsN(t *testing.T) {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{}), zapcore.AddSync(io.Discard), zapcore.DebugLevel)
+	ring := NewRingBuffer(core, 10)
+	logger := zap.New(ring)
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+
+	entries := ring.Entries(2)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "two", entries[0].Message)
+	assert.Equal(t, "three", entries[1].Message)
+}
+
+func TestRingBuffer_WithCarriesFieldsIntoEntries(t *testing.T) {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{}), zapcore.AddSync(io.Discard), zapcore.DebugLevel)
+	ring := NewRingBuffer(core, 5)
+	logger := zap.New(ring).With(zap.String("component", "test"))
+
+	logger.Info("hello")
+
+	entries := ring.Entries(0)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "test", entries[0].Fields["component"])
+}
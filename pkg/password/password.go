@@ -0,0 +1,133 @@
+// Package password hashes and verifies user passwords with Argon2id,
+// storing hashes in PHC string format
+// ($argon2id$v=19$m=65536,t=3,p=2$salt$hash) so the memory/time/parallelism
+// profile can evolve without breaking already-stored hashes, and a
+// compatibility path still verifies legacy bcrypt hashes so existing users
+// aren't locked out.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Params is an Argon2id cost profile.
+type Params struct {
+	Memory      uint32 // KiB
+	Time        uint32 // iterations
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams is the profile Hash uses and the baseline Verify compares
+// stored params against to decide whether a hash needsRehash.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Time:        3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// pepper is mixed into every password before hashing/verifying, on top of
+// the per-hash salt, so a stolen password database alone isn't enough to
+// brute-force it; the pepper itself lives outside the database (config/env,
+// see SetPepper). Legacy bcrypt hashes predate the pepper and are verified
+// without it.
+var pepper string
+
+// SetPepper sets the server-side pepper. Call once at startup, before any
+// Hash/Verify call, from the value loaded into config.PasswordConfig.Pepper.
+func SetPepper(p string) {
+	pepper = p
+}
+
+// Hash encodes password as a PHC-format Argon2id string using DefaultParams.
+func Hash(pw string) (string, error) {
+	return hashWithParams(pw, DefaultParams)
+}
+
+func hashWithParams(pw string, p Params) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("password: generate salt: %w", err)
+	}
+	hash := argon2.IDKey([]byte(pw+pepper), salt, p.Time, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Time, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// Verify reports whether pw matches encoded, which may be either a PHC
+// Argon2id string or a legacy bcrypt hash. needsRehash is true when ok is
+// true but encoded should be replaced with a fresh Hash(pw): always for
+// bcrypt, and for Argon2id whenever its stored params are weaker than
+// DefaultParams.
+func Verify(pw, encoded string) (ok bool, needsRehash bool) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		return verifyArgon2id(pw, encoded)
+	}
+	// Legacy bcrypt hashes predate the pepper, so they're verified without it.
+	if bcrypt.CompareHashAndPassword([]byte(encoded), []byte(pw)) != nil {
+		return false, false
+	}
+	return true, true
+}
+
+func verifyArgon2id(pw, encoded string) (ok bool, needsRehash bool) {
+	params, salt, hash, err := decodePHC(encoded)
+	if err != nil {
+		return false, false
+	}
+	computed := argon2.IDKey([]byte(pw+pepper), salt, params.Time, params.Memory, params.Parallelism, uint32(len(hash)))
+	if subtle.ConstantTimeCompare(hash, computed) != 1 {
+		return false, false
+	}
+	weaker := params.Memory < DefaultParams.Memory ||
+		params.Time < DefaultParams.Time ||
+		params.Parallelism < DefaultParams.Parallelism
+	return true, weaker
+}
+
+// decodePHC parses a "$argon2id$v=19$m=65536,t=3,p=2$salt$hash" string.
+func decodePHC(encoded string) (Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Params{}, nil, nil, fmt.Errorf("password: malformed encoded hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Params{}, nil, nil, fmt.Errorf("password: malformed version: %w", err)
+	}
+	if version != argon2.Version {
+		return Params{}, nil, nil, fmt.Errorf("password: unsupported argon2 version %d", version)
+	}
+
+	var p Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Parallelism); err != nil {
+		return Params{}, nil, nil, fmt.Errorf("password: malformed params: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, fmt.Errorf("password: malformed salt: %w", err)
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Params{}, nil, nil, fmt.Errorf("password: malformed hash: %w", err)
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(hash))
+
+	return p, salt, hash, nil
+}
@@ -0,0 +1,103 @@
+// Package authn provides a pluggable authentication filter chain, modeled
+// after service-manager's basic+oidc filter composition: a request is
+// handed to each configured Authenticator in order until one reaches a
+// decision (Allow or Deny); an Authenticator that doesn't apply to the
+// request (e.g. no Authorization header it understands) returns Abstain so
+// the next one gets a chance.
+package authn
+
+import (
+	"context"
+	"net/http"
+)
+
+// Decision is the outcome of a single Authenticator.Authenticate call.
+type Decision int
+
+const (
+	// Abstain means this Authenticator doesn't recognize the request's
+	// credentials (e.g. wrong auth scheme) and the Chain should try the
+	// next Authenticator.
+	Abstain Decision = iota
+	// Allow means the request is authenticated as the returned Principal.
+	Allow
+	// Deny means this Authenticator recognized the credential scheme but
+	// rejected it (bad password, expired/invalid token); the Chain stops
+	// here rather than falling through.
+	Deny
+)
+
+// Principal is the authenticated identity a Chain attaches to a request's
+// context on Allow.
+type Principal struct {
+	// Subject is the provider-specific identifier: the login email for
+	// BasicAuthenticator, or the OIDC `sub` claim for OIDCAuthenticator.
+	Subject string
+	Email   string
+	Name    string
+	// UserID is the resolved local user.User.ID, when one exists.
+	UserID int64
+	// Roles, when non-nil, authorizes the request the same way a local
+	// token's `roles` claim does (see auth.RolesFromContext);
+	// BasicAuthenticator leaves this nil, OIDCAuthenticator populates it
+	// from its configured GroupsClaim.
+	Roles []string
+}
+
+// Authenticator is one link in a Chain.
+type Authenticator interface {
+	// Name identifies this authenticator in logs.
+	Name() string
+	// Authenticate inspects r's credentials. A nil error with Abstain means
+	// "not my scheme, try the next authenticator"; a non-nil error always
+	// accompanies Deny.
+	Authenticate(r *http.Request) (*Principal, Decision, error)
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a context carrying principal, retrievable via
+// PrincipalFromContext.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext extracts the Principal a Chain attached to ctx.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return p, ok
+}
+
+// Chain tries a fixed, ordered list of Authenticators per request.
+type Chain struct {
+	authenticators []Authenticator
+}
+
+// NewChain builds a Chain that tries authenticators in the given order.
+func NewChain(authenticators ...Authenticator) *Chain {
+	return &Chain{authenticators: authenticators}
+}
+
+// Middleware returns an http middleware that authenticates every request
+// via the chain, rejecting it with 401 if every authenticator abstains or
+// one denies, and otherwise attaching the winning Principal to the request
+// context before calling next.
+func (c *Chain) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, a := range c.authenticators {
+				principal, decision, err := a.Authenticate(r)
+				switch decision {
+				case Allow:
+					next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+					return
+				case Deny:
+					_ = err // the denying authenticator's error is for logs/callers, not the response body
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
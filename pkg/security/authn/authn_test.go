@@ -0,0 +1,70 @@
+package authn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAuthenticator struct {
+	name      string
+	decision  Decision
+	principal *Principal
+}
+
+func (s stubAuthenticator) Name() string { return s.name }
+func (s stubAuthenticator) Authenticate(r *http.Request) (*Principal, Decision, error) {
+	return s.principal, s.decision, nil
+}
+
+func TestChain_FallsThroughOnAbstain(t *testing.T) {
+	chain := NewChain(
+		stubAuthenticator{name: "first", decision: Abstain},
+		stubAuthenticator{name: "second", decision: Allow, principal: &Principal{Email: "a@example.com"}},
+	)
+
+	var gotPrincipal *Principal
+	handler := chain.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPrincipal, _ = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, gotPrincipal)
+	assert.Equal(t, "a@example.com", gotPrincipal.Email)
+}
+
+func TestChain_DenyStopsShortOfLaterAuthenticators(t *testing.T) {
+	chain := NewChain(
+		stubAuthenticator{name: "first", decision: Deny},
+		stubAuthenticator{name: "second", decision: Allow, principal: &Principal{}},
+	)
+
+	handler := chain.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called when an authenticator denies")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestChain_AllAbstainIsUnauthorized(t *testing.T) {
+	chain := NewChain(stubAuthenticator{name: "only", decision: Abstain})
+
+	handler := chain.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called when every authenticator abstains")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
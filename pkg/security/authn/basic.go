@@ -0,0 +1,49 @@
+package authn
+
+import (
+	"fmt"
+	"net/http"
+
+	"connex/internal/api/user"
+	"connex/pkg/password"
+)
+
+// BasicAuthenticator authenticates HTTP Basic Auth credentials against the
+// local user store.
+type BasicAuthenticator struct {
+	users user.Service
+}
+
+// NewBasicAuthenticator builds a BasicAuthenticator backed by users.
+func NewBasicAuthenticator(users user.Service) *BasicAuthenticator {
+	return &BasicAuthenticator{users: users}
+}
+
+func (a *BasicAuthenticator) Name() string { return "basic" }
+
+// Authenticate abstains if the request carries no Basic Auth credentials,
+// and otherwise looks the email up via user.Service.GetByEmail and verifies
+// the password with pkg/password.Verify.
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Principal, Decision, error) {
+	email, pw, ok := r.BasicAuth()
+	if !ok {
+		return nil, Abstain, nil
+	}
+
+	u, err := a.users.GetByEmail(r.Context(), email)
+	if err != nil {
+		return nil, Deny, fmt.Errorf("authn: basic: lookup %q: %w", email, err)
+	}
+
+	verified, _ := password.Verify(pw, u.PasswordHash)
+	if !verified {
+		return nil, Deny, fmt.Errorf("authn: basic: invalid password for %q", email)
+	}
+
+	return &Principal{
+		Subject: email,
+		Email:   u.Email,
+		Name:    u.Name,
+		UserID:  u.ID,
+	}, Allow, nil
+}
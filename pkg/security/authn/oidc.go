@@ -0,0 +1,202 @@
+package authn
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"connex/internal/api/user"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// OIDCAuthenticator authenticates bearer ID tokens against a discovered
+// OIDC issuer, the same verification path as backend.OIDCBackend uses for
+// the redirect flow: oidc.Provider.Verifier checks signature, expiry, and
+// issuer match before any claims are trusted.
+type OIDCAuthenticator struct {
+	issuerURL string
+	verifier  *oidc.IDTokenVerifier
+	users     user.Service
+
+	usernameClaim    string
+	groupsClaim      string
+	allowedAudiences []string
+}
+
+// OIDCAuthenticatorOption configures NewOIDCAuthenticator beyond issuer
+// discovery and the default single-ClientID audience check.
+type OIDCAuthenticatorOption func(*OIDCAuthenticator)
+
+// WithUsernameClaim overrides which ID token claim Authenticate reads for
+// Principal.Name (and the Name of a JIT-provisioned local user). Unset
+// defaults to "name".
+func WithUsernameClaim(claim string) OIDCAuthenticatorOption {
+	return func(a *OIDCAuthenticator) { a.usernameClaim = claim }
+}
+
+// WithGroupsClaim sets the ID token claim (a JSON string array) Authenticate
+// copies onto Principal.Roles. Unset by default, meaning OIDC principals
+// carry no roles.
+func WithGroupsClaim(claim string) OIDCAuthenticatorOption {
+	return func(a *OIDCAuthenticator) { a.groupsClaim = claim }
+}
+
+// WithAllowedAudiences widens token acceptance beyond the single clientID
+// passed to NewOIDCAuthenticator: a token is then accepted if its aud claim
+// contains clientID or any of audiences, for realms that mint one token
+// shared across several audience-mapped clients. Passing no audiences
+// leaves the default single-clientID check in place.
+func WithAllowedAudiences(audiences ...string) OIDCAuthenticatorOption {
+	return func(a *OIDCAuthenticator) { a.allowedAudiences = audiences }
+}
+
+// NewOIDCAuthenticator discovers issuerURL's OIDC metadata and builds an
+// OIDCAuthenticator that accepts bearer ID tokens issued for clientID,
+// auto-provisioning local users the same way auth.Handler.resolveLocalUser
+// does for the social login flows.
+func NewOIDCAuthenticator(ctx context.Context, issuerURL, clientID string, users user.Service, opts ...OIDCAuthenticatorOption) (*OIDCAuthenticator, error) {
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("authn: oidc: discover %q: %w", issuerURL, err)
+	}
+	a := &OIDCAuthenticator{
+		issuerURL:     issuerURL,
+		users:         users,
+		usernameClaim: "name",
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	verifierConfig := &oidc.Config{ClientID: clientID}
+	if len(a.allowedAudiences) > 0 {
+		// go-oidc's built-in check only accepts a single audience; do it
+		// ourselves in Authenticate instead, against clientID plus every
+		// configured AllowedAudiences entry.
+		verifierConfig = &oidc.Config{SkipClientIDCheck: true}
+		a.allowedAudiences = append(a.allowedAudiences, clientID)
+	}
+	a.verifier = provider.Verifier(verifierConfig)
+	return a, nil
+}
+
+func (a *OIDCAuthenticator) Name() string { return "oidc" }
+
+// Authenticate abstains if the request carries no Bearer token, and
+// otherwise verifies it against the discovered issuer's JWKS. Verify
+// rejects expired tokens, bad signatures, and issuer mismatches on its own,
+// so any error here is a straightforward Deny.
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (*Principal, Decision, error) {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, Abstain, nil
+	}
+	rawToken := strings.TrimPrefix(header, prefix)
+
+	idToken, err := a.verifier.Verify(r.Context(), rawToken)
+	if err != nil {
+		return nil, Deny, fmt.Errorf("authn: oidc: verify id_token: %w", err)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, Deny, fmt.Errorf("authn: oidc: decode id_token claims: %w", err)
+	}
+
+	if len(a.allowedAudiences) > 0 {
+		if !audienceAllowed(claims["aud"], a.allowedAudiences) {
+			return nil, Deny, fmt.Errorf("authn: oidc: token audience not in allowed list")
+		}
+		// SkipClientIDCheck (needed above to accept more than one
+		// audience) drops go-oidc's azp check along with it; redo it
+		// here so a token minted for some other, less-trusted client
+		// can't be replayed against us just for sharing our audience.
+		if azp, ok := claims["azp"].(string); ok {
+			if !containsStr(a.allowedAudiences, azp) {
+				return nil, Deny, fmt.Errorf("authn: oidc: token azp %q not in allowed list", azp)
+			}
+		} else if aud, ok := claims["aud"].([]interface{}); ok && len(aud) > 1 {
+			return nil, Deny, fmt.Errorf("authn: oidc: multi-audience token missing required azp claim")
+		}
+	}
+
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	name, _ := claims[a.usernameClaim].(string)
+
+	if email == "" {
+		return nil, Deny, fmt.Errorf("authn: oidc: token has no email claim, cannot resolve a local user")
+	}
+
+	u, err := a.resolveLocalUser(r.Context(), email, name)
+	if err != nil {
+		return nil, Deny, fmt.Errorf("authn: oidc: resolve local user: %w", err)
+	}
+
+	return &Principal{
+		Subject: subject,
+		Email:   email,
+		Name:    name,
+		UserID:  u.ID,
+		Roles:   a.groups(claims),
+	}, Allow, nil
+}
+
+// groups extracts a.groupsClaim from claims as a string slice, or nil if
+// unconfigured or the claim isn't the expected JSON array of strings.
+func (a *OIDCAuthenticator) groups(claims map[string]interface{}) []string {
+	if a.groupsClaim == "" {
+		return nil
+	}
+	raw, ok := claims[a.groupsClaim].([]interface{})
+	if !ok {
+		return nil
+	}
+	groups := make([]string, 0, len(raw))
+	for _, g := range raw {
+		if s, ok := g.(string); ok {
+			groups = append(groups, s)
+		}
+	}
+	return groups
+}
+
+// audienceAllowed reports whether aud (a JWT "aud" claim, either a bare
+// string or a JSON array of strings) contains any entry in allowed.
+func audienceAllowed(aud interface{}, allowed []string) bool {
+	switch v := aud.(type) {
+	case string:
+		return containsStr(allowed, v)
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && containsStr(allowed, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsStr(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *OIDCAuthenticator) resolveLocalUser(ctx context.Context, email, name string) (*user.User, error) {
+	found, err := a.users.GetByEmail(ctx, email)
+	if err == nil {
+		return found, nil
+	}
+
+	if name == "" {
+		name = email
+	}
+	return a.users.Create(ctx, &user.User{Name: name, Email: email})
+}
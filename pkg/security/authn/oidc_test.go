@@ -0,0 +1,281 @@
+package authn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiuser "connex/internal/api/user"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUserService is a minimal in-memory apiuser.Service for authn tests;
+// GetByEmail/Create are the only methods OIDCAuthenticator/BasicAuthenticator
+// actually call.
+type fakeUserService struct {
+	byEmail map[string]*apiuser.User
+	nextID  int64
+}
+
+func newFakeUserService() *fakeUserService {
+	return &fakeUserService{byEmail: make(map[string]*apiuser.User)}
+}
+
+func (f *fakeUserService) Create(ctx context.Context, u *apiuser.User) (*apiuser.User, error) {
+	f.nextID++
+	u.ID = f.nextID
+	f.byEmail[u.Email] = u
+	return u, nil
+}
+func (f *fakeUserService) List(ctx context.Context) ([]*apiuser.User, error) { return nil, nil }
+func (f *fakeUserService) Get(ctx context.Context, id int64) (*apiuser.User, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeUserService) Update(ctx context.Context, u *apiuser.User) (*apiuser.User, error) {
+	return u, nil
+}
+func (f *fakeUserService) Delete(ctx context.Context, id int64) error { return nil }
+func (f *fakeUserService) GetByEmail(ctx context.Context, email string) (*apiuser.User, error) {
+	u, ok := f.byEmail[email]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", email)
+	}
+	return u, nil
+}
+func (f *fakeUserService) GetByExternalID(ctx context.Context, authSource, externalID string) (*apiuser.User, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeUserService) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	return nil
+}
+func (f *fakeUserService) SoftDelete(ctx context.Context, id int64) error { return nil }
+func (f *fakeUserService) ListExternal(ctx context.Context, authSource string) ([]*apiuser.User, error) {
+	return nil, nil
+}
+
+// oidcTestFixture stands up a fake OIDC issuer (discovery document + JWKS)
+// backed by an RSA key, so tokens can be signed with golang-jwt and verified
+// end-to-end through OIDCAuthenticator the same way a real IdP would be.
+type oidcTestFixture struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+}
+
+func newOIDCTestFixture(t *testing.T) *oidcTestFixture {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	fx := &oidcTestFixture{key: key, kid: "test-key-1"}
+
+	mux := http.NewServeMux()
+	fx.server = httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   fx.server.URL,
+			"jwks_uri": fx.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]interface{}{
+				{
+					"kty": "RSA",
+					"kid": fx.kid,
+					"use": "sig",
+					"alg": "RS256",
+					"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+				},
+			},
+		})
+	})
+
+	t.Cleanup(fx.server.Close)
+	return fx
+}
+
+func (fx *oidcTestFixture) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = fx.kid
+	signed, err := token.SignedString(fx.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (fx *oidcTestFixture) bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestOIDCAuthenticator_ValidTokenAllowsAndProvisionsUser(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	users := newFakeUserService()
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", users)
+	require.NoError(t, err)
+
+	token := fx.sign(t, jwt.MapClaims{
+		"iss":   fx.server.URL,
+		"aud":   "connex-metrics",
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"name":  "Ops User",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	principal, decision, err := a.Authenticate(fx.bearerRequest(token))
+	require.NoError(t, err)
+	assert.Equal(t, Allow, decision)
+	assert.Equal(t, "ops@example.com", principal.Email)
+	assert.Equal(t, int64(1), principal.UserID)
+}
+
+func TestOIDCAuthenticator_ExpiredTokenDenied(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService())
+	require.NoError(t, err)
+
+	token := fx.sign(t, jwt.MapClaims{
+		"iss":   fx.server.URL,
+		"aud":   "connex-metrics",
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"iat":   time.Now().Add(-2 * time.Hour).Unix(),
+		"exp":   time.Now().Add(-time.Hour).Unix(),
+	})
+
+	_, decision, err := a.Authenticate(fx.bearerRequest(token))
+	assert.Error(t, err)
+	assert.Equal(t, Deny, decision)
+}
+
+func TestOIDCAuthenticator_WrongSigningKeyDenied(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService())
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   fx.server.URL,
+		"aud":   "connex-metrics",
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = fx.kid
+	signed, err := token.SignedString(otherKey)
+	require.NoError(t, err)
+
+	_, decision, err := a.Authenticate(fx.bearerRequest(signed))
+	assert.Error(t, err)
+	assert.Equal(t, Deny, decision)
+}
+
+func TestOIDCAuthenticator_IssuerMismatchDenied(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService())
+	require.NoError(t, err)
+
+	token := fx.sign(t, jwt.MapClaims{
+		"iss":   "https://not-the-real-issuer.example.com",
+		"aud":   "connex-metrics",
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, decision, err := a.Authenticate(fx.bearerRequest(token))
+	assert.Error(t, err)
+	assert.Equal(t, Deny, decision)
+}
+
+func TestOIDCAuthenticator_AllowedAudienceWithMatchingAzpAllowed(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService(), WithAllowedAudiences("connex-web"))
+	require.NoError(t, err)
+
+	token := fx.sign(t, jwt.MapClaims{
+		"iss":   fx.server.URL,
+		"aud":   []string{"connex-metrics", "connex-web"},
+		"azp":   "connex-web",
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, decision, err := a.Authenticate(fx.bearerRequest(token))
+	require.NoError(t, err)
+	assert.Equal(t, Allow, decision)
+}
+
+func TestOIDCAuthenticator_MultiAudienceTokenMissingAzpDenied(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService(), WithAllowedAudiences("connex-web"))
+	require.NoError(t, err)
+
+	token := fx.sign(t, jwt.MapClaims{
+		"iss":   fx.server.URL,
+		"aud":   []string{"connex-metrics", "connex-web"},
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, decision, err := a.Authenticate(fx.bearerRequest(token))
+	assert.Error(t, err)
+	assert.Equal(t, Deny, decision)
+}
+
+func TestOIDCAuthenticator_AzpNotInAllowedListDenied(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService(), WithAllowedAudiences("connex-web"))
+	require.NoError(t, err)
+
+	token := fx.sign(t, jwt.MapClaims{
+		"iss":   fx.server.URL,
+		"aud":   []string{"connex-metrics", "connex-web"},
+		"azp":   "some-other-untrusted-client",
+		"sub":   "user-123",
+		"email": "ops@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, decision, err := a.Authenticate(fx.bearerRequest(token))
+	assert.Error(t, err)
+	assert.Equal(t, Deny, decision)
+}
+
+func TestOIDCAuthenticator_AbstainsWithoutBearerHeader(t *testing.T) {
+	fx := newOIDCTestFixture(t)
+	a, err := NewOIDCAuthenticator(context.Background(), fx.server.URL, "connex-metrics", newFakeUserService())
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	_, decision, err := a.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, Abstain, decision)
+}
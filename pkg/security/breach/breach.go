@@ -0,0 +1,150 @@
+// Package breach checks candidate passwords against the HaveIBeenPwned
+// Pwned Passwords range API using the k-anonymity protocol: only the first
+// five hex characters of the password's SHA-1 hash ever leave the process,
+// so the API never sees the actual password or its full hash.
+package breach
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// BreachChecker reports whether password has appeared in a known breach
+// corpus at least threshold times.
+type BreachChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+const (
+	// breakerThreshold is the number of consecutive failed range lookups
+	// before the circuit opens.
+	breakerThreshold = 3
+	// breakerCooldown is how long the circuit stays open once tripped,
+	// before the next call is allowed to probe the API again.
+	breakerCooldown = 30 * time.Second
+)
+
+// HIBPChecker queries the HIBP Pwned Passwords range API, caching each
+// prefix's response in Redis (when non-nil) so repeated registrations
+// hitting a common password prefix don't re-fetch it. A short circuit
+// breaker trips after a few consecutive failures so an HIBP outage doesn't
+// add API latency (or block registration) once it's clearly down; callers
+// should treat an error as fail-open, same as a CrowdSec LAPI outage.
+type HIBPChecker struct {
+	httpClient *http.Client
+	threshold  int
+	redis      *redis.Client
+	cacheTTL   time.Duration
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+}
+
+// NewHIBPChecker builds a HIBPChecker. client may be nil to skip the Redis
+// cache entirely. threshold is the minimum breach count (HIBP's "how many
+// times has this exact password been seen") to reject a password for.
+func NewHIBPChecker(client *redis.Client, threshold int, cacheTTL, timeout time.Duration) *HIBPChecker {
+	return &HIBPChecker{
+		httpClient: &http.Client{Timeout: timeout},
+		threshold:  threshold,
+		redis:      client,
+		cacheTTL:   cacheTTL,
+	}
+}
+
+func (c *HIBPChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	if c.breakerOpen() {
+		return false, fmt.Errorf("breach: circuit breaker open, skipping HIBP lookup")
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hexSum := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hexSum[:5], hexSum[5:]
+
+	body, err := c.rangeBody(ctx, prefix)
+	if err != nil {
+		c.recordFailure()
+		return false, fmt.Errorf("breach: range lookup for prefix %s: %w", prefix, err)
+	}
+	c.recordSuccess()
+
+	for _, line := range strings.Split(body, "\n") {
+		suffixPart, countPart, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || suffixPart != suffix {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(countPart))
+		if err == nil && count >= c.threshold {
+			return true, nil
+		}
+		break
+	}
+	return false, nil
+}
+
+// rangeBody returns the HIBP range response body for prefix, serving it
+// from the Redis cache when present.
+func (c *HIBPChecker) rangeBody(ctx context.Context, prefix string) (string, error) {
+	cacheKey := "hibp:range:" + prefix
+	if c.redis != nil {
+		if cached, err := c.redis.Get(ctx, cacheKey).Result(); err == nil {
+			return cached, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.pwnedpasswords.com/range/"+prefix, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	body := string(data)
+
+	if c.redis != nil {
+		c.redis.Set(ctx, cacheKey, body, c.cacheTTL)
+	}
+	return body, nil
+}
+
+func (c *HIBPChecker) breakerOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.openUntil)
+}
+
+func (c *HIBPChecker) recordFailure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consecutiveFailures++
+	if c.consecutiveFailures >= breakerThreshold {
+		c.openUntil = time.Now().Add(breakerCooldown)
+	}
+}
+
+func (c *HIBPChecker) recordSuccess() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consecutiveFailures = 0
+	c.openUntil = time.Time{}
+}
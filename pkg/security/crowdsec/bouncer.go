@@ -0,0 +1,150 @@
+// Package crowdsec implements a CrowdSec Local API (LAPI) bouncer: it
+// long-polls LAPI for ban/captcha decisions, caches them in memory, and
+// exposes a Middleware that short-circuits requests from banned IPs,
+// CIDR ranges, countries, or ASNs. Security events detected elsewhere in
+// connex (failed logins, rate-limit hits, suspicious user agents) can be
+// fed back to LAPI via ReportEvent so a CrowdSec agent correlating alerts
+// across the fleet can turn them into a ban decision.
+package crowdsec
+
+import (
+	"context"
+	"time"
+
+	"connex/internal/config"
+	"connex/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Action names accepted by config.CrowdSecConfig.Action.
+const (
+	ActionBan      = "ban"
+	ActionThrottle = "throttle"
+	ActionCaptcha  = "captcha"
+	ActionTarpit   = "tarpit"
+)
+
+// pollInterval is how often Bouncer long-polls the decisions stream once
+// the initial startup snapshot has been fetched.
+const pollInterval = 10 * time.Second
+
+// Bouncer is a CrowdSec LAPI bouncer: it keeps a local decision cache fresh
+// via long-polling and enforces it at the HTTP layer via Middleware.
+type Bouncer struct {
+	cfg    config.CrowdSecConfig
+	client *lapiClient
+	cache  *decisionCache
+	events chan alertEvent
+
+	cancel context.CancelFunc
+}
+
+type alertEvent struct {
+	scenario string
+	message  string
+	ip       string
+}
+
+// NewBouncer builds a Bouncer from cfg. It does not start polling or
+// pushing alerts until Start is called, so it's safe to construct
+// unconditionally even when cfg.Enabled is false.
+func NewBouncer(cfg config.CrowdSecConfig) *Bouncer {
+	return &Bouncer{
+		cfg:    cfg,
+		client: newLAPIClient(cfg.LAPIURL, cfg.APIKey),
+		cache:  newDecisionCache(),
+		events: make(chan alertEvent, 256),
+	}
+}
+
+// Start begins long-polling LAPI for decisions and flushing queued alerts
+// in background goroutines. It returns immediately; call Stop to shut them
+// down.
+func (b *Bouncer) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+	go b.streamDecisions(ctx)
+	go b.flushEvents(ctx)
+}
+
+// Stop halts the background goroutines started by Start.
+func (b *Bouncer) Stop() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+// streamDecisions long-polls the decisions stream for as long as ctx is
+// live. A failed poll is logged and retried with backoff, leaving the
+// cache's previous snapshot in effect so bans keep being enforced through a
+// transient LAPI outage.
+func (b *Bouncer) streamDecisions(ctx context.Context) {
+	startup := true
+	backoff := time.Second
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		added, deleted, err := b.client.StreamDecisions(ctx, startup)
+		if err != nil {
+			logger.GetGlobal().Warn("crowdsec: decision stream unreachable, keeping previous snapshot",
+				zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+			continue
+		}
+
+		backoff = time.Second
+		startup = false
+		b.cache.Apply(added, deleted)
+		b.cache.Sweep()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// flushEvents drains queued ReportEvent calls and pushes each to LAPI as an
+// alert, for as long as ctx is live.
+func (b *Bouncer) flushEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-b.events:
+			pushCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err := b.client.PushAlert(pushCtx, ev.scenario, ev.message, ev.ip)
+			cancel()
+			if err != nil {
+				logger.GetGlobal().Warn("crowdsec: failed to push alert", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ReportEvent queues a locally-detected security event to be pushed to LAPI
+// as a signal. It never blocks: if the queue is full the event is dropped
+// and a warning logged, since bouncer alerting is best-effort and must not
+// add latency to the request path it's called from.
+func (b *Bouncer) ReportEvent(scenario, message, ip string) {
+	select {
+	case b.events <- alertEvent{scenario: scenario, message: message, ip: ip}:
+	default:
+		logger.GetGlobal().Warn("crowdsec: event queue full, dropping signal",
+			zap.String("scenario", scenario), zap.String("ip", ip))
+	}
+}
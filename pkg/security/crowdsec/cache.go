@@ -0,0 +1,152 @@
+package crowdsec
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// decisionCache is an in-memory snapshot of active CrowdSec decisions,
+// indexed by scope so Decision can check a request's IP, its containing
+// CIDR ranges, its country, and its ASN against a single cache. A failed
+// LAPI poll simply skips Apply, leaving the previous snapshot in place, so
+// bans keep being enforced through a transient LAPI outage.
+type decisionCache struct {
+	mu        sync.RWMutex
+	ips       map[string]entry
+	ranges    []rangeEntry
+	countries map[string]entry
+	asns      map[string]entry
+}
+
+type entry struct {
+	decisionType string
+	expiresAt    time.Time
+}
+
+type rangeEntry struct {
+	network *net.IPNet
+	entry
+}
+
+func newDecisionCache() *decisionCache {
+	return &decisionCache{
+		ips:       make(map[string]entry),
+		countries: make(map[string]entry),
+		asns:      make(map[string]entry),
+	}
+}
+
+// Apply merges one stream tick's added and deleted decisions into the cache.
+func (c *decisionCache) Apply(added, deleted []Decision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, d := range deleted {
+		c.remove(d)
+	}
+	for _, d := range added {
+		c.add(d)
+	}
+}
+
+func (c *decisionCache) add(d Decision) {
+	e := entry{decisionType: d.Type, expiresAt: d.expiry()}
+	switch strings.ToLower(d.Scope) {
+	case "ip":
+		c.ips[d.Value] = e
+	case "range":
+		if _, network, err := net.ParseCIDR(d.Value); err == nil {
+			c.ranges = append(c.ranges, rangeEntry{network: network, entry: e})
+		}
+	case "country":
+		c.countries[strings.ToUpper(d.Value)] = e
+	case "as":
+		c.asns[d.Value] = e
+	}
+}
+
+func (c *decisionCache) remove(d Decision) {
+	switch strings.ToLower(d.Scope) {
+	case "ip":
+		delete(c.ips, d.Value)
+	case "range":
+		filtered := c.ranges[:0]
+		for _, r := range c.ranges {
+			if r.network.String() != d.Value {
+				filtered = append(filtered, r)
+			}
+		}
+		c.ranges = filtered
+	case "country":
+		delete(c.countries, strings.ToUpper(d.Value))
+	case "as":
+		delete(c.asns, d.Value)
+	}
+}
+
+// Decision reports the highest-priority active decision matching ip (exact
+// match, then containing range, then country, then AS), returning its type
+// ("ban", "captcha", ...) and true if one applies. Expired entries are
+// treated as absent even if Sweep hasn't purged them yet.
+func (c *decisionCache) Decision(ip, country, asn string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	if e, ok := c.ips[ip]; ok && e.expiresAt.After(now) {
+		return e.decisionType, true
+	}
+	if parsed := net.ParseIP(ip); parsed != nil {
+		for _, r := range c.ranges {
+			if r.expiresAt.After(now) && r.network.Contains(parsed) {
+				return r.decisionType, true
+			}
+		}
+	}
+	if country != "" {
+		if e, ok := c.countries[strings.ToUpper(country)]; ok && e.expiresAt.After(now) {
+			return e.decisionType, true
+		}
+	}
+	if asn != "" {
+		if e, ok := c.asns[asn]; ok && e.expiresAt.After(now) {
+			return e.decisionType, true
+		}
+	}
+	return "", false
+}
+
+// Sweep purges expired entries so the cache doesn't grow unbounded between
+// LAPI deletions, which only arrive for decisions LAPI itself expired, not
+// ones whose TTL we computed locally from Duration and that have since
+// lapsed between polls.
+func (c *decisionCache) Sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range c.ips {
+		if !e.expiresAt.After(now) {
+			delete(c.ips, k)
+		}
+	}
+	for k, e := range c.countries {
+		if !e.expiresAt.After(now) {
+			delete(c.countries, k)
+		}
+	}
+	for k, e := range c.asns {
+		if !e.expiresAt.After(now) {
+			delete(c.asns, k)
+		}
+	}
+	filtered := c.ranges[:0]
+	for _, r := range c.ranges {
+		if r.expiresAt.After(now) {
+			filtered = append(filtered, r)
+		}
+	}
+	c.ranges = filtered
+}
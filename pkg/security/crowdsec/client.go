@@ -0,0 +1,116 @@
+package crowdsec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// lapiClient speaks the CrowdSec bouncer HTTP API: bouncers authenticate
+// with a static API key (unlike machines, which use a JWT), long-poll the
+// decisions stream, and may push alerts for signals detected locally.
+type lapiClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newLAPIClient(baseURL, apiKey string) *lapiClient {
+	return &lapiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		// LAPI holds the stream connection open for up to ~30s per tick.
+		http: &http.Client{Timeout: 35 * time.Second},
+	}
+}
+
+type streamResponse struct {
+	New     []Decision `json:"new"`
+	Deleted []Decision `json:"deleted"`
+}
+
+// StreamDecisions performs one long-poll tick against
+// GET /v1/decisions/stream?startup=<startup>. On startup=true LAPI returns
+// its full current decision set; subsequent ticks return only what changed.
+func (c *lapiClient) StreamDecisions(ctx context.Context, startup bool) (added, deleted []Decision, err error) {
+	url := fmt.Sprintf("%s/v1/decisions/stream?startup=%t", c.baseURL, startup)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("crowdsec: build stream request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("crowdsec: stream request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("crowdsec: stream returned status %d", resp.StatusCode)
+	}
+
+	var parsed streamResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, nil, fmt.Errorf("crowdsec: decode stream response: %w", err)
+	}
+	return parsed.New, parsed.Deleted, nil
+}
+
+// alert is the subset of the CrowdSec POST /v1/alerts payload populated
+// when reporting a locally-detected event as a signal.
+type alert struct {
+	Scenario        string   `json:"scenario"`
+	ScenarioVersion string   `json:"scenario_version"`
+	Message         string   `json:"message"`
+	EventsCount     int      `json:"events_count"`
+	StartAt         string   `json:"start_at"`
+	StopAt          string   `json:"stop_at"`
+	Source          alertSrc `json:"source"`
+}
+
+type alertSrc struct {
+	Scope string `json:"scope"`
+	Value string `json:"value"`
+}
+
+// PushAlert reports a locally detected event (failed login, rate-limit hit,
+// suspicious user agent) to LAPI as a signal, so a CrowdSec agent
+// correlating alerts across the fleet can turn repeated signals from
+// multiple connex instances into a ban decision.
+func (c *lapiClient) PushAlert(ctx context.Context, scenario, message, ip string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	body := []alert{{
+		Scenario:        scenario,
+		ScenarioVersion: "0.1",
+		Message:         message,
+		EventsCount:     1,
+		StartAt:         now,
+		StopAt:          now,
+		Source:          alertSrc{Scope: "Ip", Value: ip},
+	}}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("crowdsec: marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/alerts", strings.NewReader(string(payload)))
+	if err != nil {
+		return fmt.Errorf("crowdsec: build alert request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("crowdsec: push alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("crowdsec: alerts endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package crowdsec
+
+import "time"
+
+// Decision is a single ban/captcha decision as reported by the CrowdSec LAPI
+// decisions stream (GET /v1/decisions/stream).
+type Decision struct {
+	Origin   string `json:"origin"`
+	Type     string `json:"type"`            // "ban", "captcha", "throttle", ...
+	Scope    string `json:"scope"`           // "Ip", "Range", "Country", "AS"
+	Value    string `json:"value"`           // "1.2.3.4", "1.2.3.0/24", "US", "15169"
+	Duration string `json:"duration"`        // e.g. "4h32m1s", relative to when LAPI sent it
+	Until    string `json:"until,omitempty"` // RFC3339 absolute expiry, when present
+}
+
+// expiry resolves the decision's TTL to an absolute time, preferring Until
+// when LAPI set it and otherwise adding Duration to now.
+func (d Decision) expiry() time.Time {
+	if d.Until != "" {
+		if t, err := time.Parse(time.RFC3339, d.Until); err == nil {
+			return t
+		}
+	}
+	if dur, err := time.ParseDuration(d.Duration); err == nil {
+		return time.Now().Add(dur)
+	}
+	return time.Now().Add(time.Hour)
+}
@@ -0,0 +1,64 @@
+package crowdsec
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Middleware short-circuits requests whose IP, containing CIDR range, or (if
+// ever populated) country/ASN has an active CrowdSec decision, responding
+// per the Bouncer's configured Action. Requests with no matching decision
+// pass through unchanged.
+func (b *Bouncer) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decisionType, banned := b.cache.Decision(clientIP(r), "", "")
+			if !banned {
+				next.ServeHTTP(w, r)
+				return
+			}
+			b.deny(w, r, decisionType)
+		})
+	}
+}
+
+// deny writes the response for a banned request according to cfg.Action.
+// decisionType (the type LAPI itself assigned, e.g. "ban" or "captcha") is
+// currently only used for future refinement; the bouncer's own Action
+// config is authoritative for how connex responds.
+func (b *Bouncer) deny(w http.ResponseWriter, r *http.Request, decisionType string) {
+	switch b.cfg.Action {
+	case ActionThrottle:
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+	case ActionCaptcha:
+		if b.cfg.CaptchaURL != "" {
+			http.Redirect(w, r, b.cfg.CaptchaURL, http.StatusFound)
+			return
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case ActionTarpit:
+		delay := time.Duration(b.cfg.TarpitDelay)
+		if delay <= 0 {
+			delay = 5 * time.Second
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(delay):
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	default:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}
+}
+
+// clientIP extracts the request's IP from RemoteAddr, expected to already be
+// the real client address since this middleware is meant to run after chi's
+// RealIP middleware.
+func clientIP(r *http.Request) string {
+	host := r.RemoteAddr
+	if idx := strings.LastIndex(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+	return host
+}
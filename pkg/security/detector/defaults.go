@@ -0,0 +1,24 @@
+package detector
+
+import (
+	_ "embed"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed defaults.yaml
+var defaultRulesYAML []byte
+
+// DefaultRuleSet returns the ruleset NewDetector falls back to when no
+// RulesPath is configured: the current hardcoded UA/path lists plus common
+// OWASP patterns (sqli, xss, path traversal, shell injection).
+func DefaultRuleSet() *RuleSet {
+	var rs RuleSet
+	if err := yaml.Unmarshal(defaultRulesYAML, &rs); err != nil {
+		// defaults.yaml is compiled into the binary, so a parse failure here
+		// means the default ruleset itself is broken, not operator input.
+		panic(fmt.Sprintf("detector: embedded default ruleset is invalid: %v", err))
+	}
+	return &rs
+}
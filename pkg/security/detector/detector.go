@@ -0,0 +1,249 @@
+// Package detector implements SuspiciousRequestDetector, a rules-driven
+// replacement for the old hardcoded substring checks in
+// internal/middleware/monitoring.go. Rules are loaded from a YAML or JSON
+// file (or an embedded default ruleset when none is configured), compiled
+// once, and re-compiled in place on Reload so operators can push new rules
+// via SIGHUP without restarting the server.
+package detector
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"connex/pkg/logger"
+	"connex/pkg/security/crowdsec"
+	"connex/pkg/telemetry"
+
+	"github.com/cloudflare/ahocorasick"
+	"go.uber.org/zap"
+)
+
+// Match describes one rule that fired for a request.
+type Match struct {
+	RuleID   string
+	Category string
+	Severity string
+}
+
+// SuspiciousRequestDetector checks incoming requests against a compiled
+// RuleSet. It's safe for concurrent use, including concurrent Reload.
+type SuspiciousRequestDetector struct {
+	mu       sync.RWMutex
+	compiled *compiledRuleSet
+
+	// path is empty when running on the embedded DefaultRuleSet, in which
+	// case Reload is a no-op: there's no file to re-read.
+	path    string
+	bouncer *crowdsec.Bouncer
+}
+
+// NewDetector builds a SuspiciousRequestDetector. path may be empty to use
+// DefaultRuleSet; bouncer may be nil to skip forwarding matches to CrowdSec
+// LAPI as signals.
+func NewDetector(path string, bouncer *crowdsec.Bouncer) (*SuspiciousRequestDetector, error) {
+	rs, err := loadRuleSet(path)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := compileRuleSet(rs)
+	if err != nil {
+		return nil, err
+	}
+	return &SuspiciousRequestDetector{compiled: compiled, path: path, bouncer: bouncer}, nil
+}
+
+func loadRuleSet(path string) (*RuleSet, error) {
+	if path == "" {
+		return DefaultRuleSet(), nil
+	}
+	return LoadRuleSet(path)
+}
+
+// Reload re-reads and re-compiles the ruleset from disk, swapping it in
+// atomically. A bad or unreadable file leaves the previously-compiled
+// ruleset in place, so a typo in a hot-reloaded config degrades to "old
+// rules keep running" rather than "detector falls over".
+func (d *SuspiciousRequestDetector) Reload() error {
+	if d.path == "" {
+		return nil
+	}
+	rs, err := LoadRuleSet(d.path)
+	if err != nil {
+		return fmt.Errorf("detector: reload: %w", err)
+	}
+	compiled, err := compileRuleSet(rs)
+	if err != nil {
+		return fmt.Errorf("detector: reload: %w", err)
+	}
+	d.mu.Lock()
+	d.compiled = compiled
+	d.mu.Unlock()
+	return nil
+}
+
+// Check inspects r against every rule, reporting each match (structured
+// log, telemetry counter, and an optional CrowdSec signal) as it goes, and
+// reports whether any rule matched at all.
+func (d *SuspiciousRequestDetector) Check(r *http.Request) bool {
+	d.mu.RLock()
+	compiled := d.compiled
+	d.mu.RUnlock()
+
+	matches := compiled.match(r)
+	for _, m := range matches {
+		d.report(r, m)
+	}
+	return len(matches) > 0
+}
+
+func (d *SuspiciousRequestDetector) report(r *http.Request, m Match) {
+	logger.FromContext(r.Context()).Warn("suspicious request rule matched",
+		zap.String("rule_id", m.RuleID),
+		zap.String("category", m.Category),
+		zap.String("severity", m.Severity),
+		zap.String("ip", r.RemoteAddr),
+		zap.String("path", r.URL.Path),
+	)
+	telemetry.RecordSuspiciousRequestRule(m.RuleID, m.Category, m.Severity)
+	if d.bouncer != nil {
+		d.bouncer.ReportEvent(
+			fmt.Sprintf("connex/%s", m.Category),
+			fmt.Sprintf("rule %s matched (%s severity)", m.RuleID, m.Severity),
+			r.RemoteAddr,
+		)
+	}
+}
+
+// compiledRuleSet is the compiled form of a RuleSet: every SubstringRule's
+// patterns collapsed into one Aho-Corasick matcher (so per-field cost is
+// linear in the field's length, not in the number of rules), and every
+// RegexRule compiled with case-insensitivity enforced.
+type compiledRuleSet struct {
+	headers []string
+
+	// matcher and patternRule are parallel: matcher.Match returns indices
+	// into the pattern list it was built from, and patternRule[i] is the
+	// rule that contributed pattern i.
+	matcher     *ahocorasick.Matcher
+	patternRule []*SubstringRule
+
+	regexRules []compiledRegexRule
+}
+
+type compiledRegexRule struct {
+	RegexRule
+	re *regexp.Regexp
+}
+
+func compileRuleSet(rs *RuleSet) (*compiledRuleSet, error) {
+	var patterns []string
+	var patternRule []*SubstringRule
+	for i := range rs.SubstringRules {
+		rule := &rs.SubstringRules[i]
+		for _, p := range rule.Patterns {
+			patterns = append(patterns, strings.ToLower(p))
+			patternRule = append(patternRule, rule)
+		}
+	}
+
+	var matcher *ahocorasick.Matcher
+	if len(patterns) > 0 {
+		matcher = ahocorasick.NewStringMatcher(patterns)
+	}
+
+	regexRules := make([]compiledRegexRule, 0, len(rs.RegexRules))
+	for _, rule := range rs.RegexRules {
+		pattern := rule.Pattern
+		if !strings.HasPrefix(pattern, "(?i)") {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("detector: compile regex rule %s: %w", rule.ID, err)
+		}
+		regexRules = append(regexRules, compiledRegexRule{RegexRule: rule, re: re})
+	}
+
+	return &compiledRuleSet{
+		headers:     append([]string(nil), rs.Headers...),
+		matcher:     matcher,
+		patternRule: patternRule,
+		regexRules:  regexRules,
+	}, nil
+}
+
+type fieldValue struct {
+	field string
+	value string
+}
+
+func (c *compiledRuleSet) fields(r *http.Request) []fieldValue {
+	fields := []fieldValue{
+		{FieldUserAgent, r.UserAgent()},
+		{FieldPath, r.URL.Path},
+		{FieldQuery, r.URL.RawQuery},
+	}
+	for _, name := range c.headers {
+		if v := r.Header.Get(name); v != "" {
+			fields = append(fields, fieldValue{FieldHeader, v})
+		}
+	}
+	return fields
+}
+
+// match checks every field of r against the compiled rules, returning at
+// most one Match per rule even if several of its patterns or fields fire.
+func (c *compiledRuleSet) match(r *http.Request) []Match {
+	fields := c.fields(r)
+	seen := make(map[string]bool)
+	var matches []Match
+
+	if c.matcher != nil {
+		for _, fv := range fields {
+			if fv.value == "" {
+				continue
+			}
+			for _, idx := range c.matcher.Match([]byte(strings.ToLower(fv.value))) {
+				rule := c.patternRule[idx]
+				if seen[rule.ID] || !fieldAllowed(rule.Fields, fv.field) {
+					continue
+				}
+				seen[rule.ID] = true
+				matches = append(matches, Match{RuleID: rule.ID, Category: rule.Category, Severity: rule.Severity})
+			}
+		}
+	}
+
+	for _, rule := range c.regexRules {
+		if seen[rule.ID] {
+			continue
+		}
+		for _, fv := range fields {
+			if fv.value == "" || !fieldAllowed(rule.Fields, fv.field) {
+				continue
+			}
+			if rule.re.MatchString(fv.value) {
+				seen[rule.ID] = true
+				matches = append(matches, Match{RuleID: rule.ID, Category: rule.Category, Severity: rule.Severity})
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+func fieldAllowed(allowed []string, field string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, f := range allowed {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
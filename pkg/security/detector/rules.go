@@ -0,0 +1,84 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity levels a rule can be tagged with. These are free-form strings in
+// the config (so operators can invent their own), but the default ruleset
+// sticks to these.
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Field names a rule can scope itself to. "header" means "any of the
+// RuleSet's configured Headers", since individual header names aren't known
+// until the ruleset is loaded.
+const (
+	FieldUserAgent = "user_agent"
+	FieldPath      = "path"
+	FieldQuery     = "query"
+	FieldHeader    = "header"
+)
+
+// SubstringRule flags a request when any of Patterns appears (case
+// insensitively) in one of Fields. Patterns across every SubstringRule in a
+// RuleSet are compiled into a single Aho-Corasick matcher, so adding more
+// patterns or rules doesn't add more passes over the request.
+type SubstringRule struct {
+	ID       string   `yaml:"id" json:"id"`
+	Category string   `yaml:"category" json:"category"`
+	Severity string   `yaml:"severity" json:"severity"`
+	Fields   []string `yaml:"fields" json:"fields"`
+	Patterns []string `yaml:"patterns" json:"patterns"`
+}
+
+// RegexRule flags a request when Pattern matches one of Fields. Pattern is
+// compiled case-insensitively regardless of whether it already carries an
+// "(?i)" flag.
+type RegexRule struct {
+	ID       string   `yaml:"id" json:"id"`
+	Category string   `yaml:"category" json:"category"`
+	Severity string   `yaml:"severity" json:"severity"`
+	Fields   []string `yaml:"fields" json:"fields"`
+	Pattern  string   `yaml:"pattern" json:"pattern"`
+}
+
+// RuleSet is the on-disk (YAML or JSON) shape of a SuspiciousRequestDetector
+// configuration.
+type RuleSet struct {
+	// Headers lists the request header names checked by rules scoped to
+	// FieldHeader. Unlisted headers are never inspected.
+	Headers []string `yaml:"headers" json:"headers"`
+
+	SubstringRules []SubstringRule `yaml:"substring_rules" json:"substring_rules"`
+	RegexRules     []RegexRule     `yaml:"regex_rules" json:"regex_rules"`
+}
+
+// LoadRuleSet reads and parses a RuleSet from path, choosing YAML or JSON
+// based on its extension (.json is parsed as JSON; anything else as YAML,
+// since YAML is a superset of JSON and this repo has no other JSON-only
+// config loader to match).
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("detector: read ruleset %s: %w", path, err)
+	}
+
+	var rs RuleSet
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		if err := json.Unmarshal(data, &rs); err != nil {
+			return nil, fmt.Errorf("detector: parse ruleset %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("detector: parse ruleset %s: %w", path, err)
+	}
+	return &rs, nil
+}
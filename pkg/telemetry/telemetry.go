@@ -11,6 +11,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
@@ -112,25 +118,55 @@ var (
 		},
 		[]string{"event_type", "source"},
 	)
+
+	suspiciousRuleMatchesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "suspicious_rule_matches_total",
+			Help: "Total number of SuspiciousRequestDetector rule matches",
+		},
+		[]string{"rule_id", "category", "severity"},
+	)
+
+	loginAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_attempts_total",
+			Help: "Total number of login attempts by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	compressionResponsesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_compression_responses_total",
+			Help: "Total number of responses compressed by CompressionMiddleware, by encoding",
+		},
+		[]string{"encoding"},
+	)
+
+	compressionBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_compression_bytes_total",
+			Help: "Total response bytes seen by CompressionMiddleware before and after encoding; compression ratio is 1 - compressed/original",
+		},
+		[]string{"encoding", "stage"},
+	)
 )
 
-// Init initializes OpenTelemetry tracing and metrics
+// Init initializes OpenTelemetry tracing and, for the otlp-* exporters with
+// MetricsEnabled, an OTLP metrics pipeline (see initMetricsPipeline). The
+// Prometheus counters/histograms registered above are not exported through
+// that pipeline.
 func Init(cfg config.OTelConfig, log *zap.Logger) error {
 	logger = log
 
-	if !cfg.Enabled {
+	if !cfg.Enabled || cfg.Exporter == "none" {
 		logger.Info("OpenTelemetry disabled")
 		return nil
 	}
 
-	// Create Jaeger exporter
-	exporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.JaegerURL)))
-	if err != nil {
-		return fmt.Errorf("failed to create Jaeger exporter: %w", err)
-	}
+	ctx := context.Background()
 
-	// Create resource with service information
-	res, err := resource.New(context.Background(),
+	res, err := resource.New(ctx,
 		resource.WithAttributes(
 			semconv.ServiceName(cfg.ServiceName),
 			semconv.ServiceVersion("1.0.0"),
@@ -141,27 +177,124 @@ func Init(cfg config.OTelConfig, log *zap.Logger) error {
 		return fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	// Create trace provider
+	exporter, err := newSpanExporter(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create %s span exporter: %w", cfg.Exporter, err)
+	}
+
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to configure sampler: %w", err)
+	}
+
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithBatchTimeout(time.Duration(cfg.BatchTimeout)),
+			sdktrace.WithMaxQueueSize(cfg.MaxQueueSize),
+		),
 		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
 	)
-
-	// Set global trace provider
 	otel.SetTracerProvider(tp)
-
-	// Create tracer
 	tracer = tp.Tracer(cfg.ServiceName)
 
+	if cfg.MetricsEnabled {
+		if err := initMetricsPipeline(ctx, cfg, res); err != nil {
+			return fmt.Errorf("failed to initialize OTLP metrics pipeline: %w", err)
+		}
+	}
+
 	logger.Info("OpenTelemetry initialized",
 		zap.String("service", cfg.ServiceName),
 		zap.String("environment", cfg.Environment),
-		zap.String("jaeger_url", cfg.JaegerURL),
+		zap.String("exporter", cfg.Exporter),
+		zap.String("sampler", cfg.Sampler),
+		zap.Bool("otlp_metrics_enabled", cfg.MetricsEnabled),
 	)
 
 	return nil
 }
 
+// newSpanExporter constructs the sdktrace.SpanExporter selected by
+// cfg.Exporter.
+func newSpanExporter(ctx context.Context, cfg config.OTelConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "", "jaeger":
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.JaegerURL)))
+	case "otlp-grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case "otlp-http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+}
+
+// newSampler builds the sdktrace.Sampler selected by cfg.Sampler.
+func newSampler(cfg config.OTelConfig) (sdktrace.Sampler, error) {
+	switch cfg.Sampler {
+	case "", "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(cfg.SampleRatio), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)), nil
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", cfg.Sampler)
+	}
+}
+
+// initMetricsPipeline sets the global metric.MeterProvider to one pushing to
+// the same otlp-grpc/otlp-http endpoint as the span exporter. It exports only
+// instruments created through the OpenTelemetry metric API; the Prometheus
+// counters/histograms in this package are not bridged into it and are still
+// available only through a Prometheus scrape.
+func initMetricsPipeline(ctx context.Context, cfg config.OTelConfig, res *resource.Resource) error {
+	var reader metric.Reader
+	switch cfg.Exporter {
+	case "otlp-grpc":
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		exporter, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		reader = metric.NewPeriodicReader(exporter)
+	case "otlp-http":
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		exporter, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		reader = metric.NewPeriodicReader(exporter)
+	default:
+		// MetricsEnabled only applies to the otlp-* exporters; jaeger/stdout
+		// keep metrics on Prometheus alone.
+		return nil
+	}
+
+	mp := metric.NewMeterProvider(metric.WithReader(reader), metric.WithResource(res))
+	otel.SetMeterProvider(mp)
+	return nil
+}
+
 // GetTracer returns the global tracer
 func GetTracer() trace.Tracer {
 	return tracer
@@ -230,6 +363,27 @@ func LogWithTrace(ctx context.Context, level string, msg string, fields ...zap.F
 	}
 }
 
+// RecordLoginAttempt records a login attempt by its outcome, e.g. "success",
+// "failure", or "locked" (see auth.LoginAttemptLimiter).
+func RecordLoginAttempt(outcome string) {
+	loginAttemptsTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordSuspiciousRequestRule records one SuspiciousRequestDetector rule
+// match (see pkg/security/detector).
+func RecordSuspiciousRequestRule(ruleID, category, severity string) {
+	suspiciousRuleMatchesTotal.WithLabelValues(ruleID, category, severity).Inc()
+}
+
+// RecordCompression records one response compressed by
+// custommiddleware.CompressionMiddleware, exposing the compression ratio as
+// http_compression_bytes_total{stage="compressed"} / {stage="original"}.
+func RecordCompression(encoding string, originalBytes, compressedBytes int) {
+	compressionResponsesTotal.WithLabelValues(encoding).Inc()
+	compressionBytesTotal.WithLabelValues(encoding, "original").Add(float64(originalBytes))
+	compressionBytesTotal.WithLabelValues(encoding, "compressed").Add(float64(compressedBytes))
+}
+
 // RecordSecurityEvent records a security event
 func RecordSecurityEvent(eventType string, source string) {
 	// Record in metrics
@@ -0,0 +1,71 @@
+// Package tls wraps golang.org/x/crypto/acme/autocert with the pieces a
+// multi-instance deployment needs: a pluggable autocert.Cache (filesystem
+// for single-instance setups, a SQL-backed one for replicas that must share
+// certificates) and a Server that serves the HTTP-01 challenge, redirects
+// plain HTTP to HTTPS, and only reports itself HSTS-preload-ready once a
+// certificate has actually been issued.
+package tls
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// SQLCache is an autocert.Cache backed by a SQL table, so every replica of
+// a multi-instance deployment obtains and renews certificates from the same
+// store instead of racing separate ACME orders. It expects a table shaped
+// like:
+//
+//	CREATE TABLE autocert_cache (
+//		cache_key TEXT PRIMARY KEY,
+//		data      BYTEA NOT NULL
+//	);
+//
+// DB is typically the same *sql.DB backing UserService (see db.Manager),
+// reused here rather than opening a second pool.
+type SQLCache struct {
+	DB *sql.DB
+}
+
+// NewSQLCache builds a SQLCache backed by db.
+func NewSQLCache(db *sql.DB) *SQLCache {
+	return &SQLCache{DB: db}
+}
+
+// Get implements autocert.Cache.
+func (c *SQLCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := c.DB.QueryRowContext(ctx, `SELECT data FROM autocert_cache WHERE cache_key = $1`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tls: sqlcache: get %q: %w", key, err)
+	}
+	return data, nil
+}
+
+// Put implements autocert.Cache.
+func (c *SQLCache) Put(ctx context.Context, key string, data []byte) error {
+	_, err := c.DB.ExecContext(ctx, `
+		INSERT INTO autocert_cache (cache_key, data) VALUES ($1, $2)
+		ON CONFLICT (cache_key) DO UPDATE SET data = EXCLUDED.data
+	`, key, data)
+	if err != nil {
+		return fmt.Errorf("tls: sqlcache: put %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements autocert.Cache.
+func (c *SQLCache) Delete(ctx context.Context, key string) error {
+	_, err := c.DB.ExecContext(ctx, `DELETE FROM autocert_cache WHERE cache_key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("tls: sqlcache: delete %q: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package tls
+
+import (
+	"context"
+	ctls "crypto/tls"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"connex/internal/config"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// modernCipherSuites restricts TLS 1.2 negotiation to AEAD/ECDHE suites;
+// TLS 1.3 ignores CipherSuites and always uses its own modern set.
+var modernCipherSuites = []uint16{
+	ctls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	ctls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	ctls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	ctls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	ctls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	ctls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// Server obtains and renews certificates via ACME/autocert.Manager and
+// serves HTTPS with them, redirecting plain HTTP to HTTPS and answering the
+// HTTP-01 challenge along the way.
+type Server struct {
+	Addr         string
+	Handler      http.Handler
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// BaseTLSConfig, if set, supplies additional *ctls.Config fields (e.g.
+	// ClientCAs/ClientAuth for mTLS) that ListenAndServeAutoTLS layers
+	// MinVersion/CipherSuites/GetCertificate on top of, rather than
+	// replacing wholesale.
+	BaseTLSConfig *ctls.Config
+
+	certManager *autocert.Manager
+	// ready flips true the first time GetCertificate returns a certificate
+	// (newly issued, renewed, or loaded from the cache), so HSTS preload (see internal/middleware.WithHSTSGate) isn't promised
+	// to clients before a cert actually exists to back it up.
+	ready atomic.Bool
+}
+
+// NewAutoTLSServer builds a Server that provisions certificates for
+// cfg.Hosts via ACME. cache is the autocert.Cache certificates are
+// persisted to; pass autocert.DirCache(cfg.CacheDir) for a single instance,
+// or a *SQLCache for replicas that must share issued certificates.
+func NewAutoTLSServer(handler http.Handler, addr string, cfg config.AutoTLSConfig, cache autocert.Cache) *Server {
+	certManager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Hosts...),
+		Cache:      cache,
+		Email:      cfg.Email,
+	}
+	if cfg.DirectoryURL != "" {
+		// Points autocert at a private ACME CA (e.g. step-ca, Pebble)
+		// instead of Let's Encrypt's production directory.
+		certManager.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return &Server{Addr: addr, Handler: handler, certManager: certManager}
+}
+
+// Ready reports whether the certificate manager has returned a certificate
+// for a TLS handshake since this Server was created, whether that
+// certificate was newly issued, renewed, or loaded from the cache.
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+func (s *Server) getCertificate(hello *ctls.ClientHelloInfo) (*ctls.Certificate, error) {
+	cert, err := s.certManager.GetCertificate(hello)
+	if err == nil {
+		s.ready.Store(true)
+	}
+	return cert, err
+}
+
+// ListenAndServeAutoTLS serves the HTTP-01 challenge and an HTTP->HTTPS
+// redirect on :80, and TLS 1.2+ with modern cipher suites on s.Addr, until
+// ctx is canceled. It returns once the HTTPS listener stops; the :80
+// listener runs in the background and is closed only when ctx is canceled.
+func (s *Server) ListenAndServeAutoTLS(ctx context.Context, log *zap.Logger) error {
+	tlsConfig := s.BaseTLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &ctls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	tlsConfig.MinVersion = ctls.VersionTLS12
+	tlsConfig.CipherSuites = modernCipherSuites
+	tlsConfig.GetCertificate = s.getCertificate
+
+	httpsSrv := &http.Server{
+		Addr:         s.Addr,
+		Handler:      s.Handler,
+		ReadTimeout:  s.ReadTimeout,
+		WriteTimeout: s.WriteTimeout,
+		IdleTimeout:  s.IdleTimeout,
+		TLSConfig:    tlsConfig,
+	}
+
+	redirectSrv := &http.Server{
+		Addr:    ":80",
+		Handler: s.certManager.HTTPHandler(http.HandlerFunc(redirectToHTTPS)),
+	}
+
+	go func() {
+		if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("HTTP-01 challenge/redirect listener error", zap.Error(err))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = redirectSrv.Close()
+		_ = httpsSrv.Close()
+	}()
+
+	if err := httpsSrv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("tls: serve: %w", err)
+	}
+	return nil
+}
+
+// redirectToHTTPS redirects plain HTTP requests (that aren't an ACME
+// HTTP-01 challenge, which autocert.Manager.HTTPHandler intercepts first)
+// to the HTTPS equivalent of the same URL.
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	target := "https://" + r.Host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
@@ -0,0 +1,94 @@
+package tls
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connex/internal/config"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// fakeACMEDirectory serves just the ACME directory document, enough to
+// verify NewAutoTLSServer actually points its autocert.Manager at
+// AutoTLSConfig.DirectoryURL (the knob that lets a deployment use a private
+// ACME CA, e.g. step-ca or Pebble, instead of Let's Encrypt production)
+// rather than silently falling back to the default directory.
+func fakeACMEDirectory(t *testing.T) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"newNonce":"` + server.URL + `/new-nonce","newAccount":"` +
+			server.URL + `/new-account","newOrder":"` + server.URL + `/new-order"}`))
+	})
+	t.Cleanup(server.Close)
+	return server.URL + "/directory"
+}
+
+func TestNewAutoTLSServer_UsesConfiguredDirectoryURL(t *testing.T) {
+	directoryURL := fakeACMEDirectory(t)
+
+	cfg := config.AutoTLSConfig{
+		Hosts:        []string{"example.test"},
+		DirectoryURL: directoryURL,
+	}
+	srv := NewAutoTLSServer(http.NewServeMux(), ":0", cfg, autocert.DirCache(t.TempDir()))
+
+	require.NotNil(t, srv.certManager)
+	require.NotNil(t, srv.certManager.Client)
+	assert.Equal(t, directoryURL, srv.certManager.Client.DirectoryURL)
+	assert.False(t, srv.Ready(), "Ready should be false before any certificate has been issued")
+}
+
+func TestNewAutoTLSServer_WithoutDirectoryURLKeepsDefaultClient(t *testing.T) {
+	cfg := config.AutoTLSConfig{Hosts: []string{"example.test"}}
+	srv := NewAutoTLSServer(http.NewServeMux(), ":0", cfg, autocert.DirCache(t.TempDir()))
+
+	require.NotNil(t, srv.certManager)
+	assert.Nil(t, srv.certManager.Client, "no DirectoryURL configured should leave the default Let's Encrypt client")
+}
+
+// TestSQLCache_RoundTrip exercises SQLCache's Get/Put/Delete against a
+// sqlmock-backed *sql.DB, the same mocking approach user.UserService's tests
+// use for *sqlx.DB-backed services.
+func TestSQLCache_RoundTrip(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cache := NewSQLCache(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(`SELECT data FROM autocert_cache WHERE cache_key = \$1`).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	_, err = cache.Get(ctx, "missing")
+	assert.ErrorIs(t, err, autocert.ErrCacheMiss)
+
+	mock.ExpectExec(`INSERT INTO autocert_cache`).
+		WithArgs("example.test", []byte("cert-bytes")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, cache.Put(ctx, "example.test", []byte("cert-bytes")))
+
+	mock.ExpectQuery(`SELECT data FROM autocert_cache WHERE cache_key = \$1`).
+		WithArgs("example.test").
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow([]byte("cert-bytes")))
+	data, err := cache.Get(ctx, "example.test")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert-bytes"), data)
+
+	mock.ExpectExec(`DELETE FROM autocert_cache`).
+		WithArgs("example.test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, cache.Delete(ctx, "example.test"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -85,13 +85,15 @@ func TestUserAPI_Integration(t *testing.T) {
 	_, err := logger.New(cfg.Log.Level, cfg.Log.Env)
 	require.NoError(t, err)
 
-	// Initialize database
-	dbInstance, err := db.Init(cfg.Database)
-	require.NoError(t, err)
-	defer dbInstance.Close()
+	// Initialize database. Each test gets its own Manager (and its own
+	// container-backed pool), so parallel runs no longer collapse onto a
+	// shared global singleton.
+	dbManager := db.NewManager()
+	require.NoError(t, dbManager.Register(db.DefaultTenant, cfg.Database))
+	defer dbManager.Close()
 
 	// Create services and handlers
-	userService := user.NewService()
+	userService := user.NewService(dbManager)
 	userHandler := user.NewHandler(userService)
 	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
 
@@ -251,9 +253,9 @@ func TestHealthCheck_Integration(t *testing.T) {
 	}
 
 	// Initialize database
-	dbInstance, err := db.Init(cfg.Database)
-	require.NoError(t, err)
-	defer dbInstance.Close()
+	dbManager := db.NewManager()
+	require.NoError(t, dbManager.Register(db.DefaultTenant, cfg.Database))
+	defer dbManager.Close()
 
 	// Test health check
 	t.Run("Health Check", func(t *testing.T) {
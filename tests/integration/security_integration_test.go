@@ -12,9 +12,11 @@ import (
 
 	"connex/internal/api/auth"
 	"connex/internal/api/user"
+	"connex/internal/db"
 	"connex/internal/config"
 	"connex/internal/middleware"
 	"connex/pkg/logger"
+	"connex/pkg/security/detector"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -43,10 +45,12 @@ func TestSecurityIntegration(t *testing.T) {
 	r.Use(middleware.RequestSizeLimitMiddleware(1024 * 1024)) // 1MB limit
 	r.Use(middleware.IPRateLimit(10, time.Minute))            // 10 requests per minute
 	r.Use(middleware.CSRFMiddleware([]byte("test-csrf-key")))
-	r.Use(middleware.SecurityMonitoringMiddleware())
+	testDetector, err := detector.NewDetector("", nil)
+	require.NoError(t, err)
+	r.Use(middleware.SecurityMonitoringMiddleware(nil, testDetector))
 
 	// Create services and handlers
-	userService := user.NewService()
+	userService := user.NewService(db.DefaultManager())
 	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
 
 	// Setup routes
@@ -264,7 +268,7 @@ func TestSecurityHeadersComprehensive(t *testing.T) {
 	r := chi.NewRouter()
 	r.Use(middleware.SecurityHeadersMiddleware())
 
-	userService := user.NewService()
+	userService := user.NewService(db.DefaultManager())
 	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
 	r.Post("/api/auth/register", authHandler.Register)
 
@@ -307,7 +311,7 @@ func TestRateLimitingComprehensive(t *testing.T) {
 	r := chi.NewRouter()
 	r.Use(middleware.IPRateLimit(5, time.Minute)) // 5 requests per minute
 
-	userService := user.NewService()
+	userService := user.NewService(db.DefaultManager())
 	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
 	r.Post("/api/auth/login", authHandler.Login)
 
@@ -0,0 +1,116 @@
+package security
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connex/internal/config"
+	"connex/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compressionTestRouter(cfg config.CompressionConfig) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(middleware.CompressionMiddleware(cfg))
+
+	body := strings.Repeat("compress me please ", 200)
+	r.Get("/api/data", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+	r.Post("/api/auth/login", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+	return r
+}
+
+// TestCompressionAppliedWhenRequested verifies Content-Encoding: gzip is set
+// when the client advertises support and the response qualifies.
+func TestCompressionAppliedWhenRequested(t *testing.T) {
+	cfg := config.CompressionConfig{
+		Enabled:      true,
+		Level:        5,
+		MinSizeBytes: 64,
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+	r := compressionTestRouter(cfg)
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	gr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	defer gr.Close()
+	decompressed, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Contains(t, string(decompressed), "compress me please")
+}
+
+// TestCompressionAbsentWithoutAcceptEncoding verifies no Content-Encoding
+// header is set when the client doesn't advertise gzip/br support.
+func TestCompressionAbsentWithoutAcceptEncoding(t *testing.T) {
+	cfg := config.CompressionConfig{
+		Enabled:      true,
+		Level:        5,
+		MinSizeBytes: 64,
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+	r := compressionTestRouter(cfg)
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), "compress me please")
+}
+
+// TestCompressionSkippedOnAuthRoutes verifies /api/auth/* is never
+// compressed, even when the client requests it, to avoid a BREACH oracle.
+func TestCompressionSkippedOnAuthRoutes(t *testing.T) {
+	cfg := config.CompressionConfig{
+		Enabled:      true,
+		Level:        5,
+		MinSizeBytes: 64,
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+	r := compressionTestRouter(cfg)
+
+	req := httptest.NewRequest("POST", "/api/auth/login", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+// TestCompressionBelowMinSize verifies small responses aren't compressed
+// even when the client requests it, since the overhead isn't worth it.
+func TestCompressionBelowMinSize(t *testing.T) {
+	cfg := config.CompressionConfig{
+		Enabled:      true,
+		Level:        5,
+		MinSizeBytes: 1 << 20,
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+	r := compressionTestRouter(cfg)
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
@@ -0,0 +1,73 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"connex/internal/api/ssr"
+	"connex/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var csrfMetaRegexp = regexp.MustCompile(`<meta name="csrf-token" content="([^"]+)">`)
+
+// TestCSRFTokenRenderedMatchesAcceptedToken verifies the token ssr.Handler
+// (built with WithCSRF) embeds in the <meta name="csrf-token"> tag of a
+// GET-rendered page is the same one CSRFMiddleware accepts on a subsequent
+// state-changing request.
+func TestCSRFTokenRenderedMatchesAcceptedToken(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte(
+		`<html><head>{{.CSRFMeta}}</head><body>{{.StateScript}}</body></html>`,
+	), 0o644))
+
+	ssrHandler := ssr.NewHandler(dir, ssr.WithCSRF())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, ssrHandler.RenderSPA(w, r, ssr.SSRData{Title: "test"}))
+	})
+	mux.HandleFunc("/submit", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := middleware.CSRFMiddleware([]byte("01234567890123456789012345678901"))(mux)
+
+	getReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	getRec := httptest.NewRecorder()
+	wrapped.ServeHTTP(getRec, getReq)
+	require.Equal(t, http.StatusOK, getRec.Code)
+
+	metaMatch := csrfMetaRegexp.FindStringSubmatch(getRec.Body.String())
+	require.Len(t, metaMatch, 2, "rendered body should contain a csrf-token meta tag")
+	token := metaMatch[1]
+
+	var cookie *http.Cookie
+	for _, c := range getRec.Result().Cookies() {
+		if c.Name == "_gorilla_csrf" {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie, "CSRFMiddleware should set its session cookie on the GET response")
+
+	// Without the token, the POST should be rejected.
+	bareReq := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	bareReq.AddCookie(cookie)
+	bareRec := httptest.NewRecorder()
+	wrapped.ServeHTTP(bareRec, bareReq)
+	assert.Equal(t, http.StatusForbidden, bareRec.Code, "POST without the token should be rejected")
+
+	// With the token the page rendered, the POST should be accepted.
+	postReq := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	postReq.AddCookie(cookie)
+	postReq.Header.Set("X-CSRF-Token", token)
+	postRec := httptest.NewRecorder()
+	wrapped.ServeHTTP(postRec, postReq)
+	assert.Equal(t, http.StatusOK, postRec.Code, "POST with the rendered token should be accepted")
+}
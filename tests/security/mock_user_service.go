@@ -3,6 +3,7 @@ package security
 import (
 	"context"
 	"errors"
+	"time"
 
 	"connex/internal/api/user"
 )
@@ -82,3 +83,47 @@ func (s *MockUserService) GetByEmail(ctx context.Context, email string) (*user.U
 	}
 	return nil, errors.New("user not found")
 }
+
+// GetByExternalID mocks user retrieval by (authSource, externalID).
+func (s *MockUserService) GetByExternalID(ctx context.Context, authSource, externalID string) (*user.User, error) {
+	for _, u := range s.users {
+		if u.AuthSource == authSource && u.ExternalID == externalID {
+			return u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// UpdatePassword mocks setting a new local password hash.
+func (s *MockUserService) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	for _, u := range s.users {
+		if u.ID == id {
+			u.PasswordHash = passwordHash
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
+
+// SoftDelete mocks marking a user deleted without removing it.
+func (s *MockUserService) SoftDelete(ctx context.Context, id int64) error {
+	for _, u := range s.users {
+		if u.ID == id {
+			now := time.Now()
+			u.DeletedAt = &now
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
+
+// ListExternal mocks listing every non-deleted user provisioned by authSource.
+func (s *MockUserService) ListExternal(ctx context.Context, authSource string) ([]*user.User, error) {
+	var users []*user.User
+	for _, u := range s.users {
+		if u.AuthSource == authSource && u.DeletedAt == nil {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
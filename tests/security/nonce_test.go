@@ -0,0 +1,72 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"connex/internal/api/ssr"
+	"connex/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cspNonceRegexp = regexp.MustCompile(`'nonce-([^']+)'`)
+var scriptNonceRegexp = regexp.MustCompile(`nonce="([^"]+)"`)
+
+// TestCSPNonceMatchesRenderedScript verifies the nonce SecurityHeadersMiddleware
+// puts in the Content-Security-Policy header is the exact value ssr.Handler
+// embeds in its rendered <script nonce="..."> hydration tag, so the page
+// doesn't need 'unsafe-inline' to run its own bootstrap script.
+func TestCSPNonceMatchesRenderedScript(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "index.html")
+	require.NoError(t, os.WriteFile(indexPath, []byte(
+		`<html><head></head><body>{{.StateScript}}</body></html>`,
+	), 0o644))
+
+	ssrHandler := ssr.NewHandler(dir)
+
+	r := http.NewServeMux()
+	r.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		err := ssrHandler.RenderSPA(w, req, ssr.SSRData{Title: "test"})
+		require.NoError(t, err)
+	})
+
+	wrapped := middleware.SecurityHeadersMiddleware()(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	cspMatch := cspNonceRegexp.FindStringSubmatch(w.Header().Get("Content-Security-Policy"))
+	require.Len(t, cspMatch, 2, "CSP header should contain a nonce")
+
+	scriptMatch := scriptNonceRegexp.FindStringSubmatch(w.Body.String())
+	require.Len(t, scriptMatch, 2, "rendered body should contain a nonce attribute")
+
+	assert.Equal(t, cspMatch[1], scriptMatch[1], "CSP nonce should match the rendered script's nonce")
+}
+
+// TestCSPNoncesAreUniquePerRequest verifies each request gets its own nonce,
+// not a value reused/hardcoded across requests.
+func TestCSPNoncesAreUniquePerRequest(t *testing.T) {
+	wrapped := middleware.SecurityHeadersMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	first := httptest.NewRecorder()
+	wrapped.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/", nil))
+	second := httptest.NewRecorder()
+	wrapped.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	firstNonce := cspNonceRegexp.FindStringSubmatch(first.Header().Get("Content-Security-Policy"))
+	secondNonce := cspNonceRegexp.FindStringSubmatch(second.Header().Get("Content-Security-Policy"))
+	require.Len(t, firstNonce, 2)
+	require.Len(t, secondNonce, 2)
+	assert.NotEqual(t, firstNonce[1], secondNonce[1])
+}
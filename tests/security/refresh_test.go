@@ -0,0 +1,113 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connex/internal/api/auth"
+	"connex/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// register creates a user and returns its parsed AuthResponse.
+func register(t *testing.T, authHandler *auth.Handler) auth.AuthResponse {
+	t.Helper()
+	reqBody := map[string]interface{}{
+		"name":     "Test User",
+		"email":    fmt.Sprintf("refresh-%d@example.com", time.Now().UnixNano()),
+		"password": "SecurePass123!",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	authHandler.Register(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp auth.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.RefreshToken)
+	return resp
+}
+
+func doRefresh(authHandler *auth.Handler, refreshToken string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(auth.RefreshRequest{RefreshToken: refreshToken})
+	req := httptest.NewRequest("POST", "/api/auth/refresh", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	authHandler.Refresh(w, req)
+	return w
+}
+
+// TestRefreshTokenRotation tests that a valid refresh token yields a fresh
+// access+refresh pair.
+func TestRefreshTokenRotation(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	userService := NewMockUserService()
+	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
+
+	issued := register(t, authHandler)
+
+	w := doRefresh(authHandler, issued.RefreshToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var rotated auth.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.NotEmpty(t, rotated.Token)
+	assert.NotEmpty(t, rotated.RefreshToken)
+	assert.NotEqual(t, issued.Token, rotated.Token, "rotation should mint a new access token")
+	assert.NotEqual(t, issued.RefreshToken, rotated.RefreshToken, "rotation should mint a new refresh token")
+}
+
+// TestRefreshTokenReuseRejected tests that replaying an already-rotated
+// refresh token is rejected rather than silently accepted.
+func TestRefreshTokenReuseRejected(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	userService := NewMockUserService()
+	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
+
+	issued := register(t, authHandler)
+
+	w := doRefresh(authHandler, issued.RefreshToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Replaying the original (now-rotated) refresh token must fail.
+	reuse := doRefresh(authHandler, issued.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, reuse.Code)
+}
+
+// TestLogoutDenylistsRefreshToken tests that Logout, given a refresh token
+// in its body, denylists it so it can no longer be redeemed.
+func TestLogoutDenylistsRefreshToken(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	userService := NewMockUserService()
+	authHandler := auth.NewHandler(userService, cfg.JWT.Secret)
+
+	issued := register(t, authHandler)
+
+	logoutBody, _ := json.Marshal(auth.RefreshRequest{RefreshToken: issued.RefreshToken})
+	logoutReq := httptest.NewRequest("POST", "/api/auth/logout", bytes.NewBuffer(logoutBody))
+	logoutReq.Header.Set("Authorization", "Bearer "+issued.Token)
+	logoutReq.Header.Set("Content-Type", "application/json")
+	logoutW := httptest.NewRecorder()
+	authHandler.Logout(logoutW, logoutReq)
+	require.Equal(t, http.StatusNoContent, logoutW.Code)
+
+	w := doRefresh(authHandler, issued.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "refresh token denylisted by logout should be rejected")
+}